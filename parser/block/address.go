@@ -0,0 +1,174 @@
+package block
+
+import (
+	"regexp"
+	"strings"
+)
+
+const (
+	fieldNameFrom    = "From"
+	fieldNameTo      = "To"
+	fieldNameReplyTo = "Reply-To"
+)
+
+var (
+	// Like `addressRegex` in the `parse` package, this is more tolerant than
+	// `net/mail`, since quoted headers often have redacted domains like
+	// "user@y...".
+	nameAddressRegex = regexp.MustCompile(`^"?([^"<>]*?)"?\s*<(?i:mailto:)?([^<>]*)>$`)
+
+	// Outlook puts the address in square brackets instead, like "Alice
+	// [mailto:alice@example.com]". Unlike with angle brackets, the brackets
+	// must hold an address, so a name like "Alice [Moderator]" is left alone.
+	bracketAddressRegex = regexp.MustCompile(`^"?([^"\[\]]*?)"?\s*\[(?i:mailto:)?([^\s\[\]@]+@[^\s\[\]@]*)\]$`)
+	bareAddressRegex    = regexp.MustCompile(`^(?i:mailto:)?([^\s<>@]+@[^\s<>@]*)$`)
+	mailtoRegex         = regexp.MustCompile(`^(?i:mailto:)`)
+
+	// Redacted addresses like "alice@y..." aren't worth linking to.
+	linkableAddressRegex = regexp.MustCompile(`^[^\s<>@]+@[^\s<>@.]+(?:\.[^\s<>@.]+)+$`)
+)
+
+type Address struct {
+	Name  string
+	Email string
+}
+
+// CanonicalizeEmail makes addresses which only differ in formatting compare
+// equal. It trims whitespace, angle brackets, and "mailto:", and lowercases
+// the domain. The local part is left alone, since it may be case-sensitive;
+// use `FoldEmail` to lowercase that too.
+func CanonicalizeEmail(address string) string {
+	address = strings.TrimSpace(address)
+	address = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(address, "<"), ">"))
+	address = mailtoRegex.ReplaceAllString(address, "")
+
+	atIndex := strings.LastIndexByte(address, '@')
+	if atIndex < 0 {
+		return address
+	}
+
+	return address[:atIndex+1] + strings.ToLower(address[atIndex+1:])
+}
+
+// FoldEmail is like `CanonicalizeEmail`, but lowercases the whole address.
+// Almost every mail server treats local parts case-insensitively, so this is
+// usually what you want for deduplicating senders.
+func FoldEmail(address string) string {
+	return strings.ToLower(CanonicalizeEmail(address))
+}
+
+// splitAddressList splits a list of addresses separated by commas or
+// semicolons, ignoring separators inside quotes or angle or square brackets.
+func splitAddressList(value string) []string {
+	var (
+		parts      []string
+		current    strings.Builder
+		inQuotes   bool
+		inBrackets bool
+	)
+
+	for _, char := range value {
+		switch {
+		case char == '"' && !inBrackets:
+			inQuotes = !inQuotes
+		case (char == '<' || char == '[') && !inQuotes:
+			inBrackets = true
+		case (char == '>' || char == ']') && !inQuotes:
+			inBrackets = false
+		case (char == ',' || char == ';') && !inQuotes && !inBrackets:
+			parts = append(parts, current.String())
+			current.Reset()
+			continue
+		}
+
+		current.WriteRune(char)
+	}
+
+	return append(parts, current.String())
+}
+
+// ParseAddressList parses a comma- or semicolon-separated list of addresses,
+// like the value of a "To" or "Reply-To" field. Entries which are neither a
+// name, an email address, nor both are returned as a name.
+func ParseAddressList(value string) []Address {
+	var addresses []Address
+
+	for _, part := range splitAddressList(value) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		if matches := nameAddressRegex.FindStringSubmatch(part); matches != nil {
+			addresses = append(addresses, Address{
+				Name:  strings.TrimSpace(matches[1]),
+				Email: strings.TrimSpace(matches[2]),
+			})
+		} else if matches := bracketAddressRegex.FindStringSubmatch(part); matches != nil {
+			addresses = append(addresses, Address{
+				Name:  strings.TrimSpace(matches[1]),
+				Email: matches[2],
+			})
+		} else if matches := bareAddressRegex.FindStringSubmatch(part); matches != nil {
+			addresses = append(addresses, Address{Email: matches[1]})
+		} else {
+			addresses = append(addresses, Address{Name: strings.Trim(part, `"`)})
+		}
+	}
+
+	return addresses
+}
+
+func (b MessageHeaderBlock) addressesInField(name string) (addresses []Address, ok bool) {
+	field, ok := b.Field(name)
+	if !ok {
+		return nil, false
+	}
+
+	return ParseAddressList(field.Value), true
+}
+
+// isAddressFieldName returns whether the field `name` holds a list of
+// addresses.
+func isAddressFieldName(name string) bool {
+	for _, addressFieldName := range []string{fieldNameFrom, fieldNameTo, fieldNameReplyTo, "Cc"} {
+		if strings.EqualFold(name, addressFieldName) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// canonicalizeEmailsInValue applies `canonicalize` to each email address in
+// the address list `value`, leaving the rest of it as written.
+func canonicalizeEmailsInValue(value string, canonicalize func(address string) string) string {
+	for _, address := range ParseAddressList(value) {
+		if address.Email != "" {
+			value = strings.ReplaceAll(value, address.Email, canonicalize(address.Email))
+		}
+	}
+
+	return value
+}
+
+// From returns the address in the "From" field. It returns false if there is
+// no such field or it isn't a single address.
+func (b MessageHeaderBlock) From() (address Address, ok bool) {
+	addresses, ok := b.addressesInField(fieldNameFrom)
+	if !ok || len(addresses) != 1 || addresses[0].Email == "" {
+		return Address{}, false
+	}
+
+	return addresses[0], true
+}
+
+// To returns the addresses in the "To" field.
+func (b MessageHeaderBlock) To() (addresses []Address, ok bool) {
+	return b.addressesInField(fieldNameTo)
+}
+
+// ReplyTo returns the addresses in the "Reply-To" field.
+func (b MessageHeaderBlock) ReplyTo() (addresses []Address, ok bool) {
+	return b.addressesInField(fieldNameReplyTo)
+}
@@ -0,0 +1,142 @@
+package block
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseAddressList(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  []Address
+	}{
+		{
+			name:  "comma-separated",
+			value: `Alice <alice@example.com>, bob@example.com`,
+			want:  []Address{{Name: "Alice", Email: "alice@example.com"}, {Email: "bob@example.com"}},
+		},
+		{
+			name:  "semicolon-separated",
+			value: `Alice <alice@example.com>; Bob [mailto:bob@example.com]`,
+			want:  []Address{{Name: "Alice", Email: "alice@example.com"}, {Name: "Bob", Email: "bob@example.com"}},
+		},
+		{
+			name:  "separator in quotes",
+			value: `"Example, Alice" <alice@example.com>, Bob`,
+			want:  []Address{{Name: "Example, Alice", Email: "alice@example.com"}, {Name: "Bob"}},
+		},
+		{
+			name:  "mailto",
+			value: `Alice <mailto:alice@example.com>, mailto:bob@example.com`,
+			want:  []Address{{Name: "Alice", Email: "alice@example.com"}, {Email: "bob@example.com"}},
+		},
+		{
+			name:  "square brackets with mailto",
+			value: `"Example, Alice" [mailto:alice@example.com]`,
+			want:  []Address{{Name: "Example, Alice", Email: "alice@example.com"}},
+		},
+		{
+			name:  "square brackets",
+			value: `Alice [alice@example.com]`,
+			want:  []Address{{Name: "Alice", Email: "alice@example.com"}},
+		},
+		{
+			name:  "square brackets without an address",
+			value: `Alice [Moderator]`,
+			want:  []Address{{Name: "Alice [Moderator]"}},
+		},
+		{
+			name:  "redacted domain",
+			value: `alice@y...`,
+			want:  []Address{{Email: "alice@y..."}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := ParseAddressList(test.value); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("ParseAddressList(%q) = %q, want %q", test.value, got, test.want)
+			}
+		})
+	}
+}
+
+func TestMessageHeaderReplyTo(t *testing.T) {
+	header := parseHeader(t, "From: Alice <alice@example.com>\nReply-To: Alice <alice@example.com>; list@example.com\nSubject: Lunch\n")
+
+	want := []Address{{Name: "Alice", Email: "alice@example.com"}, {Email: "list@example.com"}}
+
+	if got, ok := header.ReplyTo(); !ok || !reflect.DeepEqual(got, want) {
+		t.Errorf("ReplyTo() = %q, %v, want %q, true", got, ok, want)
+	}
+
+	if _, ok := header.To(); ok {
+		t.Errorf("To() ok = true for a header without a To field")
+	}
+}
+
+func TestCanonicalizeEmail(t *testing.T) {
+	tests := []struct {
+		name       string
+		address    string
+		want       string
+		wantFolded string
+	}{
+		{"mixed-case domain", "Alice@Example.COM", "Alice@example.com", "alice@example.com"},
+		{"angle brackets", " <alice@example.com> ", "alice@example.com", "alice@example.com"},
+		{"mailto", "<MAILTO:Alice@Example.com>", "Alice@example.com", "alice@example.com"},
+		{"not an address", "Alice", "Alice", "alice"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := CanonicalizeEmail(test.address); got != test.want {
+				t.Errorf("CanonicalizeEmail(%q) = %q, want %q", test.address, got, test.want)
+			}
+
+			if got := FoldEmail(test.address); got != test.wantFolded {
+				t.Errorf("FoldEmail(%q) = %q, want %q", test.address, got, test.wantFolded)
+			}
+		})
+	}
+}
+
+func TestCanonicalizeEmailOption(t *testing.T) {
+	parser := &MessageHeaderParser{CanonicalizeEmail: FoldEmail}
+	if ok, _, _ := parser.FromText("From: Alice <Alice@Example.com>\nTo: BOB@EXAMPLE.COM\nSubject: Lunch\n"); !ok {
+		t.Fatal("FromText ok = false, want true")
+	}
+
+	header := parser.Header
+
+	if from, _ := header.From(); from.Email != "alice@example.com" {
+		t.Errorf("From() Email = %q, want %q", from.Email, "alice@example.com")
+	}
+
+	if to, _ := header.To(); len(to) != 1 || to[0].Email != "bob@example.com" {
+		t.Errorf("To() = %q, want bob@example.com", to)
+	}
+
+	attribution := &AttributionBlock{CanonicalizeEmail: FoldEmail}
+	parseAttribution(t, attribution, "On Mon, 2 Jan 2006 15:04, Alice <Alice@Example.com> wrote:\n")
+
+	if attribution.Email != "alice@example.com" {
+		t.Errorf("FromText Email = %q, want %q", attribution.Email, "alice@example.com")
+	}
+
+	attribution = &AttributionBlock{CanonicalizeEmail: FoldEmail}
+	parseAttribution(t, attribution, "<Alice@Example.com> wrote:\n")
+
+	if attribution.Name != "alice@example.com" {
+		t.Errorf("FromText Name = %q, want %q", attribution.Name, "alice@example.com")
+	}
+}
+
+func TestMessageHeaderOutlookFrom(t *testing.T) {
+	header := parseHeader(t, "From: Alice [mailto:alice@example.com]\nSent: Monday, January 02, 2006 3:04 PM\nTo: Bob\nSubject: Lunch\n")
+
+	if from, ok := header.From(); !ok || from != (Address{Name: "Alice", Email: "alice@example.com"}) {
+		t.Errorf("From() = %q, %v, want Alice and alice@example.com", from, ok)
+	}
+}
@@ -9,19 +9,37 @@ import (
 )
 
 var (
-	ErrInvalidDateFormat       = errors.New("invalid date format")
-	ErrInvalidTimeFormat       = errors.New("invalid time format")
-	ErrInvalidNameFormat       = errors.New("invalid name format")
-	ErrInvalidCaptureKind      = errors.New("invalid capture kind")
-	ErrNoMatchingCaptureGroups = errors.New("match has no matching capture groups")
+	ErrInvalidDateFormat         = errors.New("invalid date format")
+	ErrInvalidTimeFormat         = errors.New("invalid time format")
+	ErrInvalidRelativeDateFormat = errors.New("invalid relative date format")
+	ErrInvalidNameFormat         = errors.New("invalid name format")
+	ErrInvalidCaptureKind        = errors.New("invalid capture kind")
+	ErrNoMatchingCaptureGroups   = errors.New("match has no matching capture groups")
+	ErrInvalidAttributionRegex   = errors.New("invalid attribution regex")
+	ErrYearlessDateWithoutColon  = errors.New("date without a year in an attribution without a colon")
 )
 
 const (
-	attributionNameRegexPart       = `(?:[^<>,"\s]|[^<>,"\s][^<>,"]*[^<>,"\s])`
+	attributionNameRegexPart       = `(?:[^<>,"“”\s]|[^<>,"“”\s][^<>,"“”\n]*[^<>,"“”\s])`
 	attributionEmailRegexPart      = `[^<>@\s]+@[^<>@\s]*`
+	attributionMailtoRegexPart     = `(?i:mailto:)?`
 	attributionGroupEmailRegexPart = `[^\s@]+@(?:yahoogroups\.com|y?\.{3})`
-	shortMonthRegexPart            = `(?:Jan|Feb|Mar|Apr|May|Jun|Jul|Aug|Sep|Oct|Nov|Dec)`
 	shortWeekdayRegexPart          = `(?:Mon|Tue|Wed|Thu|Fri|Sat|Sun)`
+
+	// Some clients quote display names with smart quotes, like “Alice”.
+	openQuoteRegexPart  = `["“]`
+	closeQuoteRegexPart = `["”]`
+
+	// Present-tense clients use "writes" and chat-style exports use "says".
+	attributionVerbRegexPart = `(?:wrote|writes|says)`
+
+	// Some clients leave the colon off, like "On Mon, 2 Jan 2006, Alice
+	// wrote". This is only allowed in attributions with a date, and only
+	// when no more text follows until a blank line, so it doesn't match prose
+	// like "On Mon, 2 Jan 2006, Alice wrote\nthat it is good". The quote
+	// after an attribution is tokenized separately, so the text of its
+	// paragraph ends after the verb.
+	datedAttributionEndRegexPart = `(?::(?:\s+|\z)|[^\S\n]*(?:\z|\n[^\S\n]*(?:\z|\n\s*)))`
 )
 
 type regexMatcher interface {
@@ -58,6 +76,16 @@ func joinDateFormats(formats []dateFormat) string {
 	return joinMatchers(matchers)
 }
 
+func joinRelativeDateFormats(formats []relativeDateFormat) string {
+	matchers := make([]regexMatcher, len(formats))
+
+	for i, format := range formats {
+		matchers[i] = format
+	}
+
+	return joinMatchers(matchers)
+}
+
 func joinTimeFormats(formats []timeFormat) string {
 	matchers := make([]regexMatcher, len(formats))
 
@@ -77,6 +105,7 @@ const (
 	nameFormatQuotedName               = "QuotedName"
 	nameFormatQuotedNameEmail          = "QuotedNameEmail"
 	nameFormatQuotedNameDuplicateEmail = "QuotedNameDuplicateEmail"
+	nameFormatHandle                   = "Handle"
 )
 
 func allNameFormats() []nameFormat {
@@ -90,6 +119,25 @@ func allNameFormats() []nameFormat {
 	}
 }
 
+// knownNameFormats also includes formats which are too loose to be tried by
+// the regexes that use `allNameFormats`.
+func knownNameFormats() []nameFormat {
+	return append(allNameFormats(), nameFormatHandle)
+}
+
+var nameFormatRegexes = compileNameFormatRegexes()
+
+func compileNameFormatRegexes() map[nameFormat]*regexp.Regexp {
+	formats := knownNameFormats()
+	regexes := make(map[nameFormat]*regexp.Regexp, len(formats))
+
+	for _, format := range formats {
+		regexes[format] = format.compileRegex()
+	}
+
+	return regexes
+}
+
 func allEmailNameFormats() []nameFormat {
 	return []nameFormat{
 		nameFormatQuotedNameDuplicateEmail,
@@ -100,19 +148,30 @@ func allEmailNameFormats() []nameFormat {
 }
 
 func (f nameFormat) Regex() *regexp.Regexp {
+	regex, ok := nameFormatRegexes[f]
+	if !ok {
+		panic(fmt.Errorf("%w: %s", ErrInvalidNameFormat, f))
+	}
+
+	return regex
+}
+
+func (f nameFormat) compileRegex() *regexp.Regexp {
 	switch f {
 	case nameFormatName:
 		return regexp.MustCompile(fmt.Sprintf(`(%s)`, attributionNameRegexPart))
 	case nameFormatEmail:
-		return regexp.MustCompile(fmt.Sprintf(`<(%s)>`, attributionEmailRegexPart))
+		return regexp.MustCompile(fmt.Sprintf(`<%s(%s)>`, attributionMailtoRegexPart, attributionEmailRegexPart))
 	case nameFormatNameEmail:
-		return regexp.MustCompile(fmt.Sprintf(`(%s)\s+<%s>`, attributionNameRegexPart, attributionEmailRegexPart))
+		return regexp.MustCompile(fmt.Sprintf(`(%s)\s+<%s%s>`, attributionNameRegexPart, attributionMailtoRegexPart, attributionEmailRegexPart))
 	case nameFormatQuotedName:
-		return regexp.MustCompile(fmt.Sprintf(`"(%s)"`, attributionNameRegexPart))
+		return regexp.MustCompile(fmt.Sprintf(`%s(%s)%s`, openQuoteRegexPart, attributionNameRegexPart, closeQuoteRegexPart))
 	case nameFormatQuotedNameEmail:
-		return regexp.MustCompile(fmt.Sprintf(`"(%s)"\s+<%s>`, attributionNameRegexPart, attributionEmailRegexPart))
+		return regexp.MustCompile(fmt.Sprintf(`%s(%s)%s\s+<%s%s>`, openQuoteRegexPart, attributionNameRegexPart, closeQuoteRegexPart, attributionMailtoRegexPart, attributionEmailRegexPart))
 	case nameFormatQuotedNameDuplicateEmail:
-		return regexp.MustCompile(fmt.Sprintf(`"(%[1]s)\s+<%[2]s>"\s+<%[2]s>`, attributionNameRegexPart, attributionEmailRegexPart))
+		return regexp.MustCompile(fmt.Sprintf(`%[3]s(%[1]s)\s+<%[5]s%[2]s>%[4]s\s+<%[5]s%[2]s>`, attributionNameRegexPart, attributionEmailRegexPart, openQuoteRegexPart, closeQuoteRegexPart, attributionMailtoRegexPart))
+	case nameFormatHandle:
+		return regexp.MustCompile(`([A-Z][A-Z0-9_.-]+)`)
 	default:
 		panic(fmt.Errorf("%w: %s", ErrInvalidNameFormat, f))
 	}
@@ -131,6 +190,14 @@ const (
 	dateFormatLongMonthDayYear         = "LongMonthDayYear"
 	dateFormatLongDayMonthYearWeekday  = "LongDayMonthYearWeekday"
 	dateFormatLongMonthDayYearWeekday  = "LongMonthDayYearWeekday"
+	dateFormatLongOrdinalDayOfMonth    = "LongOrdinalDayOfMonth"
+	dateFormatNumeric                  = "Numeric"
+	dateFormatNumericWeekday           = "NumericWeekday"
+	dateFormatNumericDotted            = "NumericDotted"
+	dateFormatYearlessDayMonth         = "YearlessDayMonth"
+	dateFormatYearlessMonthDay         = "YearlessMonthDay"
+	dateFormatYearlessDayMonthWeekday  = "YearlessDayMonthWeekday"
+	dateFormatYearlessMonthDayWeekday  = "YearlessMonthDayWeekday"
 )
 
 func allDateFormats() []dateFormat {
@@ -139,15 +206,39 @@ func allDateFormats() []dateFormat {
 		dateFormatLongMonthDayYearWeekday,
 		dateFormatLongDayMonthYear,
 		dateFormatLongMonthDayYear,
+		dateFormatLongOrdinalDayOfMonth,
 		dateFormatShortYearMonthDayWeekday,
 		dateFormatShortYearMonthDay,
+		dateFormatNumericWeekday,
+		dateFormatNumeric,
+		dateFormatNumericDotted,
 		dateFormatShortPaddedWeekday,
 		dateFormatShortWeekday,
 		dateFormatShortPadded,
 		dateFormatShort,
+
+		// Dates without a year are tried last so that they don't match the
+		// start of a date with one.
+		dateFormatYearlessDayMonthWeekday,
+		dateFormatYearlessMonthDayWeekday,
+		dateFormatYearlessDayMonth,
+		dateFormatYearlessMonthDay,
 	}
 }
 
+var dateFormatRegexes = compileDateFormatRegexes()
+
+func compileDateFormatRegexes() map[dateFormat]*regexp.Regexp {
+	formats := allDateFormats()
+	regexes := make(map[dateFormat]*regexp.Regexp, len(formats))
+
+	for _, format := range formats {
+		regexes[format] = format.compileRegex()
+	}
+
+	return regexes
+}
+
 func (f dateFormat) FormatString() string {
 	switch f {
 	case dateFormatShort:
@@ -170,12 +261,110 @@ func (f dateFormat) FormatString() string {
 		return "Mon, 2 Jan 2006"
 	case dateFormatLongMonthDayYearWeekday:
 		return "Mon, Jan 2, 2006"
+	case dateFormatLongOrdinalDayOfMonth:
+		return "the 2nd of January 2006"
+	case dateFormatNumeric:
+		return "1/2/2006"
+	case dateFormatNumericWeekday:
+		return "Mon, 1/2/2006"
+	case dateFormatNumericDotted:
+		return "1.2.2006"
+	case dateFormatYearlessDayMonth:
+		return "2 Jan"
+	case dateFormatYearlessMonthDay:
+		return "Jan 2"
+	case dateFormatYearlessDayMonthWeekday:
+		return "Mon, 2 Jan"
+	case dateFormatYearlessMonthDayWeekday:
+		return "Mon, Jan 2"
 	default:
 		panic(fmt.Errorf("%w: %s", ErrInvalidDateFormat, f))
 	}
 }
 
+// dayFirstLayoutReplacer swaps the month and day in the layouts of numeric
+// dates.
+var dayFirstLayoutReplacer = strings.NewReplacer("01/02/", "02/01/", "1/2/", "2/1/", "1.2.", "2.1.")
+
+// IsDotted returns whether this is a numeric date separated by periods, like
+// "2.1.2006", which is usually written day first, unlike slashed dates.
+func (f dateFormat) IsDotted() bool {
+	return f == dateFormatNumericDotted
+}
+
+// IsYearless returns whether this is a date without a year, like "Mon, 2
+// Jan", which has to be parsed with `ParseYearless` instead.
+func (f dateFormat) IsYearless() bool {
+	switch f {
+	case dateFormatYearlessDayMonth, dateFormatYearlessMonthDay, dateFormatYearlessDayMonthWeekday, dateFormatYearlessMonthDayWeekday:
+		return true
+	default:
+		return false
+	}
+}
+
+// yearlessSearchYears is how many years before the reference to look for one
+// in which a yearless date is valid. The calendar repeats every 28 years, so
+// any day of the week and leap day is found within that many.
+const yearlessSearchYears = 28
+
+// ParseYearless parses `text` as a date without a year. The year is taken to
+// be the one which puts the date closest before `reference`, since a quoted
+// message is sent before the reply to it. A date up to a day after
+// `reference` is allowed, since the two may be in different time zones. Years
+// in which the date doesn't exist, like "29 Feb" in a year which isn't a leap
+// year, or in which it falls on a different day of the week than the one
+// given, are skipped.
+func (f dateFormat) ParseYearless(text string, reference time.Time) (time.Time, error) {
+	weekday, hasWeekday := time.Weekday(0), false
+	if f == dateFormatYearlessDayMonthWeekday || f == dateFormatYearlessMonthDayWeekday {
+		weekday, hasWeekday = leadingWeekday(text)
+	}
+
+	for year := reference.Year(); year > reference.Year()-yearlessSearchYears; year-- {
+		date, err := parseLongDate(fmt.Sprintf("%s %04d", text, year))
+		if err != nil || date.After(reference.AddDate(0, 0, 1)) {
+			continue
+		}
+
+		if hasWeekday && date.Weekday() != weekday {
+			continue
+		}
+
+		return date, nil
+	}
+
+	return time.Time{}, fmt.Errorf("%w: %q", ErrUnrecognizedDate, text)
+}
+
+// Parse parses `text` as a date in this format. Numeric dates are ambiguous,
+// so they're read as MM/DD unless `dayFirst` is set, in which case they're
+// read as DD/MM. Dates with a month name may use the names of any
+// locale in `monthNames`.
+func (f dateFormat) Parse(text string, dayFirst bool) (time.Time, error) {
+	switch f {
+	case dateFormatLongDayMonthYear, dateFormatLongMonthDayYear, dateFormatLongDayMonthYearWeekday, dateFormatLongMonthDayYearWeekday, dateFormatLongOrdinalDayOfMonth:
+		return parseLongDate(text)
+	}
+
+	layout := f.FormatString()
+	if dayFirst {
+		layout = dayFirstLayoutReplacer.Replace(layout)
+	}
+
+	return time.Parse(layout, text)
+}
+
 func (f dateFormat) Regex() *regexp.Regexp {
+	regex, ok := dateFormatRegexes[f]
+	if !ok {
+		panic(fmt.Errorf("%w: %s", ErrInvalidDateFormat, f))
+	}
+
+	return regex
+}
+
+func (f dateFormat) compileRegex() *regexp.Regexp {
 	switch f {
 	case dateFormatShort:
 		return regexp.MustCompile(`(\d{1,2}/\d{1,2}/\d{2})`)
@@ -190,61 +379,224 @@ func (f dateFormat) Regex() *regexp.Regexp {
 	case dateFormatShortYearMonthDayWeekday:
 		return regexp.MustCompile(fmt.Sprintf(`(%s, \d{4}-\d{2}-\d{2})`, shortWeekdayRegexPart))
 	case dateFormatLongDayMonthYear:
-		return regexp.MustCompile(fmt.Sprintf(`(\d{1,2} %s \d{4})`, shortMonthRegexPart))
+		return regexp.MustCompile(fmt.Sprintf(`(\d{1,2}\.? %s \d{4})`, monthNameRegexPart))
 	case dateFormatLongMonthDayYear:
-		return regexp.MustCompile(fmt.Sprintf(`(%s \d{1,2}, \d{4})`, shortMonthRegexPart))
+		return regexp.MustCompile(fmt.Sprintf(`(%s \d{1,2}, \d{4})`, monthNameRegexPart))
 	case dateFormatLongDayMonthYearWeekday:
-		return regexp.MustCompile(fmt.Sprintf(`(%s, \d{1,2} %s \d{4})`, shortWeekdayRegexPart, shortMonthRegexPart))
+		return regexp.MustCompile(fmt.Sprintf(`(%s,? \d{1,2}\.? %s \d{4})`, weekdayNameRegexPart, monthNameRegexPart))
 	case dateFormatLongMonthDayYearWeekday:
-		return regexp.MustCompile(fmt.Sprintf(`(%s, %s \d{1,2}, \d{4})`, shortWeekdayRegexPart, shortMonthRegexPart))
+		return regexp.MustCompile(fmt.Sprintf(`(%s,? %s \d{1,2}, \d{4})`, weekdayNameRegexPart, monthNameRegexPart))
+	case dateFormatLongOrdinalDayOfMonth:
+		// This is the verbose British form, like "the 2nd of January 2006".
+		return regexp.MustCompile(fmt.Sprintf(`((?i:the\s+)?\d{1,2}(?i:st|nd|rd|th)\s+(?i:of)\s+%s,?\s+\d{4})`, monthNameRegexPart))
+	case dateFormatNumeric:
+		return regexp.MustCompile(`(\d{1,2}/\d{1,2}/\d{4})`)
+	case dateFormatNumericWeekday:
+		return regexp.MustCompile(fmt.Sprintf(`(%s, \d{1,2}/\d{1,2}/\d{4})`, shortWeekdayRegexPart))
+	case dateFormatNumericDotted:
+		// This could be mistaken for a version number or an IP address on
+		// its own, but it's only matched as part of an attribution.
+		return regexp.MustCompile(`(\d{1,2}\.\d{1,2}\.\d{4})`)
+	case dateFormatYearlessDayMonth:
+		return regexp.MustCompile(fmt.Sprintf(`(\d{1,2}\.? %s)`, monthNameRegexPart))
+	case dateFormatYearlessMonthDay:
+		return regexp.MustCompile(fmt.Sprintf(`(%s \d{1,2})`, monthNameRegexPart))
+	case dateFormatYearlessDayMonthWeekday:
+		return regexp.MustCompile(fmt.Sprintf(`(%s,? \d{1,2}\.? %s)`, weekdayNameRegexPart, monthNameRegexPart))
+	case dateFormatYearlessMonthDayWeekday:
+		return regexp.MustCompile(fmt.Sprintf(`(%s,? %s \d{1,2})`, weekdayNameRegexPart, monthNameRegexPart))
 	default:
 		panic(fmt.Errorf("%w: %s", ErrInvalidDateFormat, f))
 	}
 }
 
+type relativeDateFormat string
+
+const (
+	relativeDateFormatToday     = "Today"
+	relativeDateFormatYesterday = "Yesterday"
+)
+
+func allRelativeDateFormats() []relativeDateFormat {
+	return []relativeDateFormat{
+		relativeDateFormatYesterday,
+		relativeDateFormatToday,
+	}
+}
+
+var relativeDateFormatRegexes = compileRelativeDateFormatRegexes()
+
+func compileRelativeDateFormatRegexes() map[relativeDateFormat]*regexp.Regexp {
+	formats := allRelativeDateFormats()
+	regexes := make(map[relativeDateFormat]*regexp.Regexp, len(formats))
+
+	for _, format := range formats {
+		regexes[format] = format.compileRegex()
+	}
+
+	return regexes
+}
+
+func (f relativeDateFormat) DaysAgo() int {
+	switch f {
+	case relativeDateFormatToday:
+		return 0
+	case relativeDateFormatYesterday:
+		return 1
+	default:
+		panic(fmt.Errorf("%w: %s", ErrInvalidRelativeDateFormat, f))
+	}
+}
+
+func (f relativeDateFormat) Regex() *regexp.Regexp {
+	regex, ok := relativeDateFormatRegexes[f]
+	if !ok {
+		panic(fmt.Errorf("%w: %s", ErrInvalidRelativeDateFormat, f))
+	}
+
+	return regex
+}
+
+func (f relativeDateFormat) compileRegex() *regexp.Regexp {
+	switch f {
+	case relativeDateFormatToday:
+		return regexp.MustCompile(`((?i:today))`)
+	case relativeDateFormatYesterday:
+		return regexp.MustCompile(`((?i:yesterday))`)
+	default:
+		panic(fmt.Errorf("%w: %s", ErrInvalidRelativeDateFormat, f))
+	}
+}
+
 type timeFormat string
 
 const (
 	timeFormatShort12Hr  = "Short12Hr"
 	timeFormatShort24Hr  = "Short24Hr"
+	timeFormatMedium24Hr = "Medium24Hr"
 	timeFormatLong       = "Long"
 	timeFormatLongTzName = "LongTzName"
+
+	timeFormatLongBareTzName = "LongBareTzName"
+
+	// This is a time followed by a spelled-out time zone name, like "3:04 PM
+	// Pacific Standard Time". See `TimeZoneNames`.
+	timeFormatFullTzName = "FullTzName"
+
+	// This is a time followed by a time zone abbreviation in parentheses but
+	// no numeric offset, like "15:04:05 (UTC)".
+	timeFormatParenTzName = "ParenTzName"
 )
 
 func allTimeFormats() []timeFormat {
 	return []timeFormat{
+		timeFormatFullTzName,
 		timeFormatLongTzName,
+		timeFormatLongBareTzName,
+		timeFormatParenTzName,
 		timeFormatLong,
+		timeFormatMedium24Hr,
 		timeFormatShort12Hr,
 		timeFormatShort24Hr,
 	}
 }
 
+var timeFormatRegexes = compileTimeFormatRegexes()
+
+func compileTimeFormatRegexes() map[timeFormat]*regexp.Regexp {
+	formats := allTimeFormats()
+	regexes := make(map[timeFormat]*regexp.Regexp, len(formats))
+
+	for _, format := range formats {
+		regexes[format] = format.compileRegex()
+	}
+
+	return regexes
+}
+
 func (f timeFormat) FormatString() string {
 	switch f {
 	case timeFormatShort12Hr:
 		return "3:04 PM"
 	case timeFormatShort24Hr:
 		return "15:04"
+	case timeFormatMedium24Hr:
+		return "15:04:05"
 	case timeFormatLong:
 		return "15:04:05 -0700"
 	case timeFormatLongTzName:
 		return "15:04:05 -0700 (MST)"
+	case timeFormatLongBareTzName:
+		return "15:04:05 -0700 MST"
+	case timeFormatFullTzName:
+		return "3:04 PM Pacific Standard Time"
+	case timeFormatParenTzName:
+		return "15:04:05 (MST)"
 	default:
 		panic(fmt.Errorf("%w: %s", ErrInvalidTimeFormat, f))
 	}
 }
 
+// Parse parses a time matched by the format's regex. The meridiem in 12-hour
+// times is normalized first, since `time.Parse` only accepts "AM" and "PM"
+// and clients also write "pm" and "p.m.".
+func (f timeFormat) Parse(text string) (time.Time, error) {
+	if f == timeFormatShort12Hr {
+		text = normalizeMeridiem(text)
+	}
+
+	if f == timeFormatFullTzName {
+		return parseClockWithFullTimeZoneName(text)
+	}
+
+	if f == timeFormatParenTzName {
+		return parseClockWithParenTimeZoneName(text)
+	}
+
+	return time.Parse(f.FormatString(), text)
+}
+
+// HasUnknownOffset returns whether `text`, which was matched by the format's
+// regex, names a time zone whose offset isn't known, like "Samoa Standard
+// Time" or "(EST)".
+func (f timeFormat) HasUnknownOffset(text string) bool {
+	switch f {
+	case timeFormatFullTzName:
+		return hasUnknownFullTimeZoneName(text)
+	case timeFormatParenTzName:
+		return hasUnknownParenTimeZoneName(text)
+	default:
+		return false
+	}
+}
+
 func (f timeFormat) Regex() *regexp.Regexp {
+	regex, ok := timeFormatRegexes[f]
+	if !ok {
+		panic(fmt.Errorf("%w: %s", ErrInvalidTimeFormat, f))
+	}
+
+	return regex
+}
+
+func (f timeFormat) compileRegex() *regexp.Regexp {
 	switch f {
 	case timeFormatShort12Hr:
-		return regexp.MustCompile(`(\d{1,2}:\d{2} (?:AM|PM))`)
+		return regexp.MustCompile(`(\d{1,2}:\d{2}\s?(?i:[AP]\.?M\.?))`)
 	case timeFormatShort24Hr:
 		return regexp.MustCompile(`(\d{1,2}:\d{2})`)
+	case timeFormatMedium24Hr:
+		return regexp.MustCompile(`(\d{1,2}:\d{2}:\d{2})`)
 	case timeFormatLong:
 		return regexp.MustCompile(`(\d{2}:\d{2}:\d{2} [+-]\d{4})`)
 	case timeFormatLongTzName:
 		return regexp.MustCompile(`(\d{2}:\d{2}:\d{2} [+-]\d{4} \([A-Z]{2,5}\))`)
+	case timeFormatLongBareTzName:
+		return regexp.MustCompile(`(\d{2}:\d{2}:\d{2} [+-]\d{4} [A-Z]{2,4}\b)`)
+	case timeFormatFullTzName:
+		return regexp.MustCompile(fmt.Sprintf(`(\d{1,2}:\d{2}(?::\d{2})?(?:\s?(?i:[AP]\.?M\.?))?,?\s+\(?%s\)?)`, fullTimeZoneNameRegexPart))
+	case timeFormatParenTzName:
+		return regexp.MustCompile(`(\d{1,2}:\d{2}(?::\d{2})?\s+\([A-Z]{2,5}\))`)
 	default:
 		panic(fmt.Errorf("%w: %s", ErrInvalidTimeFormat, f))
 	}
@@ -252,9 +604,20 @@ func (f timeFormat) Regex() *regexp.Regexp {
 
 func (f timeFormat) HasTimeZone() bool {
 	switch f {
-	case timeFormatShort12Hr, timeFormatShort24Hr:
+	case timeFormatShort12Hr, timeFormatShort24Hr, timeFormatMedium24Hr:
 		return false
-	case timeFormatLong, timeFormatLongTzName:
+	case timeFormatLong, timeFormatLongTzName, timeFormatLongBareTzName, timeFormatFullTzName, timeFormatParenTzName:
+		return true
+	default:
+		panic(fmt.Errorf("%w: %s", ErrInvalidTimeFormat, f))
+	}
+}
+
+func (f timeFormat) HasTimeZoneName() bool {
+	switch f {
+	case timeFormatShort12Hr, timeFormatShort24Hr, timeFormatMedium24Hr, timeFormatLong:
+		return false
+	case timeFormatLongTzName, timeFormatLongBareTzName, timeFormatFullTzName, timeFormatParenTzName:
 		return true
 	default:
 		panic(fmt.Errorf("%w: %s", ErrInvalidTimeFormat, f))
@@ -271,6 +634,8 @@ const (
 	attributionRegexCaptureName attributionRegexCapture = "Name"
 	attributionRegexCaptureDate attributionRegexCapture = "Date"
 	attributionRegexCaptureTime attributionRegexCapture = "Time"
+
+	attributionRegexCaptureRelativeDate attributionRegexCapture = "RelativeDate"
 )
 
 func (attributionRegexCapture) IsAttributionRegexPart() {}
@@ -280,27 +645,72 @@ type attributionRegexLiteral string
 func (attributionRegexLiteral) IsAttributionRegexPart() {}
 
 type attributionRegex struct {
-	Template    string
-	Parts       []attributionRegexPart
-	NameFormats []nameFormat
-	DateFormats []dateFormat
-	TimeFormats []timeFormat
-	regex       *regexp.Regexp
+	Name                string
+	Template            string
+	Parts               []attributionRegexPart
+	NameFormats         []nameFormat
+	DateFormats         []dateFormat
+	RelativeDateFormats []relativeDateFormat
+	TimeFormats         []timeFormat
+
+	// Lang is the BCP 47 tag of the language the attribution is written in,
+	// which defaults to English.
+	Lang string
+
+	// Lenient regexes are prone to false positives, so they're only tried
+	// when `AttributionBlock.Lenient` is set.
+	Lenient bool
+
+	regex *regexp.Regexp
+
+	// These are the formats for each kind of capture and the number of the
+	// capture group of the first one, which are worked out when the regex is
+	// compiled so that matching doesn't allocate them. A kind may be captured
+	// in more than one place, like in each branch of an alternation, in which
+	// case there's a first capture group for each.
+	captureMatchers    map[attributionRegexCapture][]regexMatcher
+	firstCaptureGroups map[attributionRegexCapture][]int
+}
+
+const defaultAttributionLang = "en"
+
+func (r *attributionRegex) Language() string {
+	if r.Lang == "" {
+		return defaultAttributionLang
+	}
+
+	return r.Lang
+}
+
+// AllowsMissingColon returns whether the regex matches attributions without a
+// colon after the verb, like "On Mon, 2 Jan 2006, Alice wrote".
+func (r *attributionRegex) AllowsMissingColon() bool {
+	for _, part := range r.Parts {
+		if part == attributionRegexLiteral(datedAttributionEndRegexPart) {
+			return true
+		}
+	}
+
+	return false
 }
 
 func (r *attributionRegex) HasDate() bool {
 	return len(r.DateFormats) > 0
 }
 
+func (r *attributionRegex) HasRelativeDate() bool {
+	return len(r.RelativeDateFormats) > 0
+}
+
 func (r *attributionRegex) HasTime() bool {
 	return len(r.TimeFormats) > 0
 }
 
 func (r *attributionRegex) Regex() *regexp.Regexp {
-	if r.regex != nil {
-		return r.regex
-	}
+	return r.regex
+}
 
+func (r *attributionRegex) compile() error {
 	formatArgs := make([]interface{}, len(r.Parts))
 
 	for partIndex, part := range r.Parts {
@@ -313,15 +723,40 @@ func (r *attributionRegex) Regex() *regexp.Regexp {
 				formatArgs[partIndex] = joinDateFormats(r.DateFormats)
 			case attributionRegexCaptureTime:
 				formatArgs[partIndex] = joinTimeFormats(r.TimeFormats)
+			case attributionRegexCaptureRelativeDate:
+				formatArgs[partIndex] = joinRelativeDateFormats(r.RelativeDateFormats)
 			}
 		case attributionRegexLiteral:
 			formatArgs[partIndex] = string(concretePart)
 		}
 	}
 
-	r.regex = regexp.MustCompile(fmt.Sprintf(r.Template, formatArgs...))
+	regex, err := regexp.Compile(fmt.Sprintf(r.Template, formatArgs...))
+	if err != nil {
+		return err
+	}
 
-	return r.regex
+	r.regex = regex
+	r.captureMatchers = make(map[attributionRegexCapture][]regexMatcher)
+	r.firstCaptureGroups = make(map[attributionRegexCapture][]int)
+
+	captureGroupNumber := 1
+
+	for _, part := range r.Parts {
+		kind, isCapture := part.(attributionRegexCapture)
+		if !isCapture {
+			continue
+		}
+
+		matchers := r.matchersOfKind(kind)
+
+		r.captureMatchers[kind] = matchers
+		r.firstCaptureGroups[kind] = append(r.firstCaptureGroups[kind], captureGroupNumber)
+
+		captureGroupNumber += len(matchers)
+	}
+
+	return nil
 }
 
 func (r *attributionRegex) matchersOfKind(kind attributionRegexCapture) []regexMatcher {
@@ -340,6 +775,10 @@ func (r *attributionRegex) matchersOfKind(kind attributionRegexCapture) []regexM
 		for _, format := range r.TimeFormats {
 			matchers = append(matchers, format)
 		}
+	case attributionRegexCaptureRelativeDate:
+		for _, format := range r.RelativeDateFormats {
+			matchers = append(matchers, format)
+		}
 	default:
 		panic(fmt.Errorf("%w: %s", ErrInvalidCaptureKind, kind))
 	}
@@ -352,25 +791,12 @@ func indicesForCaptureGroup(match []int, number int) (start, end int) {
 }
 
 func (r *attributionRegex) MatchIndices(match []int, kind attributionRegexCapture) (start, end int, matcher regexMatcher) {
-	precedingCaptureGroups := 0
-
-	for _, part := range r.Parts {
-		concretePart, isCapture := part.(attributionRegexCapture)
-
-		if !isCapture {
-			continue
-		} else if concretePart == kind {
-			break
-		}
-
-		precedingCaptureGroups += len(r.matchersOfKind(concretePart))
-	}
-
-	for i, matcher := range r.matchersOfKind(kind) {
-		captureGroupNumber := 1 + precedingCaptureGroups + i
-		startIndex, endIndex := indicesForCaptureGroup(match, captureGroupNumber)
-		if startIndex >= 0 && endIndex >= 0 {
-			return startIndex, endIndex, matcher
+	for _, firstCaptureGroup := range r.firstCaptureGroups[kind] {
+		for i, matcher := range r.captureMatchers[kind] {
+			startIndex, endIndex := indicesForCaptureGroup(match, firstCaptureGroup+i)
+			if startIndex >= 0 && endIndex >= 0 {
+				return startIndex, endIndex, matcher
+			}
 		}
 	}
 
@@ -389,6 +815,12 @@ func (r *attributionRegex) DateIndices(match []int) (start, end int, format date
 	return start, end, matcher.(dateFormat)
 }
 
+func (r *attributionRegex) RelativeDateIndices(match []int) (start, end int, format relativeDateFormat) {
+	start, end, matcher := r.MatchIndices(match, attributionRegexCaptureRelativeDate)
+
+	return start, end, matcher.(relativeDateFormat)
+}
+
 func (r *attributionRegex) TimeIndices(match []int) (start, end int, format timeFormat) {
 	start, end, matcher := r.MatchIndices(match, attributionRegexCaptureTime)
 
@@ -397,54 +829,164 @@ func (r *attributionRegex) TimeIndices(match []int) (start, end int, format time
 
 var attributionRegexes = []attributionRegex{
 	{
-		Template: `(?m)^%[1]s(?:-{2,3}\s+)?On\s+%[2]s\s+(?:at\s+)?%[3]s,?\s+%[4]s\s+wrote:\s+`,
+		// Gmail puts "at" before the time, like "On Mon, Jan 2, 2006 at 3:04
+		// PM, Alice <alice@example.com> wrote:". Some export tools put the
+		// date in brackets, like "On [Mon, 2 Jan 2006 15:04:05 -0700] Alice
+		// wrote:". The brackets are matched in a branch of their own so that
+		// one can't be given without the other.
+		Name:     "OnDateTime",
+		Template: `(?m)^%[1]s(?:>%[1]s)*(?:-{2,3}\s+)?On\s*(?:\[%[2]s\s+(?:at\s+)?%[3]s\]|%[4]s\s+(?:at\s+)?%[5]s),?\s+%[6]s\s+%[7]s%[8]s`,
 		Parts: []attributionRegexPart{
 			attributionRegexLiteral(nonNewlineWhitespaceRegexPart),
 			attributionRegexCaptureDate,
 			attributionRegexCaptureTime,
+			attributionRegexCaptureDate,
+			attributionRegexCaptureTime,
 			attributionRegexCaptureName,
+			attributionRegexLiteral(attributionVerbRegexPart),
+			attributionRegexLiteral(datedAttributionEndRegexPart),
 		},
 		NameFormats: allNameFormats(),
 		DateFormats: allDateFormats(),
 		TimeFormats: allTimeFormats(),
 	},
 	{
-		Template: `(?m)^%[1]s(?:-{2,3}\s+)?On\s+%[2]s,?\s+%[3]s\s+wrote:\s+`,
+		// Some locales put the time before the date.
+		Name:     "OnTimeDate",
+		Template: `(?m)^%[1]s(?:>%[1]s)*(?:-{2,3}\s+)?On\s*(?:\[%[2]s,?\s+%[3]s\]|%[4]s,?\s+%[5]s),?\s+%[6]s\s+%[7]s%[8]s`,
 		Parts: []attributionRegexPart{
 			attributionRegexLiteral(nonNewlineWhitespaceRegexPart),
+			attributionRegexCaptureTime,
+			attributionRegexCaptureDate,
+			attributionRegexCaptureTime,
 			attributionRegexCaptureDate,
 			attributionRegexCaptureName,
+			attributionRegexLiteral(attributionVerbRegexPart),
+			attributionRegexLiteral(datedAttributionEndRegexPart),
+		},
+		NameFormats: allNameFormats(),
+		DateFormats: allDateFormats(),
+		TimeFormats: allTimeFormats(),
+	},
+	{
+		// German clients put the name first, like "Alice schrieb am 2. Jan.
+		// 2006 um 15:04:".
+		Name:     "NameSchriebAmDateUmTime",
+		Lang:     "de",
+		Template: `(?m)^%[1]s(?:>%[1]s)*%[2]s\s+schrieb\s+am\s+%[3]s,?\s+um\s+%[4]s(?:\s+Uhr)?:(?:\s+|\z)`,
+		Parts: []attributionRegexPart{
+			attributionRegexLiteral(nonNewlineWhitespaceRegexPart),
+			attributionRegexCaptureName,
+			attributionRegexCaptureDate,
+			attributionRegexCaptureTime,
+		},
+		NameFormats: allNameFormats(),
+		DateFormats: []dateFormat{
+			dateFormatLongDayMonthYearWeekday,
+			dateFormatLongDayMonthYear,
+			dateFormatNumericDotted,
+		},
+		TimeFormats: allTimeFormats(),
+	},
+	{
+		// French clients put the time after "à" and a space before the colon,
+		// like "Le 2 janvier 2006 à 15:04, Alice a écrit :". Following French
+		// typography, that space is often a non-breaking one.
+		Name:     "LeDateATimeName",
+		Lang:     "fr",
+		Template: `(?m)^%[1]s(?:>%[1]s)*Le\s+%[2]s,?\s+à\s+%[3]s,\s+%[4]s\s+a\s+écrit[\t \x{00A0}\x{202F}]*:(?:\s+|\z)`,
+		Parts: []attributionRegexPart{
+			attributionRegexLiteral(nonNewlineWhitespaceRegexPart),
+			attributionRegexCaptureDate,
+			attributionRegexCaptureTime,
+			attributionRegexCaptureName,
+		},
+		NameFormats: allNameFormats(),
+		DateFormats: []dateFormat{
+			dateFormatLongDayMonthYearWeekday,
+			dateFormatLongDayMonthYear,
+			dateFormatNumericDotted,
+		},
+		TimeFormats: allTimeFormats(),
+	},
+	{
+		// Some clients use a date relative to when the message was sent,
+		// like "On Yesterday at 3:04 PM".
+		Name:     "OnRelativeDateTime",
+		Template: `(?m)^%[1]s(?:>%[1]s)*(?:-{2,3}\s+)?On\s*%[2]s,?\s+(?:at\s+)?%[3]s,?\s+%[4]s\s+%[5]s%[6]s`,
+		Parts: []attributionRegexPart{
+			attributionRegexLiteral(nonNewlineWhitespaceRegexPart),
+			attributionRegexCaptureRelativeDate,
+			attributionRegexCaptureTime,
+			attributionRegexCaptureName,
+			attributionRegexLiteral(attributionVerbRegexPart),
+			attributionRegexLiteral(datedAttributionEndRegexPart),
+		},
+		NameFormats:         allNameFormats(),
+		RelativeDateFormats: allRelativeDateFormats(),
+		TimeFormats:         allTimeFormats(),
+	},
+	{
+		Name:     "OnDate",
+		Template: `(?m)^%[1]s(?:>%[1]s)*(?:-{2,3}\s+)?On\s*(?:\[%[2]s\]|%[3]s),?\s+%[4]s\s+%[5]s%[6]s`,
+		Parts: []attributionRegexPart{
+			attributionRegexLiteral(nonNewlineWhitespaceRegexPart),
+			attributionRegexCaptureDate,
+			attributionRegexCaptureDate,
+			attributionRegexCaptureName,
+			attributionRegexLiteral(attributionVerbRegexPart),
+			attributionRegexLiteral(datedAttributionEndRegexPart),
 		},
 		NameFormats: allNameFormats(),
 		DateFormats: allDateFormats(),
 		TimeFormats: nil,
 	},
 	{
-		Template: `(?m)^%[1]s(?:-{2,3}\s+)?In\s+%[2]s,\s+%[3]s\s+wrote:\s+`,
+		Name:     "InGroupName",
+		Template: `(?m)^%[1]s(?:>%[1]s)*(?:-{2,3}\s+)?In\s+%[2]s,\s+%[3]s\s+%[4]s:(?:\s+|\z)`,
 		Parts: []attributionRegexPart{
 			attributionRegexLiteral(nonNewlineWhitespaceRegexPart),
 			attributionRegexLiteral(attributionGroupEmailRegexPart),
 			attributionRegexCaptureName,
+			attributionRegexLiteral(attributionVerbRegexPart),
 		},
 		NameFormats: allNameFormats(),
 		DateFormats: nil,
 		TimeFormats: nil,
 	},
 	{
-		Template: `(?m)^%[1]s-{2,3}\s+%[2]s\s+wrote:\s+`,
+		// This is the format used by Mutt and some other command-line clients.
+		Name:     "QuotingName",
+		Template: `(?m)^%[1]s(?:>%[1]s)*Quoting\s+%[2]s:(?:\s+|\z)`,
 		Parts: []attributionRegexPart{
 			attributionRegexLiteral(nonNewlineWhitespaceRegexPart),
 			attributionRegexCaptureName,
 		},
+		// Like the "Name" format, this requires an email address to reduce
+		// false positives.
+		NameFormats: allEmailNameFormats(),
+		DateFormats: nil,
+		TimeFormats: nil,
+	},
+	{
+		Name:     "DashName",
+		Template: `(?m)^%[1]s(?:>%[1]s)*-{2,3}\s+%[2]s\s+%[3]s:(?:\s+|\z)`,
+		Parts: []attributionRegexPart{
+			attributionRegexLiteral(nonNewlineWhitespaceRegexPart),
+			attributionRegexCaptureName,
+			attributionRegexLiteral(attributionVerbRegexPart),
+		},
 		NameFormats: allNameFormats(),
 		DateFormats: nil,
 		TimeFormats: nil,
 	},
 	{
-		Template: `(?m)^%[1]s%[2]s%[1]swrote:\s+`,
+		Name:     "Name",
+		Template: `(?m)^%[1]s(?:>%[1]s)*%[2]s%[1]s%[3]s:(?:\s+|\z)`,
 		Parts: []attributionRegexPart{
 			attributionRegexLiteral(nonNewlineWhitespaceRegexPart),
 			attributionRegexCaptureName,
+			attributionRegexLiteral(attributionVerbRegexPart),
 		},
 		// We only allow name formats that include an email address to reduce
 		// the likelihood of false positive matches on this pattern.
@@ -452,56 +994,354 @@ var attributionRegexes = []attributionRegex{
 		DateFormats: nil,
 		TimeFormats: nil,
 	},
+	{
+		// Usenet-to-email bridges wrote attributions in all caps with just
+		// the poster's handle, like "FROM JOHNDOE WROTE:".
+		Name:     "FromHandleWrote",
+		Template: `(?m)^%[1]s(?:>%[1]s)*(?:FROM\s+)?%[2]s\s+WROTE:(?:\s+|\z)`,
+		Parts: []attributionRegexPart{
+			attributionRegexLiteral(nonNewlineWhitespaceRegexPart),
+			attributionRegexCaptureName,
+		},
+		NameFormats: []nameFormat{nameFormatHandle},
+		DateFormats: nil,
+		TimeFormats: nil,
+		Lenient:     true,
+	},
+}
+
+// The attribution regexes are compiled once up front rather than lazily so
+// that matching doesn't need to check or mutate shared state per message.
+// Whether their capture groups line up with their formats is checked by
+// `Validate`, which is run by the tests rather than here, so a mistake can't
+// stop a program using this package from starting.
+func init() {
+	for i := range attributionRegexes {
+		if err := attributionRegexes[i].compile(); err != nil {
+			panic(fmt.Errorf("%w %s: %v", ErrInvalidAttributionRegex, attributionRegexes[i].Name, err))
+		}
+	}
+}
+
+// combineDateAndTime returns the date of `date` at the clock time of `clock`.
+// The time parsed on its own is in year 0, so adding its offset from the
+// zero time to the date, like this used to do, put the result a year early.
+func combineDateAndTime(date, clock time.Time) time.Time {
+	return time.Date(
+		date.Year(), date.Month(), date.Day(),
+		clock.Hour(), clock.Minute(), clock.Second(), clock.Nanosecond(),
+		clock.Location(),
+	)
 }
 
 type AttributionBlock struct {
-	Name    string
+	Name string
+
+	// Email is the address given with the name, if any, like in "Alice
+	// <alice@example.com> wrote:". When the attribution only has an address,
+	// it's also the `Name`.
+	Email string
+
+	// Role is a parenthetical after the name, like "Moderator" in "Alice
+	// (Moderator) wrote:", which isn't included in `Name`.
+	Role string
+
 	Time    time.Time
 	HasTime bool
+	Format  string
+
+	// Lang is the BCP 47 tag of the language of the matched format, like
+	// "fr" for "Le 2 janvier 2006 à 15:04, Alice a écrit :".
+	Lang string
+
+	// TimezoneName is the time zone abbreviation given in the attribution, if
+	// any, like "EST". Spelled-out names which aren't in `TimeZoneNames` are
+	// kept as written, like "Samoa Standard Time".
+	TimezoneName string
+
+	// UnknownOffset is set when the attribution names a time zone whose
+	// offset isn't known. `Time` then has the clock time as written, but in
+	// UTC, so it isn't an exact instant.
+	UnknownOffset bool
+
+	// RawTime is the original text of a relative date and time, like "Today
+	// at 3:04 PM", when it couldn't be resolved because `Reference` is zero.
+	RawTime string
+
+	// Reference is the time the containing message was sent, which is used to
+	// resolve relative dates.
+	Reference time.Time
+
+	// QuoteDepth is the number of quote markers (">") before the attribution
+	// on its own line, like in ">On Mon, Jan 2, 2006, Alice wrote:". This is
+	// only nonzero when the text wasn't already split into quoted lines.
+	QuoteDepth int
+
+	// KeepParsedLocation stores `Time` in the location it was parsed with,
+	// like a fixed offset of -0700, instead of converting it to UTC.
+	KeepParsedLocation bool
+
+	// DayFirst reads slashed numeric dates like "01/02/2006" as DD/MM instead
+	// of MM/DD.
+	DayFirst bool
+
+	// DottedMonthFirst reads numeric dates with periods like "01.02.2006" as
+	// MM.DD instead of DD.MM.
+	DottedMonthFirst bool
+
+	// Lenient also tries formats which are prone to false positives, like
+	// all-caps handles in "FROM JOHNDOE WROTE:".
+	Lenient bool
+
+	// CanonicalizeEmail, when set, is applied to `Email`, and to `Name` when
+	// it's an address, like in "<alice@example.com> wrote:".
+	CanonicalizeEmail func(address string) string
+
+	// Hooks are called when an attribution is parsed or fails to parse.
+	Hooks *ParseHooks
+}
+
+// This matches the address after the name in formats like `nameFormatNameEmail`.
+var attributionAddressAfterNameRegex = regexp.MustCompile(fmt.Sprintf(`^%s?\s+<%s(%s)>`, closeQuoteRegexPart, attributionMailtoRegexPart, attributionEmailRegexPart))
+
+// A role is only split from the end of a name. Names with an address in them
+// are left alone, since the parenthetical in "alice@example.com (Alice)" is
+// the name rather than a role.
+var attributionRoleRegex = regexp.MustCompile(`^([^@]*[^@\s])\s*\(([^()@]*[^()@\s])\)$`)
+
+func splitAttributionRole(name string) (nameWithoutRole, role string) {
+	match := attributionRoleRegex.FindStringSubmatch(name)
+	if match == nil {
+		return name, ""
+	}
+
+	return match[1], strings.TrimSpace(match[2])
+}
+
+// countLeadingQuoteMarkers counts the quote markers at the start of `text`,
+// which may be separated by whitespace.
+func countLeadingQuoteMarkers(text string) int {
+	depth := 0
+
+	for _, char := range text {
+		switch char {
+		case '>':
+			depth++
+		case ' ', '\t':
+		default:
+			return depth
+		}
+	}
+
+	return depth
 }
 
+// fromMatch populates the block from a match of `regex` in `text`, returning
+// an error if the matched date or time can't be parsed.
+func (b *AttributionBlock) fromMatch(regex *attributionRegex, text string, match []int) error {
+	nameStartIndex, nameEndIndex, matchedNameFormat := regex.NameIndices(match)
+	b.Name = text[nameStartIndex:nameEndIndex]
+
+	switch matchedNameFormat {
+	case nameFormatEmail:
+		b.Email = b.Name
+	case nameFormatNameEmail, nameFormatQuotedNameEmail, nameFormatQuotedNameDuplicateEmail:
+		if addressMatch := attributionAddressAfterNameRegex.FindStringSubmatch(text[nameEndIndex:]); addressMatch != nil {
+			b.Email = addressMatch[1]
+		}
+	}
+
+	if b.Email != "" && b.CanonicalizeEmail != nil {
+		b.Email = b.CanonicalizeEmail(b.Email)
+
+		if matchedNameFormat == nameFormatEmail {
+			b.Name = b.Email
+		}
+	}
+
+	b.Name, b.Role = splitAttributionRole(b.Name)
+
+	b.Format = regex.Name
+	b.Lang = regex.Language()
+	b.QuoteDepth = countLeadingQuoteMarkers(text[match[0]:])
+	b.HasTime = regex.HasTime()
+
+	var err error
+
+	if regex.HasDate() {
+		dateStartIndex, dateEndIndex, matchedDateFormat := regex.DateIndices(match)
+		dayFirst := b.DayFirst
+		if matchedDateFormat.IsDotted() {
+			dayFirst = !b.DottedMonthFirst
+		}
+
+		if matchedDateFormat.IsYearless() {
+			// Prose like "On March 3 the team wrote" reads like an
+			// attribution with a date without a year, so those need a colon.
+			if regex.AllowsMissingColon() && !strings.HasSuffix(strings.TrimSpace(text[match[0]:match[1]]), ":") {
+				return fmt.Errorf("%w: %q", ErrYearlessDateWithoutColon, text[match[0]:match[1]])
+			}
+
+			// Like relative dates, the year can only be filled in from the
+			// time the message was sent.
+			if b.Reference.IsZero() {
+				rawTimeStartIndex, rawTimeEndIndex := dateStartIndex, dateEndIndex
+				if regex.HasTime() {
+					timeStartIndex, timeEndIndex, _ := regex.TimeIndices(match)
+
+					if timeStartIndex < rawTimeStartIndex {
+						rawTimeStartIndex = timeStartIndex
+					}
+
+					if timeEndIndex > rawTimeEndIndex {
+						rawTimeEndIndex = timeEndIndex
+					}
+				}
+
+				b.RawTime = text[rawTimeStartIndex:rawTimeEndIndex]
+
+				return nil
+			}
+
+			b.Time, err = matchedDateFormat.ParseYearless(text[dateStartIndex:dateEndIndex], b.Reference)
+		} else {
+			b.Time, err = matchedDateFormat.Parse(text[dateStartIndex:dateEndIndex], dayFirst)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if regex.HasRelativeDate() {
+		relativeDateStartIndex, relativeDateEndIndex, matchedRelativeDateFormat := regex.RelativeDateIndices(match)
+
+		if b.Reference.IsZero() {
+			rawTimeEndIndex := relativeDateEndIndex
+			if regex.HasTime() {
+				_, rawTimeEndIndex, _ = regex.TimeIndices(match)
+			}
+
+			b.RawTime = text[relativeDateStartIndex:rawTimeEndIndex]
+
+			return nil
+		}
+
+		year, month, day := b.Reference.Date()
+		b.Time = time.Date(year, month, day-matchedRelativeDateFormat.DaysAgo(), 0, 0, 0, 0, time.UTC)
+	}
+
+	if regex.HasTime() {
+		timeStartIndex, timeEndIndex, matchedTimeFormat := regex.TimeIndices(match)
+		localTime, err := matchedTimeFormat.Parse(text[timeStartIndex:timeEndIndex])
+		if err != nil {
+			return err
+		}
+
+		if matchedTimeFormat.HasTimeZoneName() {
+			b.TimezoneName, _ = localTime.Zone()
+		}
+
+		b.UnknownOffset = matchedTimeFormat.HasUnknownOffset(text[timeStartIndex:timeEndIndex])
+
+		if b.Time.IsZero() {
+			b.Time = localTime
+		} else {
+			b.Time = combineDateAndTime(b.Time, localTime)
+
+			if !b.KeepParsedLocation {
+				b.Time = b.Time.UTC()
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r *attributionRegex) specificity() int {
+	specificity := 0
+
+	if r.HasDate() || r.HasRelativeDate() {
+		specificity += 2
+	}
+
+	if r.HasTime() {
+		specificity++
+	}
+
+	return specificity
+}
+
+// FromText tries every attribution regex and picks a match by these rules, in
+// order:
+//
+//  1. The match which starts earliest in the text.
+//  2. The match from the more specific regex, where a date is more specific
+//     than a time, and both are more specific than neither.
+//  3. The match from the regex which comes first in `attributionRegexes`.
+//
+// Matches whose date or time can't be parsed are ignored. Any text after the
+// colon on the same line, like in "Alice wrote: I agree", is returned as the
+// start of `after` rather than preventing the match. Otherwise the line break
+// after the colon is part of the match, so when the quote starts on the next
+// line, like in "Alice wrote:\n> I agree", `after` starts at its ">".
 func (b *AttributionBlock) FromText(text string) (ok bool, before, after string) {
+	var (
+		bestBlock AttributionBlock
+		bestRegex *attributionRegex
+		bestMatch []int
+	)
+
 	for i := range attributionRegexes {
 		regex := &attributionRegexes[i]
 
-		match := regex.Regex().FindStringSubmatchIndex(text)
-		if match == nil {
+		if regex.Lenient && !b.Lenient {
 			continue
 		}
 
-		matchStartIndex, matchEndIndex := match[0], match[1]
+		// Most paragraphs don't match, and checking that first avoids
+		// allocating the indices of the capture groups for them.
+		if !regex.Regex().MatchString(text) {
+			continue
+		}
 
-		nameStartIndex, nameEndIndex, _ := regex.NameIndices(match)
-		b.Name = text[nameStartIndex:nameEndIndex]
+		match := regex.Regex().FindStringSubmatchIndex(text)
+		if match == nil {
+			continue
+		}
 
-		var err error
+		if bestMatch != nil {
+			isEarlier := match[0] < bestMatch[0]
+			isMoreSpecific := match[0] == bestMatch[0] && regex.specificity() > bestRegex.specificity()
 
-		if regex.HasDate() {
-			dateStartIndex, dateEndIndex, matchedDateFormat := regex.DateIndices(match)
-			b.Time, err = time.Parse(matchedDateFormat.FormatString(), text[dateStartIndex:dateEndIndex])
-			if err != nil {
+			if !isEarlier && !isMoreSpecific {
 				continue
 			}
 		}
 
-		if regex.HasTime() {
-			timeStartIndex, timeEndIndex, matchedTimeFormat := regex.TimeIndices(match)
-			localTime, err := time.Parse(matchedTimeFormat.FormatString(), text[timeStartIndex:timeEndIndex])
-			if err != nil {
-				continue
-			}
-
-			if b.Time.IsZero() {
-				b.Time = localTime
-			} else {
-				b.Time = b.Time.Add(localTime.Sub(time.Time{}))
-			}
+		candidate := AttributionBlock{
+			Reference:          b.Reference,
+			DayFirst:           b.DayFirst,
+			DottedMonthFirst:   b.DottedMonthFirst,
+			KeepParsedLocation: b.KeepParsedLocation,
+			Lenient:            b.Lenient,
+			CanonicalizeEmail:  b.CanonicalizeEmail,
+			Hooks:              b.Hooks,
+		}
+		if err := candidate.fromMatch(regex, text, match); err != nil {
+			b.Hooks.attributionFailed(regex.Name, err)
+			continue
 		}
 
-		b.HasTime = regex.HasTime()
+		bestBlock, bestRegex, bestMatch = candidate, regex, match
+	}
 
-		return true, text[:matchStartIndex], text[matchEndIndex:]
+	if bestMatch == nil {
+		return false, "", ""
 	}
 
-	return false, "", ""
+	*b = bestBlock
+	b.Hooks.attributionMatched(b.Format)
+
+	return true, text[:bestMatch[0]], text[bestMatch[1]:]
 }
@@ -0,0 +1,1115 @@
+package block
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	if err := Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// parseAttribution parses `text` with the options in `attribution`, failing
+// the test if it isn't an attribution.
+func parseAttribution(t *testing.T, attribution *AttributionBlock, text string) (before, after string) {
+	t.Helper()
+
+	ok, before, after := attribution.FromText(text)
+	if !ok {
+		t.Fatalf("FromText(%q) didn't match", text)
+	}
+
+	return before, after
+}
+
+func TestGmailAttribution(t *testing.T) {
+	text := "On Mon, Jan 2, 2006 at 3:04 PM, Alice Example <alice@example.com> wrote:\n"
+
+	attribution := &AttributionBlock{}
+	parseAttribution(t, attribution, text)
+
+	if attribution.Name != "Alice Example" || attribution.Email != "alice@example.com" {
+		t.Errorf("Name, Email = %q, %q", attribution.Name, attribution.Email)
+	}
+
+	if want := time.Date(2006, time.January, 2, 15, 4, 0, 0, time.UTC); !attribution.Time.Equal(want) || !attribution.HasTime {
+		t.Errorf("Time = %v, HasTime = %v, want %v", attribution.Time, attribution.HasTime, want)
+	}
+
+	if attribution.Format != "OnDateTime" {
+		t.Errorf("Format = %q", attribution.Format)
+	}
+}
+
+func TestAttributionWithoutColon(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		wantOk   bool
+		wantName string
+	}{
+		{"at the end of the text", "On Mon, 2 Jan 2006, Alice wrote\n", true, "Alice"},
+		{"before a blank line", "On Mon, 2 Jan 2006, Alice wrote\n\nI agree.\n", true, "Alice"},
+		{"with a colon before more text", "On Mon, 2 Jan 2006, Alice wrote:\nthat it is good\n", true, "Alice"},
+		{"before more text", "On Mon, 2 Jan 2006, Alice wrote\nthat it is good\n", false, ""},
+		{"without a date", "Alice wrote\n", false, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			attribution := &AttributionBlock{}
+
+			ok, _, _ := attribution.FromText(test.text)
+			if ok != test.wantOk {
+				t.Fatalf("FromText(%q) ok = %v, want %v", test.text, ok, test.wantOk)
+			}
+
+			if attribution.Name != test.wantName {
+				t.Errorf("FromText(%q) Name = %q, want %q", test.text, attribution.Name, test.wantName)
+			}
+		})
+	}
+}
+
+func TestYearlessAttribution(t *testing.T) {
+	reference := time.Date(2006, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		text        string
+		reference   time.Time
+		wantTime    time.Time
+		wantRawTime string
+		wantHasTime bool
+	}{
+		{
+			name:      "with a reference",
+			text:      "On Mon, 2 Jan, Alice wrote:\n",
+			reference: reference,
+			wantTime:  time.Date(2006, time.January, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "with a weekday from an earlier year",
+			text:      "On Sun, 2 Jan, Alice wrote:\n",
+			reference: reference,
+			wantTime:  time.Date(2005, time.January, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:        "without a reference",
+			text:        "On Mon, 2 Jan, Alice wrote:\n",
+			wantRawTime: "Mon, 2 Jan",
+		},
+		{
+			name:        "with a time and without a reference",
+			text:        "On Mon, 2 Jan 15:04, Alice wrote:\n",
+			wantRawTime: "Mon, 2 Jan 15:04",
+			wantHasTime: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			attribution := &AttributionBlock{Reference: test.reference}
+
+			if ok, _, _ := attribution.FromText(test.text); !ok {
+				t.Fatalf("FromText(%q) didn't match", test.text)
+			}
+
+			if !attribution.Time.Equal(test.wantTime) {
+				t.Errorf("Time = %v, want %v", attribution.Time, test.wantTime)
+			}
+
+			if attribution.RawTime != test.wantRawTime {
+				t.Errorf("RawTime = %q, want %q", attribution.RawTime, test.wantRawTime)
+			}
+
+			if attribution.HasTime != test.wantHasTime {
+				t.Errorf("HasTime = %v, want %v", attribution.HasTime, test.wantHasTime)
+			}
+		})
+	}
+}
+
+func TestYearlessAttributionNeedsColon(t *testing.T) {
+	for _, text := range []string{
+		"On March 3 the team wrote\n",
+		"On Jan 2 Bob wrote\n",
+	} {
+		attribution := &AttributionBlock{}
+
+		if ok, _, _ := attribution.FromText(text); ok {
+			t.Errorf("FromText(%q) matched %+v", text, attribution)
+		}
+	}
+}
+
+// These are typical paragraphs from an archive: most have no attribution, and
+// the rest have one at the start of a reply.
+var benchmarkAttributionTexts = []string{
+	"Thanks for the update. I'll try the new version this weekend and let you\nknow how it goes with the larger files.\n",
+	"On Mon, Jan 2, 2006 at 3:04 PM, Alice Example <alice@example.com> wrote:\n",
+	"I don't think that's right, since the meeting was moved to Thursday and\nnobody told the rest of us about it until the day before.\n",
+	"--- In group@yahoogroups.com, \"Bob\" <bob@...> wrote:\n",
+}
+
+// Before checking for a match ahead of finding capture groups and working out
+// the capture groups of each regex when it's compiled, this was about 2128
+// B/op and 51 allocs/op. It's now about 1248 B/op and 19 allocs/op, at about
+// the same speed.
+func BenchmarkAttributionFromText(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		for _, text := range benchmarkAttributionTexts {
+			attribution := &AttributionBlock{}
+			attribution.FromText(text)
+		}
+	}
+}
+
+func TestAttributionTimeOrder(t *testing.T) {
+	want := time.Date(2006, time.January, 2, 15, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		text string
+	}{
+		{"date first", "On Mon, 2 Jan 2006 15:04:05, Alice wrote:\n"},
+		{"time first", "On 15:04:05 Mon, 2 Jan 2006, Alice wrote:\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			attribution := &AttributionBlock{}
+			parseAttribution(t, attribution, test.text)
+
+			if !attribution.Time.Equal(want) || !attribution.HasTime {
+				t.Errorf("FromText(%q) Time = %v, HasTime = %v, want %v", test.text, attribution.Time, attribution.HasTime, want)
+			}
+
+			if attribution.Name != "Alice" {
+				t.Errorf("FromText(%q) Name = %q, want %q", test.text, attribution.Name, "Alice")
+			}
+		})
+	}
+}
+
+func TestAttributionMeridiem(t *testing.T) {
+	want := time.Date(2006, time.January, 2, 15, 4, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		text string
+	}{
+		{"uppercase", "On Mon, Jan 2, 2006 at 3:04 PM, Alice wrote:\n"},
+		{"lowercase", "On Mon, Jan 2, 2006 at 3:04 pm, Alice wrote:\n"},
+		{"dotted", "On Mon, Jan 2, 2006 at 3:04 p.m., Alice wrote:\n"},
+		{"without a space", "On Mon, Jan 2, 2006 at 3:04pm, Alice wrote:\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			attribution := &AttributionBlock{}
+			parseAttribution(t, attribution, test.text)
+
+			if !attribution.Time.Equal(want) || !attribution.HasTime {
+				t.Errorf("FromText(%q) Time = %v, HasTime = %v, want %v", test.text, attribution.Time, attribution.HasTime, want)
+			}
+
+			if attribution.Name != "Alice" {
+				t.Errorf("FromText(%q) Name = %q, want %q", test.text, attribution.Name, "Alice")
+			}
+		})
+	}
+}
+
+func TestAttributionWithoutSpaceAfterOn(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		wantOk   bool
+		wantName string
+	}{
+		{"with a space", "On Mon, 2 Jan 2006, Alice wrote:\n", true, "Alice"},
+		{"without a space", "OnMon, 2 Jan 2006, Alice wrote:\n", true, "Alice"},
+		{"word starting with on", "Online, Alice wrote:\n", false, ""},
+		{"word starting with on before a date", "Online 2 Jan 2006, Alice wrote:\n", false, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			attribution := &AttributionBlock{}
+
+			ok, _, _ := attribution.FromText(test.text)
+			if ok != test.wantOk {
+				t.Fatalf("FromText(%q) ok = %v, want %v", test.text, ok, test.wantOk)
+			}
+
+			if attribution.Name != test.wantName {
+				t.Errorf("FromText(%q) Name = %q, want %q", test.text, attribution.Name, test.wantName)
+			}
+		})
+	}
+}
+
+func TestRelativeAttribution(t *testing.T) {
+	reference := time.Date(2006, time.January, 2, 18, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		text        string
+		reference   time.Time
+		wantTime    time.Time
+		wantRawTime string
+	}{
+		{
+			name:      "today",
+			text:      "On Today at 3:04 PM, Alice wrote:\n",
+			reference: reference,
+			wantTime:  time.Date(2006, time.January, 2, 15, 4, 0, 0, time.UTC),
+		},
+		{
+			name:      "yesterday",
+			text:      "On Yesterday at 3:04 PM, Alice wrote:\n",
+			reference: reference,
+			wantTime:  time.Date(2006, time.January, 1, 15, 4, 0, 0, time.UTC),
+		},
+		{
+			name:        "today without a reference",
+			text:        "On Today at 3:04 PM, Alice wrote:\n",
+			wantRawTime: "Today at 3:04 PM",
+		},
+		{
+			name:        "yesterday without a reference",
+			text:        "On Yesterday at 3:04 PM, Alice wrote:\n",
+			wantRawTime: "Yesterday at 3:04 PM",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			attribution := &AttributionBlock{Reference: test.reference}
+			parseAttribution(t, attribution, test.text)
+
+			if !attribution.Time.Equal(test.wantTime) {
+				t.Errorf("Time = %v, want %v", attribution.Time, test.wantTime)
+			}
+
+			if attribution.RawTime != test.wantRawTime {
+				t.Errorf("RawTime = %q, want %q", attribution.RawTime, test.wantRawTime)
+			}
+
+			if attribution.Name != "Alice" {
+				t.Errorf("Name = %q, want %q", attribution.Name, "Alice")
+			}
+		})
+	}
+}
+
+func TestAttributionTimezoneName(t *testing.T) {
+	want := time.Date(2006, time.January, 2, 20, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name             string
+		text             string
+		wantTimezoneName string
+	}{
+		{"bare", "On Mon, 2 Jan 2006 15:04:05 -0500 EST, Alice wrote:\n", "EST"},
+		{"in parentheses", "On Mon, 2 Jan 2006 15:04:05 -0500 (EST), Alice wrote:\n", "EST"},
+		{"without a name", "On Mon, 2 Jan 2006 15:04:05 -0500, Alice wrote:\n", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			attribution := &AttributionBlock{}
+			parseAttribution(t, attribution, test.text)
+
+			if !attribution.Time.Equal(want) {
+				t.Errorf("FromText(%q) Time = %v, want %v", test.text, attribution.Time, want)
+			}
+
+			if attribution.TimezoneName != test.wantTimezoneName {
+				t.Errorf("FromText(%q) TimezoneName = %q, want %q", test.text, attribution.TimezoneName, test.wantTimezoneName)
+			}
+
+			if attribution.Name != "Alice" {
+				t.Errorf("FromText(%q) Name = %q, want %q", test.text, attribution.Name, "Alice")
+			}
+		})
+	}
+}
+
+func TestAttributionFullTimezoneName(t *testing.T) {
+	tests := []struct {
+		name              string
+		text              string
+		wantTime          time.Time
+		wantTimezoneName  string
+		wantUnknownOffset bool
+	}{
+		{
+			name:             "Pacific Standard Time",
+			text:             "On Mon, Jan 2, 2006 at 3:04 PM Pacific Standard Time, Alice wrote:\n",
+			wantTime:         time.Date(2006, time.January, 2, 23, 4, 0, 0, time.UTC),
+			wantTimezoneName: "PST",
+		},
+		{
+			name:             "Eastern Daylight Time",
+			text:             "On Mon, 2 Jan 2006 15:04 Eastern Daylight Time, Alice wrote:\n",
+			wantTime:         time.Date(2006, time.January, 2, 19, 4, 0, 0, time.UTC),
+			wantTimezoneName: "EDT",
+		},
+		{
+			name:              "unknown name in parentheses",
+			text:              "On Mon, Jan 2, 2006 at 3:04 PM (Samoa Standard Time), Alice wrote:\n",
+			wantTime:          time.Date(2006, time.January, 2, 15, 4, 0, 0, time.UTC),
+			wantTimezoneName:  "Samoa Standard Time",
+			wantUnknownOffset: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			attribution := &AttributionBlock{}
+			parseAttribution(t, attribution, test.text)
+
+			if !attribution.Time.Equal(test.wantTime) {
+				t.Errorf("FromText(%q) Time = %v, want %v", test.text, attribution.Time, test.wantTime)
+			}
+
+			if attribution.TimezoneName != test.wantTimezoneName || attribution.UnknownOffset != test.wantUnknownOffset {
+				t.Errorf("FromText(%q) TimezoneName, UnknownOffset = %q, %v, want %q, %v", test.text, attribution.TimezoneName, attribution.UnknownOffset, test.wantTimezoneName, test.wantUnknownOffset)
+			}
+
+			if attribution.Name != "Alice" {
+				t.Errorf("FromText(%q) Name = %q, want %q", test.text, attribution.Name, "Alice")
+			}
+		})
+	}
+}
+
+func TestAttributionParenTimezoneWithoutOffset(t *testing.T) {
+	tests := []struct {
+		name              string
+		text              string
+		wantTime          time.Time
+		wantTimezoneName  string
+		wantUnknownOffset bool
+	}{
+		{
+			name:             "UTC",
+			text:             "On Mon, 2 Jan 2006 15:04:05 (UTC), Alice wrote:\n",
+			wantTime:         time.Date(2006, time.January, 2, 15, 4, 5, 0, time.UTC),
+			wantTimezoneName: "UTC",
+		},
+		{
+			name:             "GMT without seconds",
+			text:             "On Mon, 2 Jan 2006 15:04 (GMT), Alice wrote:\n",
+			wantTime:         time.Date(2006, time.January, 2, 15, 4, 0, 0, time.UTC),
+			wantTimezoneName: "GMT",
+		},
+		{
+			name:              "unknown",
+			text:              "On Mon, 2 Jan 2006 15:04:05 (EST), Alice wrote:\n",
+			wantTime:          time.Date(2006, time.January, 2, 15, 4, 5, 0, time.UTC),
+			wantTimezoneName:  "EST",
+			wantUnknownOffset: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			attribution := &AttributionBlock{}
+			parseAttribution(t, attribution, test.text)
+
+			if !attribution.Time.Equal(test.wantTime) || !attribution.HasTime {
+				t.Errorf("FromText(%q) Time, HasTime = %v, %v, want %v, true", test.text, attribution.Time, attribution.HasTime, test.wantTime)
+			}
+
+			if attribution.TimezoneName != test.wantTimezoneName || attribution.UnknownOffset != test.wantUnknownOffset {
+				t.Errorf("FromText(%q) TimezoneName, UnknownOffset = %q, %v, want %q, %v", test.text, attribution.TimezoneName, attribution.UnknownOffset, test.wantTimezoneName, test.wantUnknownOffset)
+			}
+
+			if attribution.Name != "Alice" {
+				t.Errorf("FromText(%q) Name = %q, want %q", test.text, attribution.Name, "Alice")
+			}
+		})
+	}
+}
+
+func TestAttributionPrecedence(t *testing.T) {
+	tests := []struct {
+		name       string
+		text       string
+		wantFormat string
+		wantName   string
+	}{
+		{
+			// The "Name" regex comes after the dated ones but matches first.
+			name:       "earliest match",
+			text:       "Alice <alice@example.com> wrote:\nOn Mon, 2 Jan 2006 15:04, Bob <bob@example.com> wrote:\n",
+			wantFormat: "Name",
+			wantName:   "Alice",
+		},
+		{
+			name:       "earliest dated match",
+			text:       "On Mon, 2 Jan 2006 15:04, Bob <bob@example.com> wrote:\nAlice <alice@example.com> wrote:\n",
+			wantFormat: "OnDateTime",
+			wantName:   "Bob",
+		},
+		{
+			// Both "DashName" and "Name" match this line, and neither has a
+			// date or time.
+			name:       "first regex among equally specific matches",
+			text:       "--- Alice <alice@example.com> wrote:\n",
+			wantFormat: "DashName",
+			wantName:   "Alice",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			attribution := &AttributionBlock{}
+			parseAttribution(t, attribution, test.text)
+
+			if attribution.Format != test.wantFormat || attribution.Name != test.wantName {
+				t.Errorf("FromText(%q) Format, Name = %q, %q, want %q, %q", test.text, attribution.Format, attribution.Name, test.wantFormat, test.wantName)
+			}
+		})
+	}
+}
+
+func findAttributionRegex(t *testing.T, name string) *attributionRegex {
+	t.Helper()
+
+	for i := range attributionRegexes {
+		if attributionRegexes[i].Name == name {
+			return &attributionRegexes[i]
+		}
+	}
+
+	t.Fatalf("no attribution regex named %q", name)
+
+	return nil
+}
+
+func TestAttributionRegexSpecificity(t *testing.T) {
+	// Each of these is more specific than the next.
+	names := []string{"OnDateTime", "OnDate", "Name"}
+
+	for i := 0; i+1 < len(names); i++ {
+		moreSpecific, lessSpecific := findAttributionRegex(t, names[i]), findAttributionRegex(t, names[i+1])
+
+		if moreSpecific.specificity() <= lessSpecific.specificity() {
+			t.Errorf("specificity of %s = %d, want more than %s = %d", names[i], moreSpecific.specificity(), names[i+1], lessSpecific.specificity())
+		}
+	}
+
+	if dated, relative := findAttributionRegex(t, "OnDateTime"), findAttributionRegex(t, "OnRelativeDateTime"); dated.specificity() != relative.specificity() {
+		t.Errorf("specificity of OnDateTime = %d, want OnRelativeDateTime = %d", dated.specificity(), relative.specificity())
+	}
+}
+
+func TestBracketedAttributionDate(t *testing.T) {
+	tests := []struct {
+		name        string
+		text        string
+		wantOk      bool
+		wantTime    time.Time
+		wantHasTime bool
+	}{
+		{
+			name:        "bracketed date and time",
+			text:        "On [Mon, 2 Jan 2006 15:04:05 -0700] Alice wrote:\n",
+			wantOk:      true,
+			wantTime:    time.Date(2006, time.January, 2, 22, 4, 5, 0, time.UTC),
+			wantHasTime: true,
+		},
+		{
+			name:     "bracketed date",
+			text:     "On [2 Jan 2006] Alice wrote:\n",
+			wantOk:   true,
+			wantTime: time.Date(2006, time.January, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:        "unbracketed",
+			text:        "On Mon, 2 Jan 2006 15:04:05 -0700, Alice wrote:\n",
+			wantOk:      true,
+			wantTime:    time.Date(2006, time.January, 2, 22, 4, 5, 0, time.UTC),
+			wantHasTime: true,
+		},
+		{
+			name:   "unclosed bracket",
+			text:   "On [Mon, 2 Jan 2006 15:04:05 -0700, Alice wrote:\n",
+			wantOk: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			attribution := &AttributionBlock{}
+
+			ok, _, _ := attribution.FromText(test.text)
+			if ok != test.wantOk {
+				t.Fatalf("FromText(%q) ok = %v, want %v", test.text, ok, test.wantOk)
+			}
+
+			if !ok {
+				return
+			}
+
+			if !attribution.Time.Equal(test.wantTime) || attribution.HasTime != test.wantHasTime {
+				t.Errorf("FromText(%q) Time = %v, HasTime = %v, want %v, %v", test.text, attribution.Time, attribution.HasTime, test.wantTime, test.wantHasTime)
+			}
+
+			if attribution.Name != "Alice" {
+				t.Errorf("FromText(%q) Name = %q, want %q", test.text, attribution.Name, "Alice")
+			}
+		})
+	}
+}
+
+func TestVerboseAttributionDate(t *testing.T) {
+	tests := []struct {
+		name           string
+		text           string
+		wantTime       time.Time
+		wantDateFormat dateFormat
+	}{
+		{
+			name:           "verbose",
+			text:           "On the 2nd of January 2006, Alice wrote:\n",
+			wantTime:       time.Date(2006, time.January, 2, 0, 0, 0, 0, time.UTC),
+			wantDateFormat: dateFormatLongOrdinalDayOfMonth,
+		},
+		{
+			name:           "verbose without the",
+			text:           "On 23rd of October 2006, Alice wrote:\n",
+			wantTime:       time.Date(2006, time.October, 23, 0, 0, 0, 0, time.UTC),
+			wantDateFormat: dateFormatLongOrdinalDayOfMonth,
+		},
+		{
+			name:           "standard",
+			text:           "On Mon, 2 Jan 2006, Alice wrote:\n",
+			wantTime:       time.Date(2006, time.January, 2, 0, 0, 0, 0, time.UTC),
+			wantDateFormat: dateFormatLongDayMonthYearWeekday,
+		},
+	}
+
+	regex := findAttributionRegex(t, "OnDate")
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			attribution := &AttributionBlock{}
+			parseAttribution(t, attribution, test.text)
+
+			if !attribution.Time.Equal(test.wantTime) || attribution.Name != "Alice" {
+				t.Errorf("FromText(%q) Time, Name = %v, %q, want %v, %q", test.text, attribution.Time, attribution.Name, test.wantTime, "Alice")
+			}
+
+			match := regex.Regex().FindStringSubmatchIndex(test.text)
+			if match == nil {
+				t.Fatalf("OnDate doesn't match %q", test.text)
+			}
+
+			if _, _, format := regex.DateIndices(match); format != test.wantDateFormat {
+				t.Errorf("date format of %q = %s, want %s", test.text, format, test.wantDateFormat)
+			}
+		})
+	}
+}
+
+func TestSlashedAttributionDate(t *testing.T) {
+	tests := []struct {
+		name        string
+		text        string
+		dayFirst    bool
+		wantTime    time.Time
+		wantHasTime bool
+	}{
+		{
+			name:        "with a time",
+			text:        "On 01/02/2006 15:04:05, Alice wrote:\n",
+			wantTime:    time.Date(2006, time.January, 2, 15, 4, 5, 0, time.UTC),
+			wantHasTime: true,
+		},
+		{
+			name:     "without a time",
+			text:     "On 01/02/2006, Alice wrote:\n",
+			wantTime: time.Date(2006, time.January, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:        "day first with a time",
+			text:        "On 01/02/2006 15:04:05, Alice wrote:\n",
+			dayFirst:    true,
+			wantTime:    time.Date(2006, time.February, 1, 15, 4, 5, 0, time.UTC),
+			wantHasTime: true,
+		},
+		{
+			name:     "day first without a time",
+			text:     "On 01/02/2006, Alice wrote:\n",
+			dayFirst: true,
+			wantTime: time.Date(2006, time.February, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			attribution := &AttributionBlock{DayFirst: test.dayFirst}
+			parseAttribution(t, attribution, test.text)
+
+			if !attribution.Time.Equal(test.wantTime) || attribution.HasTime != test.wantHasTime {
+				t.Errorf("FromText(%q) Time, HasTime = %v, %v, want %v, %v", test.text, attribution.Time, attribution.HasTime, test.wantTime, test.wantHasTime)
+			}
+
+			if attribution.Name != "Alice" {
+				t.Errorf("FromText(%q) Name = %q, want %q", test.text, attribution.Name, "Alice")
+			}
+		})
+	}
+}
+
+func TestDottedAttributionDate(t *testing.T) {
+	tests := []struct {
+		name        string
+		text        string
+		dayFirst    bool
+		monthFirst  bool
+		wantTime    time.Time
+		wantHasTime bool
+	}{
+		{
+			name:        "with a time",
+			text:        "On 2.1.2006 15:04, Alice wrote:\n",
+			wantTime:    time.Date(2006, time.January, 2, 15, 4, 0, 0, time.UTC),
+			wantHasTime: true,
+		},
+		{
+			name:     "without a time",
+			text:     "On 02.01.2006, Alice wrote:\n",
+			wantTime: time.Date(2006, time.January, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			// Reading slashed dates day first doesn't change dotted ones.
+			name:     "slashed dates day first",
+			text:     "On 02.01.2006, Alice wrote:\n",
+			dayFirst: true,
+			wantTime: time.Date(2006, time.January, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "month first",
+			text:       "On 02.01.2006, Alice wrote:\n",
+			monthFirst: true,
+			wantTime:   time.Date(2006, time.February, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			attribution := &AttributionBlock{DayFirst: test.dayFirst, DottedMonthFirst: test.monthFirst}
+			parseAttribution(t, attribution, test.text)
+
+			if !attribution.Time.Equal(test.wantTime) || attribution.HasTime != test.wantHasTime {
+				t.Errorf("FromText(%q) Time, HasTime = %v, %v, want %v, %v", test.text, attribution.Time, attribution.HasTime, test.wantTime, test.wantHasTime)
+			}
+
+			if attribution.Name != "Alice" {
+				t.Errorf("FromText(%q) Name = %q, want %q", test.text, attribution.Name, "Alice")
+			}
+		})
+	}
+
+	for _, text := range []string{"Version 1.2.2006 is out.\n", "The server at 10.1.2006 is down.\n"} {
+		if ok, _, _ := (&AttributionBlock{}).FromText(text); ok {
+			t.Errorf("FromText(%q) ok = true, want false", text)
+		}
+	}
+}
+
+func TestQuotedAttributionLine(t *testing.T) {
+	tests := []struct {
+		name           string
+		text           string
+		wantQuoteDepth int
+	}{
+		{"unquoted", "On Mon, Jan 2, 2006 at 3:04 PM, Alice wrote:\n", 0},
+		{"quoted", ">On Mon, Jan 2, 2006 at 3:04 PM, Alice wrote:\n", 1},
+		{"quoted twice with spaces", "> > On Mon, Jan 2, 2006 at 3:04 PM, Alice wrote:\n", 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			attribution := &AttributionBlock{}
+			parseAttribution(t, attribution, test.text)
+
+			if attribution.QuoteDepth != test.wantQuoteDepth {
+				t.Errorf("FromText(%q) QuoteDepth = %d, want %d", test.text, attribution.QuoteDepth, test.wantQuoteDepth)
+			}
+
+			if attribution.Name != "Alice" {
+				t.Errorf("FromText(%q) Name = %q, want %q", test.text, attribution.Name, "Alice")
+			}
+		})
+	}
+}
+
+func TestAttributionVerbs(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		wantOk   bool
+		wantTime bool
+	}{
+		{"wrote with a date", "On Mon, 2 Jan 2006 15:04, Alice wrote:\n", true, true},
+		{"writes with a date", "On Mon, 2 Jan 2006 15:04, Alice writes:\n", true, true},
+		{"says with a date", "On Mon, 2 Jan 2006 15:04, Alice says:\n", true, true},
+		{"writes with an address", "Alice <alice@example.com> writes:\n", true, false},
+		{"says with an address", "Alice <alice@example.com> says:\n", true, false},
+		{"other verb", "Alice <alice@example.com> thinks:\n", false, false},
+		{"verb inside a word", "On Mon, 2 Jan 2006 15:04, Alice rewrites:\n", false, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			attribution := &AttributionBlock{}
+
+			ok, _, _ := attribution.FromText(test.text)
+			if ok != test.wantOk {
+				t.Fatalf("FromText(%q) ok = %v, want %v", test.text, ok, test.wantOk)
+			}
+
+			if !ok {
+				return
+			}
+
+			if attribution.Name != "Alice" {
+				t.Errorf("FromText(%q) Name = %q, want %q", test.text, attribution.Name, "Alice")
+			}
+
+			if hasTime := !attribution.Time.IsZero(); hasTime != test.wantTime {
+				t.Errorf("FromText(%q) has a time = %v, want %v", test.text, hasTime, test.wantTime)
+			}
+		})
+	}
+}
+
+func TestAttributionWithTextAfterColon(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		wantAfter string
+	}{
+		{"same line", "On Mon, 2 Jan 2006 15:04, Alice wrote: I agree, because it's simpler.\n", "I agree, because it's simpler.\n"},
+		{"same line and more", "On Mon, 2 Jan 2006 15:04, Alice wrote: I agree.\nAnd so does Bob.\n", "I agree.\nAnd so does Bob.\n"},
+		{"next line", "On Mon, 2 Jan 2006 15:04, Alice wrote:\n> I agree.\n", "> I agree.\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			attribution := &AttributionBlock{}
+
+			before, after := parseAttribution(t, attribution, test.text)
+			if before != "" || after != test.wantAfter {
+				t.Errorf("FromText(%q) = %q, %q, want %q, %q", test.text, before, after, "", test.wantAfter)
+			}
+
+			if attribution.Name != "Alice" {
+				t.Errorf("FromText(%q) Name = %q, want %q", test.text, attribution.Name, "Alice")
+			}
+		})
+	}
+}
+
+func TestLocalizedAttributionDate(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		wantTime time.Time
+		wantLang string
+	}{
+		{
+			name:     "German abbreviated month",
+			text:     "Alice schrieb am 2. Mär. 2006 um 15:04:\n",
+			wantTime: time.Date(2006, time.March, 2, 15, 4, 0, 0, time.UTC),
+			wantLang: "de",
+		},
+		{
+			name:     "German weekday and month",
+			text:     "Alice schrieb am Mo., 4. Dez. 2006 um 15:04 Uhr:\n",
+			wantTime: time.Date(2006, time.December, 4, 15, 4, 0, 0, time.UTC),
+			wantLang: "de",
+		},
+		{
+			name:     "French abbreviated month",
+			text:     "Le 2 févr. 2006 à 15:04, Alice a écrit :\n",
+			wantTime: time.Date(2006, time.February, 2, 15, 4, 0, 0, time.UTC),
+			wantLang: "fr",
+		},
+		{
+			name:     "French weekday and month",
+			text:     "Le lundi 2 janvier 2006 à 15:04, Alice a écrit :\n",
+			wantTime: time.Date(2006, time.January, 2, 15, 4, 0, 0, time.UTC),
+			wantLang: "fr",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			attribution := &AttributionBlock{}
+			parseAttribution(t, attribution, test.text)
+
+			if !attribution.Time.Equal(test.wantTime) {
+				t.Errorf("FromText(%q) Time = %v, want %v", test.text, attribution.Time, test.wantTime)
+			}
+
+			if attribution.Name != "Alice" || attribution.Lang != test.wantLang {
+				t.Errorf("FromText(%q) Name, Lang = %q, %q, want %q, %q", test.text, attribution.Name, attribution.Lang, "Alice", test.wantLang)
+			}
+		})
+	}
+}
+
+func TestGermanAttribution(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		wantName  string
+		wantEmail string
+	}{
+		{"long date", "Alice Example <alice@example.com> schrieb am 2. Januar 2006 um 15:04:\n", "Alice Example", "alice@example.com"},
+		{"abbreviated month", "Alice Example schrieb am 2. Jan 2006 um 15:04:\n", "Alice Example", ""},
+		{"numeric date", "Alice Example schrieb am 02.01.2006 um 15:04 Uhr:\n", "Alice Example", ""},
+	}
+
+	want := time.Date(2006, time.January, 2, 15, 4, 0, 0, time.UTC)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			attribution := &AttributionBlock{}
+			parseAttribution(t, attribution, test.text)
+
+			if attribution.Name != test.wantName || attribution.Email != test.wantEmail {
+				t.Errorf("FromText(%q) Name, Email = %q, %q, want %q, %q", test.text, attribution.Name, attribution.Email, test.wantName, test.wantEmail)
+			}
+
+			if !attribution.Time.Equal(want) || !attribution.HasTime {
+				t.Errorf("FromText(%q) Time, HasTime = %v, %v, want %v, true", test.text, attribution.Time, attribution.HasTime, want)
+			}
+
+			if attribution.Format != "NameSchriebAmDateUmTime" {
+				t.Errorf("FromText(%q) Format = %q", test.text, attribution.Format)
+			}
+		})
+	}
+}
+
+func TestQuotedAttributionName(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+	}{
+		{"straight quotes", "On Mon, 2 Jan 2006 15:04, \"Alice Example\" <alice@example.com> wrote:\n"},
+		{"smart double quotes", "On Mon, 2 Jan 2006 15:04, “Alice Example” <alice@example.com> wrote:\n"},
+		{"smart quotes without an address", "On Mon, 2 Jan 2006 15:04, “Alice Example” wrote:\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			attribution := &AttributionBlock{}
+			parseAttribution(t, attribution, test.text)
+
+			if attribution.Name != "Alice Example" {
+				t.Errorf("FromText(%q) Name = %q, want %q", test.text, attribution.Name, "Alice Example")
+			}
+		})
+	}
+}
+
+func TestQuotingAttribution(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		wantOk    bool
+		wantName  string
+		wantEmail string
+	}{
+		{"name and address", "Quoting Alice Example <alice@example.com>:\n", true, "Alice Example", "alice@example.com"},
+		{"after other text", "Thanks!\n\nQuoting Alice Example <alice@example.com>:\n", true, "Alice Example", "alice@example.com"},
+		{"without an address", "Quoting Alice:\n", false, "", ""},
+		{"in a sentence", "I'm quoting Alice Example <alice@example.com>:\n", false, "", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			attribution := &AttributionBlock{}
+
+			ok, _, _ := attribution.FromText(test.text)
+			if ok != test.wantOk {
+				t.Fatalf("FromText(%q) ok = %v, want %v", test.text, ok, test.wantOk)
+			}
+
+			if attribution.Name != test.wantName || attribution.Email != test.wantEmail {
+				t.Errorf("FromText(%q) Name, Email = %q, %q, want %q, %q", test.text, attribution.Name, attribution.Email, test.wantName, test.wantEmail)
+			}
+		})
+	}
+}
+
+func TestMailtoAttributionEmail(t *testing.T) {
+	for _, text := range []string{
+		"On Mon, 2 Jan 2006 15:04, Alice <mailto:alice@example.com> wrote:\n",
+		"On Mon, 2 Jan 2006 15:04, Alice <MAILTO:alice@example.com> wrote:\n",
+		"On Mon, 2 Jan 2006 15:04, Alice <alice@example.com> wrote:\n",
+		"Alice <mailto:alice@example.com> wrote:\n",
+	} {
+		attribution := &AttributionBlock{}
+		parseAttribution(t, attribution, text)
+
+		if attribution.Name != "Alice" || attribution.Email != "alice@example.com" {
+			t.Errorf("FromText(%q) Name, Email = %q, %q, want %q, %q", text, attribution.Name, attribution.Email, "Alice", "alice@example.com")
+		}
+	}
+}
+
+func TestKeepParsedLocation(t *testing.T) {
+	const text = "On Mon, 2 Jan 2006 15:04:05 -0700, Alice wrote:\n"
+
+	raw, err := time.Parse("2 Jan 2006 15:04:05 -0700", "2 Jan 2006 15:04:05 -0700")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kept := &AttributionBlock{KeepParsedLocation: true}
+	parseAttribution(t, kept, text)
+
+	_, keptOffset := kept.Time.Zone()
+	_, rawOffset := raw.Zone()
+
+	if !kept.Time.Equal(raw) || keptOffset != rawOffset || kept.Time.Format(time.RFC3339) != raw.Format(time.RFC3339) {
+		t.Errorf("Time = %v, want %v as parsed", kept.Time, raw)
+	}
+
+	converted := &AttributionBlock{}
+	parseAttribution(t, converted, text)
+
+	if !converted.Time.Equal(raw) || converted.Time.Location() != time.UTC {
+		t.Errorf("Time = %v, want %v in UTC", converted.Time, raw.UTC())
+	}
+}
+
+func TestFrenchAttribution(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		wantName  string
+		wantEmail string
+	}{
+		{"space before the colon", "Le 2 janvier 2006 à 15:04, Alice Example <alice@example.com> a écrit :\n", "Alice Example", "alice@example.com"},
+		{"non-breaking space before the colon", "Le 2 janvier 2006 à 15:04, Alice Example a écrit\u00a0:\n", "Alice Example", ""},
+		{"narrow non-breaking space before the colon", "Le 2 janvier 2006 à 15:04, Alice Example a écrit\u202f:\n", "Alice Example", ""},
+		{"no space before the colon", "Le 2 janv. 2006 à 15:04, Alice Example a écrit:\n", "Alice Example", ""},
+	}
+
+	want := time.Date(2006, time.January, 2, 15, 4, 0, 0, time.UTC)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			attribution := &AttributionBlock{}
+			parseAttribution(t, attribution, test.text)
+
+			if attribution.Name != test.wantName || attribution.Email != test.wantEmail {
+				t.Errorf("FromText(%q) Name, Email = %q, %q, want %q, %q", test.text, attribution.Name, attribution.Email, test.wantName, test.wantEmail)
+			}
+
+			if !attribution.Time.Equal(want) || !attribution.HasTime {
+				t.Errorf("FromText(%q) Time, HasTime = %v, %v, want %v, true", test.text, attribution.Time, attribution.HasTime, want)
+			}
+
+			if attribution.Format != "LeDateATimeName" {
+				t.Errorf("FromText(%q) Format = %q", test.text, attribution.Format)
+			}
+		})
+	}
+}
+
+func TestLenientHandleAttribution(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		lenient  bool
+		wantOk   bool
+		wantName string
+	}{
+		{"lenient", "FROM JOHNDOE WROTE:\n", true, true, "JOHNDOE"},
+		{"lenient without from", "JOHNDOE WROTE:\n", true, true, "JOHNDOE"},
+		{"strict by default", "FROM JOHNDOE WROTE:\n", false, false, ""},
+		{"lenient lowercase", "from johndoe wrote:\n", true, false, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			attribution := &AttributionBlock{Lenient: test.lenient}
+
+			ok, _, _ := attribution.FromText(test.text)
+			if ok != test.wantOk {
+				t.Fatalf("FromText(%q) ok = %v, want %v", test.text, ok, test.wantOk)
+			}
+
+			if attribution.Name != test.wantName {
+				t.Errorf("FromText(%q) Name = %q, want %q", test.text, attribution.Name, test.wantName)
+			}
+		})
+	}
+}
+
+func TestAttributionRole(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		wantName  string
+		wantRole  string
+		wantEmail string
+	}{
+		{"with an address", "On Mon, 2 Jan 2006 15:04, Alice (Moderator) <alice@example.com> wrote:\n", "Alice", "Moderator", "alice@example.com"},
+		{"without an address", "On Mon, 2 Jan 2006, Alice Example (Group Owner) wrote:\n", "Alice Example", "Group Owner", ""},
+		{"in the middle of the name", "On Mon, 2 Jan 2006, Alice (Al) Example wrote:\n", "Alice (Al) Example", "", ""},
+		{"name after an address", "On Mon, 2 Jan 2006, alice@example.com (Alice) wrote:\n", "alice@example.com (Alice)", "", ""},
+		{"without a role", "On Mon, 2 Jan 2006, Alice <alice@example.com> wrote:\n", "Alice", "", "alice@example.com"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			attribution := &AttributionBlock{}
+			parseAttribution(t, attribution, test.text)
+
+			if attribution.Name != test.wantName || attribution.Role != test.wantRole || attribution.Email != test.wantEmail {
+				t.Errorf("FromText(%q) Name, Role, Email = %q, %q, %q, want %q, %q, %q", test.text, attribution.Name, attribution.Role, attribution.Email, test.wantName, test.wantRole, test.wantEmail)
+			}
+		})
+	}
+}
+
+func TestAttributionBeforeQuote(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		wantAfter string
+	}{
+		{"dated", "On Mon, Jan 2, 2006 at 3:04 PM, Alice <alice@example.com> wrote:\n> I agree.\n", "> I agree.\n"},
+		{"undated", "Alice <alice@example.com> wrote:\n> I agree.\n", "> I agree.\n"},
+		{"CRLF", "On Mon, Jan 2, 2006 at 3:04 PM, Alice wrote:\r\n> I agree.\r\n", "> I agree.\r\n"},
+		{"nested quote", "On Mon, Jan 2, 2006 at 3:04 PM, Alice wrote:\n>> I agree.\n", ">> I agree.\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			before, after := parseAttribution(t, &AttributionBlock{}, test.text)
+
+			if before != "" || after != test.wantAfter {
+				t.Errorf("FromText(%q) = %q, %q, want %q, %q", test.text, before, after, "", test.wantAfter)
+			}
+		})
+	}
+}
@@ -1,17 +1,216 @@
 package block
 
+import (
+	"io"
+	"time"
+)
+
 const nonNewlineWhitespaceRegexPart = `[\t ]*`
 
+type RenderOptions struct {
+	// IncludeFormat annotates blocks with the name of the format they were
+	// parsed from, which is useful for debugging the parser.
+	IncludeFormat bool
+
+	// Emoticons is the set of emoticons to mark up in body text. When it's
+	// empty, emoticons are left as literal text.
+	Emoticons []Emoticon
+
+	// ResolveGroupResource maps a link to a file or photo hosted on Yahoo
+	// Groups to the URL of an archived copy. It returns false if there is no
+	// archived copy, in which case the original link is used.
+	ResolveGroupResource func(url string) (resolved string, ok bool)
+
+	// ResolveContentID maps the content ID of an inline image, like
+	// "image001.png@01D2A3B4.C5D6E7F0", to the URL of an archived copy. It
+	// returns false if there is no archived copy, in which case a placeholder
+	// with the image's name is shown instead.
+	ResolveContentID func(contentID string) (url string, ok bool)
+
+	// ResolveMessageReference maps the number of a message in the group, from
+	// a mention like "see message 1234" in body text, to a link to it, like
+	// an anchor on the page. It returns false if the message isn't in the
+	// archive, in which case the mention is left as plain text.
+	ResolveMessageReference func(number int) (href string, ok bool)
+
+	// RelativeTimeNow, when set, is the current time used to show times in
+	// attributions relative to now, like "3 years ago". The absolute time is
+	// shown as a tooltip.
+	RelativeTimeNow time.Time
+
+	// PreserveWhitespace renders paragraphs as preformatted text, keeping
+	// their line breaks, indentation, and runs of spaces. This is useful for
+	// posts with source code or ASCII art.
+	PreserveWhitespace bool
+
+	// ScriptNotation renders notations like "x^2" and "H_2O" in body text as
+	// superscripts and subscripts. Text in backticks is left as-is.
+	ScriptNotation bool
+
+	// CompactAttributions renders attributions as a short line with the name
+	// and date, like "Alice • Jan 2, 2006", instead of "On ..., Alice said:".
+	// The date is written with the `ShortDateLayout` of `DateLocale`.
+	CompactAttributions bool
+
+	// DateLocale, when set, is how to show the dates in attributions. This
+	// defaults to `DateLocaleEnglish`.
+	DateLocale *DateLocale
+
+	// BlockIDPrefix, when set, gives each paragraph, quote, and block an `id`
+	// like "message-1-block-3", numbered in the order they're rendered.
+	BlockIDPrefix string
+
+	// CanonicalHeaderOrder shows the fields of quoted message headers in a
+	// consistent order instead of the order they were written in. See
+	// `MessageHeaderBlock.CanonicalFields`.
+	CanonicalHeaderOrder bool
+
+	// ReplyTree renders each attribution and the quote after it as a nested
+	// container, so a reply to a reply is shown inside the reply it quotes.
+	ReplyTree bool
+
+	// ReplyQuoteClasses renders each quote introduced by an attribution with
+	// the classes "quote-reply" and "quote-reply-depth-N", where N is the
+	// number of replies it's nested in, so themes can indent quoted replies
+	// relative to the new content. This is also done when `QuoteAuthorColor`
+	// is set, since the color is set on the same quotes.
+	ReplyQuoteClasses bool
+
+	// QuoteAuthorColor, when set, returns the CSS color to tint each quote
+	// introduced by an attribution with, so readers can tell who wrote each
+	// quote. It should return the same color for the same author.
+	QuoteAuthorColor func(attribution *AttributionBlock) string
+}
+
 type Block interface {
 	ToHtml() string
+	WriteHtml(w io.Writer, options RenderOptions) error
 	FromText(text string) (ok bool, before, after string)
 }
 
+// ParagraphContinuer is implemented by blocks which can span more than one
+// paragraph. ContinuesParagraph returns whether `text` starts such a block
+// without ending it, in which case the next paragraph is appended to `text`
+// before looking for blocks. If the block is never ended, each paragraph is
+// searched for blocks on its own instead.
+type ParagraphContinuer interface {
+	ContinuesParagraph(text string) bool
+}
+
+// BlockParser is implemented by blocks which parse text into a block of
+// another type, like `MessageHeaderParser`, for blocks which can't hold their
+// own parse options. After `FromText` matches, the tokenizer keeps the block
+// from ParsedBlock instead.
+type BlockParser interface {
+	ParsedBlock() Block
+}
+
+// BodyPositioner is implemented by blocks which can be limited to matching
+// near the end of the body, like footers. UsesBodyPosition returns whether
+// the block is limited that way, and if so, SetLinesAfter is called before
+// `FromText` with the number of lines in the body after `text`.
+type BodyPositioner interface {
+	UsesBodyPosition() bool
+	SetLinesAfter(count int)
+}
+
+type ParseOptions struct {
+	// Reference is used to resolve relative dates, and is typically the time
+	// the message was sent.
+	Reference time.Time
+
+	// AllowEmptyHeaderFields includes header fields with no value.
+	AllowEmptyHeaderFields bool
+
+	// DayFirstDates reads slashed numeric dates in attributions as DD/MM
+	// instead of MM/DD.
+	DayFirstDates bool
+
+	// DottedMonthFirstDates reads numeric dates with periods in attributions
+	// as MM.DD instead of DD.MM.
+	DottedMonthFirstDates bool
+
+	// RawHtmlSentinel, when set, is a line which starts and ends a section of
+	// HTML that's passed through verbatim. This is off by default because the
+	// HTML isn't sanitized.
+	RawHtmlSentinel string
+
+	// KeepParsedTimes stores times in attributions in the location they were
+	// parsed with instead of converting them to UTC.
+	KeepParsedTimes bool
+
+	// AsciiTables renders tables drawn with "|" and "+---+" borders as HTML
+	// tables.
+	AsciiTables bool
+
+	// ScissorMarkers are the markers which make a line of dashes a scissor
+	// line, like "8<". This defaults to `DefaultScissorMarkers`.
+	ScissorMarkers []string
+
+	// TruncationMarkers are the markers which make a line like "-----
+	// Message truncated -----" a truncation notice. This defaults to
+	// `DefaultTruncationMarkers`.
+	TruncationMarkers []string
+
+	// LenientAttributions also recognizes attribution formats which are prone
+	// to false positives, like "FROM JOHNDOE WROTE:".
+	LenientAttributions bool
+
+	// CanonicalizeEmail, when set, is applied to addresses in attributions
+	// and quoted headers, like `CanonicalizeEmail` or `FoldEmail`.
+	CanonicalizeEmail func(address string) string
+
+	// FooterWindow, when set, only strips a disclaimer which starts within
+	// this many lines of the end of the body, so one which is quoted in the
+	// middle of a reply is kept as text.
+	FooterWindow int
+
+	// Hooks are called on parse events, like for collecting metrics.
+	Hooks *ParseHooks
+}
+
 func AllBlocks() []Block {
-	return []Block{
-		&HardBreakBlock{},
-		&DividerBlock{},
-		&MessageHeaderBlock{},
-		&AttributionBlock{},
+	return AllBlocksWithOptions(ParseOptions{})
+}
+
+func AllBlocksWithOptions(options ParseOptions) []Block {
+	var blocks []Block
+
+	// Raw sections are matched first so that nothing inside them is parsed as
+	// another block.
+	if options.RawHtmlSentinel != "" {
+		blocks = append(blocks, &RawHtmlBlock{Sentinel: options.RawHtmlSentinel})
 	}
+
+	// PGP armor is matched next for the same reason, and so its dashed lines
+	// aren't mistaken for dividers.
+	blocks = append(blocks, &PgpBlock{}, &HardBreakBlock{})
+
+	// Tables are matched before dividers so their borders aren't split off.
+	if options.AsciiTables {
+		blocks = append(blocks, &AsciiTableBlock{})
+	}
+
+	return append(blocks,
+		&ScissorBlock{Markers: options.ScissorMarkers},
+		&TruncationBlock{Markers: options.TruncationMarkers},
+		&DividerBlock{},
+		&MessageHeaderParser{
+			AllowEmptyFields:  options.AllowEmptyHeaderFields,
+			CanonicalizeEmail: options.CanonicalizeEmail,
+			Hooks:             options.Hooks,
+		},
+		&AttributionBlock{
+			Reference:          options.Reference,
+			DayFirst:           options.DayFirstDates,
+			DottedMonthFirst:   options.DottedMonthFirstDates,
+			KeepParsedLocation: options.KeepParsedTimes,
+			Lenient:            options.LenientAttributions,
+			CanonicalizeEmail:  options.CanonicalizeEmail,
+			Hooks:              options.Hooks,
+		},
+		&DisclaimerBlock{Window: options.FooterWindow, Hooks: options.Hooks},
+		&GroupResourceBlock{},
+		&ImageReferenceBlock{},
+	)
 }
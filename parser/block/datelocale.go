@@ -0,0 +1,83 @@
+package block
+
+import (
+	"strings"
+	"time"
+)
+
+// DateLocale is how to show the dates in attributions to readers in a locale,
+// regardless of the format they were written in. The layouts use the
+// reference time from the `time` package, and the month written as "Jan" in
+// them is replaced with the locale's abbreviation for it.
+type DateLocale struct {
+	// ShortMonths are the abbreviations of the months, starting with
+	// January.
+	ShortMonths [12]string
+
+	// DateTimeLayout is for attributions with a time, DateLayout is for ones
+	// with only a date, and ShortDateLayout is for compact attributions.
+	DateTimeLayout  string
+	DateLayout      string
+	ShortDateLayout string
+}
+
+var (
+	DateLocaleEnglish = DateLocale{
+		ShortMonths:     [12]string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+		DateTimeLayout:  "2 Jan 2006, 15:04 -07:00",
+		DateLayout:      "2 Jan 2006",
+		ShortDateLayout: "Jan 2, 2006",
+	}
+
+	DateLocaleGerman = DateLocale{
+		ShortMonths:     [12]string{"Jan.", "Feb.", "März", "Apr.", "Mai", "Juni", "Juli", "Aug.", "Sept.", "Okt.", "Nov.", "Dez."},
+		DateTimeLayout:  "2. Jan 2006, 15:04 -07:00",
+		DateLayout:      "2. Jan 2006",
+		ShortDateLayout: "2. Jan 2006",
+	}
+
+	DateLocaleFrench = DateLocale{
+		ShortMonths:     [12]string{"janv.", "févr.", "mars", "avr.", "mai", "juin", "juil.", "août", "sept.", "oct.", "nov.", "déc."},
+		DateTimeLayout:  "2 Jan 2006 à 15:04 -07:00",
+		DateLayout:      "2 Jan 2006",
+		ShortDateLayout: "2 Jan 2006",
+	}
+)
+
+// DateLocales are the built-in date locales by language code.
+var DateLocales = map[string]DateLocale{
+	"en": DateLocaleEnglish,
+	"de": DateLocaleGerman,
+	"fr": DateLocaleFrench,
+}
+
+func (l DateLocale) format(t time.Time, layout string) string {
+	return strings.Replace(t.Format(layout), t.Format("Jan"), l.ShortMonths[t.Month()-1], 1)
+}
+
+// FormatDateTime formats `t` with its time and offset, like "2 Jan 2006,
+// 15:04 -07:00".
+func (l DateLocale) FormatDateTime(t time.Time) string {
+	return l.format(t, l.DateTimeLayout)
+}
+
+// FormatDateTimeWithZoneName formats `t` with its time and `zoneName` in place
+// of the offset, like "2 Jan 2006, 15:04 Samoa Standard Time". This is for
+// times whose offset isn't known.
+func (l DateLocale) FormatDateTimeWithZoneName(t time.Time, zoneName string) string {
+	layout := strings.Replace(l.DateTimeLayout, "-07:00", "MST", 1)
+
+	// The clock time is kept as written, so the zone has no offset.
+	return l.format(t.In(time.FixedZone(zoneName, 0)), layout)
+}
+
+// FormatDate formats just the date of `t`, like "2 Jan 2006".
+func (l DateLocale) FormatDate(t time.Time) string {
+	return l.format(t, l.DateLayout)
+}
+
+// FormatShortDate formats the date of `t` for compact attributions, like "Jan
+// 2, 2006".
+func (l DateLocale) FormatShortDate(t time.Time) string {
+	return l.format(t, l.ShortDateLayout)
+}
@@ -0,0 +1,55 @@
+package block
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDateLocales(t *testing.T) {
+	date := time.Date(2006, time.March, 2, 15, 4, 5, 0, time.FixedZone("", -7*60*60))
+
+	tests := []struct {
+		lang          string
+		wantDateTime  string
+		wantDate      string
+		wantShortDate string
+	}{
+		{"en", "2 Mar 2006, 15:04 -07:00", "2 Mar 2006", "Mar 2, 2006"},
+		{"de", "2. März 2006, 15:04 -07:00", "2. März 2006", "2. März 2006"},
+		{"fr", "2 mars 2006 à 15:04 -07:00", "2 mars 2006", "2 mars 2006"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.lang, func(t *testing.T) {
+			locale := DateLocales[test.lang]
+
+			if got := locale.FormatDateTime(date); got != test.wantDateTime {
+				t.Errorf("FormatDateTime() = %q, want %q", got, test.wantDateTime)
+			}
+
+			if got := locale.FormatDate(date); got != test.wantDate {
+				t.Errorf("FormatDate() = %q, want %q", got, test.wantDate)
+			}
+
+			if got := locale.FormatShortDate(date); got != test.wantShortDate {
+				t.Errorf("FormatShortDate() = %q, want %q", got, test.wantShortDate)
+			}
+		})
+	}
+}
+
+func TestAttributionDateLocale(t *testing.T) {
+	// The attribution is written in English, but shown in German.
+	attribution := &AttributionBlock{}
+	parseAttribution(t, attribution, "On Thu, 2 Mar 2006 15:04:05 -0700, Alice wrote:\n")
+
+	var output strings.Builder
+	if err := attribution.WriteHtml(&output, RenderOptions{DateLocale: &DateLocaleGerman}); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := ">2. März 2006, 22:04 &#43;00:00</time>"; !strings.Contains(output.String(), want) {
+		t.Errorf("rendered HTML doesn't contain %s:\n%s", want, output.String())
+	}
+}
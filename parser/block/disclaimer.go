@@ -0,0 +1,87 @@
+package block
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// These are phrases which commonly start a legal disclaimer. They're matched
+// case-insensitively at the start of a paragraph, so a sentence which happens
+// to start a line in the middle of one isn't mistaken for a disclaimer.
+var disclaimerPhrases = []string{
+	"This email and any attachments",
+	"This e-mail and any attachments",
+	"This message and any attachments",
+	"This email is confidential",
+	"This e-mail is confidential",
+	"This message is confidential",
+	"This communication is intended",
+	"This message is intended only",
+	"The information contained in this",
+	"The information in this email",
+	"The information in this e-mail",
+	"If you have received this email in error",
+	"If you have received this e-mail in error",
+	"If you have received this message in error",
+	"Confidentiality notice",
+	"Disclaimer:",
+}
+
+var disclaimerRegex = regexp.MustCompile(fmt.Sprintf(`(?i)\A\s*(?:%s)`, joinDisclaimerPhrases(disclaimerPhrases)))
+
+func joinDisclaimerPhrases(phrases []string) string {
+	regexParts := make([]string, len(phrases))
+
+	for i, phrase := range phrases {
+		regexParts[i] = regexp.QuoteMeta(phrase)
+	}
+
+	return strings.Join(regexParts, "|")
+}
+
+type DisclaimerBlock struct {
+	Text string
+
+	// Window, when set, is the most lines from the end of the body that a
+	// disclaimer can start at. See `ParseOptions.FooterWindow`.
+	Window int
+
+	// Hooks are called when a disclaimer is split off from the message.
+	Hooks *ParseHooks
+
+	linesAfter int
+}
+
+func (b *DisclaimerBlock) UsesBodyPosition() bool {
+	return b.Window > 0
+}
+
+func (b *DisclaimerBlock) SetLinesAfter(count int) {
+	b.linesAfter = count
+}
+
+// isInWindow returns whether a disclaimer which is the paragraph `text`
+// starts close enough to the end of the body.
+func (b *DisclaimerBlock) isInWindow(text string) bool {
+	if b.Window <= 0 {
+		return true
+	}
+
+	lineCount := strings.Count(strings.TrimRight(text, "\n"), "\n") + 1
+
+	return b.linesAfter+lineCount <= b.Window
+}
+
+// FromText matches a paragraph which starts with a disclaimer phrase. A
+// disclaimer always runs through the end of the paragraph.
+func (b *DisclaimerBlock) FromText(text string) (ok bool, before, after string) {
+	if !disclaimerRegex.MatchString(text) || !b.isInWindow(strings.TrimLeft(text, "\n")) {
+		return false, "", ""
+	}
+
+	b.Text = strings.TrimSpace(text)
+	b.Hooks.footerStripped(KindDisclaimer)
+
+	return true, "", ""
+}
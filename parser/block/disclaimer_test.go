@@ -0,0 +1,51 @@
+package block
+
+import (
+	"strings"
+	"testing"
+)
+
+const confidentialityNotice = `CONFIDENTIALITY NOTICE: This email and any attachments are confidential
+and intended solely for the use of the individual to whom they are
+addressed. If you have received this email in error, please notify the
+sender and delete it.
+`
+
+func TestDisclaimerFromText(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		wantOk bool
+	}{
+		{"confidentiality notice", confidentialityNotice, true},
+		{"lowercase", "this email and any attachments are confidential.\n", true},
+		{"in the middle of a paragraph", "Please read the notes.\nThis email and any attachments are for the team.\n", false},
+		{"prose", "I think this message is important.\n", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			disclaimer := &DisclaimerBlock{}
+
+			ok, before, after := disclaimer.FromText(test.text)
+			if ok != test.wantOk {
+				t.Fatalf("FromText(%q) ok = %v, want %v", test.text, ok, test.wantOk)
+			}
+
+			if ok && (before != "" || after != "" || disclaimer.Text != strings.TrimSpace(test.text)) {
+				t.Errorf("FromText(%q) = %q, %q, Text = %q", test.text, before, after, disclaimer.Text)
+			}
+		})
+	}
+}
+
+func TestDisclaimerToHtml(t *testing.T) {
+	disclaimer := &DisclaimerBlock{}
+	if ok, _, _ := disclaimer.FromText(confidentialityNotice); !ok {
+		t.Fatalf("FromText(%q) didn't match", confidentialityNotice)
+	}
+
+	if rendered := disclaimer.ToHtml(); !strings.HasPrefix(rendered, "<details") || strings.Contains(rendered, " open") {
+		t.Errorf("disclaimer isn't collapsed:\n%s", rendered)
+	}
+}
@@ -5,7 +5,7 @@ import (
 	"regexp"
 )
 
-var dividerRegex = regexp.MustCompile(fmt.Sprintf(`(?m)^%[1]s(?:-{2,}|_{2,}|#{2,})%[1]s$`, nonNewlineWhitespaceRegexPart))
+var dividerRegex = regexp.MustCompile(fmt.Sprintf(`(?m)^%[1]s(?:-{2,}|_{2,}|#{2,}|={2,}|\*{2,})%[1]s$\n?`, nonNewlineWhitespaceRegexPart))
 
 type DividerBlock struct{}
 
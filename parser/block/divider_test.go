@@ -0,0 +1,34 @@
+package block
+
+import "testing"
+
+func TestDividerFromText(t *testing.T) {
+	tests := []struct {
+		name       string
+		text       string
+		wantOk     bool
+		wantBefore string
+		wantAfter  string
+	}{
+		{"equals", "Above.\n====\nBelow.\n", true, "Above.\n", "Below.\n"},
+		{"asterisks", "Above.\n  ****  \nBelow.\n", true, "Above.\n", "Below.\n"},
+		{"hyphens", "Above.\n--\nBelow.\n", true, "Above.\n", "Below.\n"},
+		{"underscores at the end", "Above.\n____", true, "Above.\n", ""},
+		{"one character", "Above.\n=\nBelow.\n", false, "", ""},
+		{"mixed characters", "Above.\n=*=*\nBelow.\n", false, "", ""},
+		{"in a sentence", "This is **important**.\n", false, "", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ok, before, after := (&DividerBlock{}).FromText(test.text)
+			if ok != test.wantOk {
+				t.Fatalf("FromText(%q) ok = %v, want %v", test.text, ok, test.wantOk)
+			}
+
+			if before != test.wantBefore || after != test.wantAfter {
+				t.Errorf("FromText(%q) = %q, %q, want %q, %q", test.text, before, after, test.wantBefore, test.wantAfter)
+			}
+		})
+	}
+}
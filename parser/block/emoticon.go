@@ -0,0 +1,23 @@
+package block
+
+type Emoticon struct {
+	Text  string
+	Label string
+}
+
+var DefaultEmoticons = []Emoticon{
+	{Text: ":-)", Label: "smile"},
+	{Text: ":)", Label: "smile"},
+	{Text: ":-D", Label: "grin"},
+	{Text: ":D", Label: "grin"},
+	{Text: ";-)", Label: "wink"},
+	{Text: ";)", Label: "wink"},
+	{Text: ":-(", Label: "frown"},
+	{Text: ":(", Label: "frown"},
+	{Text: ":'(", Label: "crying"},
+	{Text: ":-P", Label: "tongue out"},
+	{Text: ":P", Label: "tongue out"},
+	{Text: ":-O", Label: "surprise"},
+	{Text: ":-/", Label: "skeptical"},
+	{Text: "<3", Label: "heart"},
+}
@@ -0,0 +1,145 @@
+package block
+
+import "reflect"
+
+// Equal reports whether two attributions parsed to the same content. Times
+// are compared as instants, so the same time in different locations is
+// equal. Parse options like `Reference` and `DayFirst` aren't compared. A nil
+// attribution is only equal to another nil one.
+func (b *AttributionBlock) Equal(other *AttributionBlock) bool {
+	if b == nil || other == nil {
+		return b == other
+	}
+
+	return b.Name == other.Name &&
+		b.Email == other.Email &&
+		b.Role == other.Role &&
+		b.Time.Equal(other.Time) &&
+		b.HasTime == other.HasTime &&
+		b.Format == other.Format &&
+		b.Lang == other.Lang &&
+		b.TimezoneName == other.TimezoneName &&
+		b.UnknownOffset == other.UnknownOffset &&
+		b.RawTime == other.RawTime &&
+		b.QuoteDepth == other.QuoteDepth
+}
+
+// Equal reports whether two headers have the same fields in the same order.
+// A nil header is only equal to another nil one.
+func (b *MessageHeaderBlock) Equal(other *MessageHeaderBlock) bool {
+	if b == nil || other == nil {
+		return b == other
+	}
+
+	if len(*b) != len(*other) {
+		return false
+	}
+
+	for i := range *b {
+		if (*b)[i] != (*other)[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Equal reports whether two dividers are equal, which they always are unless
+// only one of them is nil.
+func (b *DividerBlock) Equal(other *DividerBlock) bool {
+	return (b == nil) == (other == nil)
+}
+
+func tableCellsEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Equal reports whether two tables have the same header and cells. A nil
+// table is only equal to another nil one.
+func (b *AsciiTableBlock) Equal(other *AsciiTableBlock) bool {
+	if b == nil || other == nil {
+		return b == other
+	}
+
+	if !tableCellsEqual(b.Header, other.Header) || len(b.Rows) != len(other.Rows) {
+		return false
+	}
+
+	for i := range b.Rows {
+		if !tableCellsEqual(b.Rows[i], other.Rows[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isNilBlock returns whether `b` is nil or a nil pointer to a block.
+func isNilBlock(b Block) bool {
+	if b == nil {
+		return true
+	}
+
+	value := reflect.ValueOf(b)
+
+	return value.Kind() == reflect.Ptr && value.IsNil()
+}
+
+// BlocksEqual reports whether two blocks are the same kind of block with the
+// same content. Nil blocks are only equal to nil blocks of the same type.
+func BlocksEqual(a, b Block) bool {
+	if aIsNil, bIsNil := isNilBlock(a), isNilBlock(b); aIsNil || bIsNil {
+		return aIsNil && bIsNil && reflect.TypeOf(a) == reflect.TypeOf(b)
+	}
+
+	switch concreteA := a.(type) {
+	case *AttributionBlock:
+		concreteB, ok := b.(*AttributionBlock)
+		return ok && concreteA.Equal(concreteB)
+	case *MessageHeaderBlock:
+		concreteB, ok := b.(*MessageHeaderBlock)
+		return ok && concreteA.Equal(concreteB)
+	case *DividerBlock:
+		concreteB, ok := b.(*DividerBlock)
+		return ok && concreteA.Equal(concreteB)
+	case *HardBreakBlock:
+		_, ok := b.(*HardBreakBlock)
+		return ok
+	case *ScissorBlock:
+		_, ok := b.(*ScissorBlock)
+		return ok
+	case *TruncationBlock:
+		_, ok := b.(*TruncationBlock)
+		return ok
+	case *DisclaimerBlock:
+		concreteB, ok := b.(*DisclaimerBlock)
+		return ok && concreteA.Text == concreteB.Text
+	case *GroupResourceBlock:
+		concreteB, ok := b.(*GroupResourceBlock)
+		return ok && *concreteA == *concreteB
+	case *ImageReferenceBlock:
+		concreteB, ok := b.(*ImageReferenceBlock)
+		return ok && *concreteA == *concreteB
+	case *PgpBlock:
+		concreteB, ok := b.(*PgpBlock)
+		return ok && *concreteA == *concreteB
+	case *RawHtmlBlock:
+		concreteB, ok := b.(*RawHtmlBlock)
+		return ok && concreteA.Html == concreteB.Html
+	case *AsciiTableBlock:
+		concreteB, ok := b.(*AsciiTableBlock)
+		return ok && concreteA.Equal(concreteB)
+	default:
+		return false
+	}
+}
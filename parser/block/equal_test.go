@@ -0,0 +1,103 @@
+package block
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAttributionEqual(t *testing.T) {
+	instant := time.Date(2006, time.January, 2, 15, 4, 0, 0, time.UTC)
+	sameInstant := instant.In(time.FixedZone("", -7*60*60))
+
+	alice := &AttributionBlock{Name: "Alice", Time: instant, HasTime: true, Format: "OnDateTime", Lang: "en"}
+
+	tests := []struct {
+		name  string
+		a, b  *AttributionBlock
+		equal bool
+	}{
+		{
+			name:  "same instant in another location",
+			a:     alice,
+			b:     &AttributionBlock{Name: "Alice", Time: sameInstant, HasTime: true, Format: "OnDateTime", Lang: "en"},
+			equal: true,
+		},
+		{
+			name:  "another instant",
+			a:     alice,
+			b:     &AttributionBlock{Name: "Alice", Time: instant.Add(time.Hour), HasTime: true, Format: "OnDateTime", Lang: "en"},
+			equal: false,
+		},
+		{
+			name:  "another language",
+			a:     alice,
+			b:     &AttributionBlock{Name: "Alice", Time: instant, HasTime: true, Format: "OnDateTime", Lang: "de"},
+			equal: false,
+		},
+		{
+			name:  "only one nil",
+			a:     alice,
+			b:     nil,
+			equal: false,
+		},
+		{
+			name:  "both nil",
+			a:     nil,
+			b:     nil,
+			equal: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.a.Equal(test.b); got != test.equal {
+				t.Errorf("a.Equal(b) = %v, want %v", got, test.equal)
+			}
+
+			if got := test.b.Equal(test.a); got != test.equal {
+				t.Errorf("b.Equal(a) = %v, want %v", got, test.equal)
+			}
+		})
+	}
+}
+
+func TestParsedAttributionsEqual(t *testing.T) {
+	text := "On Mon, 2 Jan 2006 15:04:05 -0700, Alice <alice@example.com> wrote:\n"
+
+	a, b := &AttributionBlock{}, &AttributionBlock{KeepParsedLocation: true}
+	a.FromText(text)
+	b.FromText(text)
+
+	if a.Time.Location() == b.Time.Location() {
+		t.Fatalf("times are both in %v", a.Time.Location())
+	}
+
+	if !a.Equal(b) {
+		t.Errorf("%+v isn't equal to %+v", a, b)
+	}
+}
+
+func TestBlocksEqual(t *testing.T) {
+	header := MessageHeaderBlock{{Name: "From", Value: "Alice"}}
+	sameHeader := MessageHeaderBlock{{Name: "From", Value: "Alice"}}
+
+	tests := []struct {
+		name  string
+		a, b  Block
+		equal bool
+	}{
+		{"same headers", &header, &sameHeader, true},
+		{"header and divider", &header, &DividerBlock{}, false},
+		{"nil blocks of the same type", (*AttributionBlock)(nil), (*AttributionBlock)(nil), true},
+		{"nil blocks of different types", (*AttributionBlock)(nil), (*DividerBlock)(nil), false},
+		{"nil and not nil", (*DividerBlock)(nil), &DividerBlock{}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := BlocksEqual(test.a, test.b); got != test.equal {
+				t.Errorf("BlocksEqual = %v, want %v", got, test.equal)
+			}
+		})
+	}
+}
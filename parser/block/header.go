@@ -3,32 +3,210 @@ package block
 import (
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 )
 
-const fieldNameRegexPart = `From|Reply-To|To|Subject|Date|Sent|Message`
+const (
+	fieldNameRegexPart = `From|Reply-To|To|Cc|Subject|Date|Sent|Message-ID|Message`
+
+	fieldNameMessageID = "Message-ID"
+	fieldNameMessage   = "Message"
+)
 
 var (
-	messageHeaderBannerRegexPart = fmt.Sprintf(`%[1]s-+ ?Original Message ?-+%[1]s`, nonNewlineWhitespaceRegexPart)
-	fieldLabelRegex              = regexp.MustCompile(fmt.Sprintf(`(?m)^%s(%s): +(\S)`, nonNewlineWhitespaceRegexPart, fieldNameRegexPart))
-	messageHeaderStartRegex      = regexp.MustCompile(fmt.Sprintf(`(?:^%[2]s\n|^%[1]s\n?|\n%[1]s(?:%[2]s)?\n)%[1]s(%[3]s): +(\S)`, nonNewlineWhitespaceRegexPart, messageHeaderBannerRegexPart, fieldNameRegexPart))
-	messageHeaderEndRegex        = regexp.MustCompile(fmt.Sprintf(`(?m)^%s\n`, nonNewlineWhitespaceRegexPart))
+	// Some clients separate the field name from the value with a tab instead
+	// of a space, which is common in forwards with tab-indented fields.
+	fieldSeparatorRegexPart = `:[\t ]+`
+
+	// The banner is "-----Original Message-----" in replies, and in forwards
+	// it's like "---------- Forwarded message ---------".
+	messageHeaderBannerRegexPart = fmt.Sprintf(`%[1]s-+%[1]s(?:Original Message|(?i:Forwarded message))%[1]s-+%[1]s`, nonNewlineWhitespaceRegexPart)
+	// Field labels are only recognized at the start of a line, so colons
+	// inside a value (e.g. "Subject: Re: meeting: agenda") never start a new
+	// field. A field may have no value, like "Cc:".
+	fieldLabelRegex          = regexp.MustCompile(fmt.Sprintf(`(?m)^%[1]s(%[2]s)(?:%[3]s(\S)|:%[1]s$)`, nonNewlineWhitespaceRegexPart, fieldNameRegexPart, fieldSeparatorRegexPart))
+	messageHeaderStartRegex  = regexp.MustCompile(fmt.Sprintf(`(?:^%[2]s\n(?:%[1]s\n)*|^%[1]s\n?|\n%[1]s(?:%[2]s\n(?:%[1]s\n)*|\n))%[1]s(%[3]s)%[4]s(\S)`, nonNewlineWhitespaceRegexPart, messageHeaderBannerRegexPart, fieldNameRegexPart, fieldSeparatorRegexPart))
+	messageHeaderBannerRegex = regexp.MustCompile(fmt.Sprintf(`^%s\n?$`, messageHeaderBannerRegexPart))
+	leadingFieldLabelRegex   = regexp.MustCompile(fmt.Sprintf(`^%s(?:%s)%s\S`, nonNewlineWhitespaceRegexPart, fieldNameRegexPart, fieldSeparatorRegexPart))
+	messageIDRegex           = regexp.MustCompile(`<[^<>@\s]+@[^<>@\s]+>`)
+	wholeMessageIDRegex      = regexp.MustCompile(`^\s*<[^<>@\s]+@[^<>@\s]+>\s*$`)
+	messageHeaderEndRegex    = regexp.MustCompile(fmt.Sprintf(`(?m)^%s\n`, nonNewlineWhitespaceRegexPart))
+	innerBannerRegex         = regexp.MustCompile(fmt.Sprintf(`(?m)^%s$`, messageHeaderBannerRegexPart))
 )
 
+// IsMessageHeaderBanner returns whether `text` consists of only an "Original
+// Message" banner, which may be separated from the header fields that follow
+// it by blank lines.
+func IsMessageHeaderBanner(text string) bool {
+	return messageHeaderBannerRegex.MatchString(text)
+}
+
+// StartsWithField returns whether `text` starts with a header field, like
+// "From: Alice".
+func StartsWithField(text string) bool {
+	return leadingFieldLabelRegex.MatchString(text)
+}
+
 type Field struct {
 	Name  string
 	Value string
 }
 
+// MessageFieldInterpretation is how to read a "Message" field, which may be a
+// "Message-ID" field with a truncated label or a field of its own, like a
+// Yahoo Groups message number.
+type MessageFieldInterpretation string
+
+const (
+	// MessageFieldAuto reads the field as a message ID when its whole value
+	// looks like "<...@...>", and as a generic field otherwise.
+	MessageFieldAuto MessageFieldInterpretation = ""
+
+	MessageFieldMessageID MessageFieldInterpretation = "message-id"
+	MessageFieldGeneric   MessageFieldInterpretation = "generic"
+)
+
 type MessageHeaderBlock []Field
 
+// MessageHeaderParser parses a `MessageHeaderBlock` with parse options, which
+// the header, being only its fields, can't hold itself. The tokenizer keeps
+// the parsed `Header` as the block.
+type MessageHeaderParser struct {
+	Header MessageHeaderBlock
+
+	// AllowEmptyFields includes fields with no value, like "Cc:", instead of
+	// skipping them. The first field of a header must still have a value.
+	AllowEmptyFields bool
+
+	// CanonicalizeEmail, when set, is applied to the addresses in address
+	// fields, like `CanonicalizeEmail` or `FoldEmail`.
+	CanonicalizeEmail func(address string) string
+
+	// Hooks are called when a header is parsed.
+	Hooks *ParseHooks
+}
+
+func (b MessageHeaderBlock) Field(name string) (field Field, ok bool) {
+	for _, field := range b {
+		if strings.EqualFold(field.Name, name) {
+			return field, true
+		}
+	}
+
+	return Field{}, false
+}
+
+// MessageFieldInterpretation returns how the "Message" field is read given
+// `messageField`, which is never `MessageFieldAuto`. It returns false if there
+// is no such field.
+func (b MessageHeaderBlock) MessageFieldInterpretation(messageField MessageFieldInterpretation) (interpretation MessageFieldInterpretation, ok bool) {
+	field, ok := b.Field(fieldNameMessage)
+	if !ok {
+		return "", false
+	}
+
+	switch messageField {
+	case MessageFieldMessageID, MessageFieldGeneric:
+		return messageField, true
+	}
+
+	if wholeMessageIDRegex.MatchString(field.Value) {
+		return MessageFieldMessageID, true
+	}
+
+	return MessageFieldGeneric, true
+}
+
+// MessageID returns the `<...@...>` message ID in the "Message-ID" field, or
+// in the "Message" field when it's read as one given `messageField`, if there
+// is one. The "Message" field often holds a Yahoo Groups message number
+// instead, which is ignored.
+func (b MessageHeaderBlock) MessageID(messageField MessageFieldInterpretation) (id string, ok bool) {
+	if field, hasField := b.Field(fieldNameMessageID); hasField {
+		if id := messageIDRegex.FindString(field.Value); id != "" {
+			return id, true
+		}
+	}
+
+	if interpretation, hasField := b.MessageFieldInterpretation(messageField); hasField && interpretation == MessageFieldMessageID {
+		field, _ := b.Field(fieldNameMessage)
+
+		if id := messageIDRegex.FindString(field.Value); id != "" {
+			return id, true
+		}
+	}
+
+	return "", false
+}
+
+// canonicalFieldOrder is the order of fields after `CanonicalFields`, which
+// roughly follows the order clients show them in. "Sent" and "Date" are
+// different names for the same field.
+var canonicalFieldOrder = []string{fieldNameFrom, fieldNameSent, fieldNameDate, fieldNameTo, "Cc", fieldNameSubject}
+
+func canonicalFieldIndex(name string) int {
+	for i, canonicalName := range canonicalFieldOrder {
+		if strings.EqualFold(name, canonicalName) {
+			return i
+		}
+	}
+
+	return len(canonicalFieldOrder)
+}
+
+// CanonicalFields returns the fields sorted into a consistent order: "From",
+// "Sent" or "Date", "To", "Cc", and "Subject", followed by any other fields
+// in the order they appear.
+func (b MessageHeaderBlock) CanonicalFields() []Field {
+	fields := make([]Field, len(b))
+	copy(fields, b)
+
+	sort.SliceStable(fields, func(i, j int) bool {
+		return canonicalFieldIndex(fields[i].Name) < canonicalFieldIndex(fields[j].Name)
+	})
+
+	return fields
+}
+
 type messageHeaderFieldPosition struct {
 	LabelStartIndex int
 	LabelEndIndex   int
 	ValueStartIndex int
+	IsEmpty         bool
 }
 
 func (b *MessageHeaderBlock) FromText(text string) (ok bool, before, after string) {
+	return b.parse(text, false)
+}
+
+func (p *MessageHeaderParser) FromText(text string) (ok bool, before, after string) {
+	p.Header = nil
+
+	ok, before, after = p.Header.parse(text, p.AllowEmptyFields)
+	if !ok {
+		return false, "", ""
+	}
+
+	if p.CanonicalizeEmail != nil {
+		for i, field := range p.Header {
+			if isAddressFieldName(field.Name) {
+				p.Header[i].Value = canonicalizeEmailsInValue(field.Value, p.CanonicalizeEmail)
+			}
+		}
+	}
+
+	p.Hooks.headerParsed(len(p.Header))
+
+	return true, before, after
+}
+
+// ParsedBlock returns the header which was parsed.
+func (p *MessageHeaderParser) ParsedBlock() Block {
+	return &p.Header
+}
+
+func (b *MessageHeaderBlock) parse(text string, allowEmptyFields bool) (ok bool, before, after string) {
 	var fieldPositions []messageHeaderFieldPosition
 
 	remaining := text
@@ -61,6 +239,16 @@ func (b *MessageHeaderBlock) FromText(text string) (ok bool, before, after strin
 		after = text[absoluteEndIndex:]
 	}
 
+	// Some clients stack the header of each message in the thread without a
+	// blank line between them, so another banner starts the next header.
+	if match := innerBannerRegex.FindStringIndex(remaining); match != nil {
+		absoluteFieldListEndIndex = currentIndex + match[0]
+		after = text[absoluteFieldListEndIndex:]
+		remaining = remaining[:match[0]]
+	}
+
+	// Fields with no value are found even when they aren't kept, so that a
+	// line like "Cc:" isn't read as part of the value of the field before it.
 	for {
 		match := fieldLabelRegex.FindStringSubmatchIndex(remaining)
 		if match == nil {
@@ -68,11 +256,17 @@ func (b *MessageHeaderBlock) FromText(text string) (ok bool, before, after strin
 		}
 
 		relativeFieldStartIndex, relativeFieldEndIndex, relativeValueStartIndex := match[2], match[3], match[4]
+		isEmpty := relativeValueStartIndex < 0
+
+		if isEmpty {
+			relativeValueStartIndex = match[1]
+		}
 
 		position := messageHeaderFieldPosition{
 			LabelStartIndex: currentIndex + relativeFieldStartIndex,
 			LabelEndIndex:   currentIndex + relativeFieldEndIndex,
 			ValueStartIndex: currentIndex + relativeValueStartIndex,
+			IsEmpty:         isEmpty,
 		}
 		fieldPositions = append(fieldPositions, position)
 
@@ -80,7 +274,33 @@ func (b *MessageHeaderBlock) FromText(text string) (ok bool, before, after strin
 		remaining = remaining[relativeFieldEndIndex:]
 	}
 
+	// The same goes for stacked headers without banners, where a field which
+	// was already seen starts the next header.
+	seenFieldNames := make(map[string]bool, len(fieldPositions))
+
 	for i, position := range fieldPositions {
+		if position.IsEmpty && !allowEmptyFields {
+			continue
+		}
+
+		fieldName := strings.ToLower(text[position.LabelStartIndex:position.LabelEndIndex])
+
+		if seenFieldNames[fieldName] {
+			absoluteFieldListEndIndex = strings.LastIndexByte(text[:position.LabelStartIndex], '\n') + 1
+			after = text[absoluteFieldListEndIndex:]
+			fieldPositions = fieldPositions[:i]
+
+			break
+		}
+
+		seenFieldNames[fieldName] = true
+	}
+
+	for i, position := range fieldPositions {
+		if position.IsEmpty && !allowEmptyFields {
+			continue
+		}
+
 		var nextField Field
 		if i+1 < len(fieldPositions) {
 			nextPosition := fieldPositions[i+1]
@@ -100,3 +320,57 @@ func (b *MessageHeaderBlock) FromText(text string) (ok bool, before, after strin
 
 	return true, before, after
 }
+
+// ParseLeadingHeaders parses the contiguous header fields at the very start of
+// `text`, stopping at the first blank line. A line which is neither a known
+// field nor indented, like an "X-Mailer" field, also ends the headers, since
+// only indented lines continue the value of a field. It returns the index in
+// `text` where the body after the headers begins, or 0 if `text` doesn't start
+// with a header field.
+func ParseLeadingHeaders(text string) (headers MessageHeaderBlock, bodyStart int) {
+	if !leadingFieldLabelRegex.MatchString(text) {
+		return nil, 0
+	}
+
+	headerEnd, bodyStart := leadingHeaderEnd(text)
+
+	ok, _, after := headers.FromText(text[:headerEnd])
+	if !ok {
+		return nil, 0
+	}
+
+	// A repeated field ends the headers before the end of the lines.
+	if after != "" {
+		bodyStart = headerEnd - len(after)
+	}
+
+	return headers, bodyStart
+}
+
+// leadingHeaderEnd returns the index in `text` where the lines of its leading
+// header end, and the index where the body after them starts, which is after
+// the blank line that ends the header, if there is one.
+func leadingHeaderEnd(text string) (headerEnd, bodyStart int) {
+	lineStart := 0
+
+	for lineStart < len(text) {
+		lineEnd := len(text)
+		if newlineIndex := strings.IndexByte(text[lineStart:], '\n'); newlineIndex >= 0 {
+			lineEnd = lineStart + newlineIndex + 1
+		}
+
+		line := text[lineStart:lineEnd]
+
+		switch {
+		case strings.TrimSpace(line) == "":
+			return lineStart, lineEnd
+		case line[0] == ' ' || line[0] == '\t', leadingFieldLabelRegex.MatchString(line):
+		default:
+			return lineStart, lineStart
+		}
+
+		lineStart = lineEnd
+	}
+
+	return len(text), len(text)
+}
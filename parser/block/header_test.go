@@ -0,0 +1,326 @@
+package block
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMessageHeaderEmptyFields(t *testing.T) {
+	text := "-----Original Message-----\nFrom: Alice\nCc:\nSubject: Lunch\n"
+
+	tests := []struct {
+		name             string
+		allowEmptyFields bool
+		want             MessageHeaderBlock
+	}{
+		{
+			name: "strict",
+			want: MessageHeaderBlock{{Name: "From", Value: "Alice"}, {Name: "Subject", Value: "Lunch"}},
+		},
+		{
+			name:             "allowing empty fields",
+			allowEmptyFields: true,
+			want:             MessageHeaderBlock{{Name: "From", Value: "Alice"}, {Name: "Cc", Value: ""}, {Name: "Subject", Value: "Lunch"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			parser := &MessageHeaderParser{AllowEmptyFields: test.allowEmptyFields}
+
+			if ok, _, _ := parser.FromText(text); !ok {
+				t.Fatalf("FromText(%q) didn't match", text)
+			}
+
+			if !reflect.DeepEqual(parser.Header, test.want) {
+				t.Errorf("Header = %q, want %q", parser.Header, test.want)
+			}
+		})
+	}
+}
+
+// parseHeader parses `text` as a quoted message header, failing the test if
+// it isn't one.
+func parseHeader(t *testing.T, text string) MessageHeaderBlock {
+	t.Helper()
+
+	parser := &MessageHeaderParser{}
+
+	if ok, _, _ := parser.FromText(text); !ok {
+		t.Fatalf("FromText(%q) didn't match", text)
+	}
+
+	return parser.Header
+}
+
+func TestMessageHeaderValuesWithColons(t *testing.T) {
+	for _, subject := range []string{
+		"Re: meeting: agenda",
+		"Fwd: Re: To: everyone: Sent: today",
+		"Time: 10:30: sharp",
+		"Re: Message: 1234",
+	} {
+		t.Run(subject, func(t *testing.T) {
+			header := parseHeader(t, "From: Alice\nSubject: "+subject+"\nDate: Mon, 2 Jan 2006\n")
+
+			want := MessageHeaderBlock{{Name: "From", Value: "Alice"}, {Name: "Subject", Value: subject}, {Name: "Date", Value: "Mon, 2 Jan 2006"}}
+			if !reflect.DeepEqual(header, want) {
+				t.Errorf("header = %q, want %q", header, want)
+			}
+		})
+	}
+}
+
+func TestMessageHeaderAfterBanner(t *testing.T) {
+	want := MessageHeaderBlock{{Name: "Subject", Value: "Lunch"}, {Name: "From", Value: "Alice"}}
+
+	for _, text := range []string{
+		"-----Original Message-----\nSubject: Lunch\nFrom: Alice\n",
+		"-----Original Message-----\n\nSubject: Lunch\nFrom: Alice\n",
+		"-----Original Message-----\n  \n\nSubject: Lunch\nFrom: Alice\n",
+		"Subject: Lunch\nFrom: Alice\n",
+	} {
+		if got := parseHeader(t, text); !reflect.DeepEqual(got, want) {
+			t.Errorf("Header of %q = %q, want %q", text, got, want)
+		}
+	}
+}
+
+func TestParseLeadingHeaders(t *testing.T) {
+	tests := []struct {
+		name          string
+		text          string
+		wantHeaders   MessageHeaderBlock
+		wantBodyStart int
+	}{
+		{
+			name:          "with headers",
+			text:          "From: Alice\nSubject: Lunch\n\nSee you there.\n",
+			wantHeaders:   MessageHeaderBlock{{Name: "From", Value: "Alice"}, {Name: "Subject", Value: "Lunch"}},
+			wantBodyStart: len("From: Alice\nSubject: Lunch\n\n"),
+		},
+		{
+			name:          "with a folded value",
+			text:          "Subject: Lunch\n  on Friday\nFrom: Alice\n\nSee you there.\n",
+			wantHeaders:   MessageHeaderBlock{{Name: "Subject", Value: "Lunch\n  on Friday"}, {Name: "From", Value: "Alice"}},
+			wantBodyStart: len("Subject: Lunch\n  on Friday\nFrom: Alice\n\n"),
+		},
+		{
+			name:          "ending at an unknown field",
+			text:          "From: Alice\nX-Mailer: Example\n\nSee you there.\n",
+			wantHeaders:   MessageHeaderBlock{{Name: "From", Value: "Alice"}},
+			wantBodyStart: len("From: Alice\n"),
+		},
+		{
+			name:          "only headers",
+			text:          "From: Alice\nSubject: Lunch\n",
+			wantHeaders:   MessageHeaderBlock{{Name: "From", Value: "Alice"}, {Name: "Subject", Value: "Lunch"}},
+			wantBodyStart: len("From: Alice\nSubject: Lunch\n"),
+		},
+		{
+			name:          "without headers",
+			text:          "See you there.\n\nFrom: Alice\n",
+			wantHeaders:   nil,
+			wantBodyStart: 0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			headers, bodyStart := ParseLeadingHeaders(test.text)
+
+			if !reflect.DeepEqual(headers, test.wantHeaders) || bodyStart != test.wantBodyStart {
+				t.Errorf("ParseLeadingHeaders(%q) = %q, %d, want %q, %d", test.text, headers, bodyStart, test.wantHeaders, test.wantBodyStart)
+			}
+		})
+	}
+}
+
+func TestMessageHeaderMessageID(t *testing.T) {
+	tests := []struct {
+		name         string
+		text         string
+		messageField MessageFieldInterpretation
+		wantID       string
+		wantOk       bool
+	}{
+		{
+			name:   "Message-ID field",
+			text:   "From: Alice\nSubject: Lunch\nMessage-ID: <abc123@example.com>\n",
+			wantID: "<abc123@example.com>",
+			wantOk: true,
+		},
+		{
+			name:   "Message field with a message ID",
+			text:   "From: Alice\nSubject: Lunch\nMessage: <abc123@example.com>\n",
+			wantID: "<abc123@example.com>",
+			wantOk: true,
+		},
+		{
+			name:   "Message field with a message number",
+			text:   "From: Alice\nSubject: Lunch\nMessage: 42\n",
+			wantOk: false,
+		},
+		{
+			name:         "Message field read as generic",
+			text:         "From: Alice\nSubject: Lunch\nMessage: <abc123@example.com>\n",
+			messageField: MessageFieldGeneric,
+			wantOk:       false,
+		},
+		{
+			name:   "without a message ID",
+			text:   "From: Alice\nSubject: Lunch\n",
+			wantOk: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			header := parseHeader(t, test.text)
+
+			if id, ok := header.MessageID(test.messageField); id != test.wantID || ok != test.wantOk {
+				t.Errorf("MessageID(%q) of %q = %q, %v, want %q, %v", test.messageField, header, id, ok, test.wantID, test.wantOk)
+			}
+		})
+	}
+}
+
+func TestMessageHeaderMessageFieldInterpretation(t *testing.T) {
+	tests := []struct {
+		name               string
+		text               string
+		messageField       MessageFieldInterpretation
+		wantInterpretation MessageFieldInterpretation
+		wantOk             bool
+	}{
+		{
+			name:               "message ID value",
+			text:               "From: Alice\nSubject: Lunch\nMessage: <abc123@example.com>\n",
+			wantInterpretation: MessageFieldMessageID,
+			wantOk:             true,
+		},
+		{
+			name:               "plain value",
+			text:               "From: Alice\nSubject: Lunch\nMessage: 42\n",
+			wantInterpretation: MessageFieldGeneric,
+			wantOk:             true,
+		},
+		{
+			name:               "message ID among other text",
+			text:               "From: Alice\nSubject: Lunch\nMessage: see <abc123@example.com>\n",
+			wantInterpretation: MessageFieldGeneric,
+			wantOk:             true,
+		},
+		{
+			name:               "forced message ID",
+			text:               "From: Alice\nSubject: Lunch\nMessage: 42\n",
+			messageField:       MessageFieldMessageID,
+			wantInterpretation: MessageFieldMessageID,
+			wantOk:             true,
+		},
+		{
+			name:   "without a Message field",
+			text:   "From: Alice\nSubject: Lunch\nMessage-ID: <abc123@example.com>\n",
+			wantOk: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			header := parseHeader(t, test.text)
+
+			if interpretation, ok := header.MessageFieldInterpretation(test.messageField); interpretation != test.wantInterpretation || ok != test.wantOk {
+				t.Errorf("MessageFieldInterpretation(%q) of %q = %q, %v, want %q, %v", test.messageField, header, interpretation, ok, test.wantInterpretation, test.wantOk)
+			}
+		})
+	}
+}
+
+func TestMessageHeaderBanner(t *testing.T) {
+	tests := []struct {
+		name   string
+		banner string
+		want   bool
+	}{
+		{"tight", "-------Original Message-------\n", true},
+		{"spaced", "----- Original Message -----\n", true},
+		{"tabs", "-----\tOriginal Message\t-----\n", true},
+		{"forwarded", "---------- Forwarded message ---------\n", true},
+		{"without dashes after", "-----Original Message\n", false},
+		{"in a sentence", "See the Original Message below.\n", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := IsMessageHeaderBanner(test.banner); got != test.want {
+				t.Errorf("IsMessageHeaderBanner(%q) = %v, want %v", test.banner, got, test.want)
+			}
+
+			if !test.want {
+				return
+			}
+
+			text := test.banner + "From: Alice\nSubject: Lunch\n"
+
+			if got, want := parseHeader(t, text), (MessageHeaderBlock{{Name: "From", Value: "Alice"}, {Name: "Subject", Value: "Lunch"}}); !reflect.DeepEqual(got, want) {
+				t.Errorf("Header of %q = %q, want %q", text, got, want)
+			}
+		})
+	}
+}
+
+func TestMessageHeaderTabIndentedFields(t *testing.T) {
+	want := MessageHeaderBlock{
+		{Name: "From", Value: "Alice <alice@example.com>"},
+		{Name: "Date", Value: "Mon, 2 Jan 2006 15:04"},
+		{Name: "Subject", Value: "Lunch"},
+	}
+
+	tests := []struct {
+		name string
+		text string
+	}{
+		{"indented", "\tFrom: Alice <alice@example.com>\n\tDate: Mon, 2 Jan 2006 15:04\n\tSubject: Lunch\n"},
+		{"tab separated", "\tFrom:\tAlice <alice@example.com>\n\tDate:\tMon, 2 Jan 2006 15:04\n\tSubject:\tLunch\n"},
+		{"trailing tabs", "\tFrom: Alice <alice@example.com>\t\n\tDate: Mon, 2 Jan 2006 15:04\t\n\tSubject: Lunch\t\n"},
+		{"forwarded banner", "---------- Forwarded message ---------\n\tFrom:\tAlice <alice@example.com>\n\tDate:\tMon, 2 Jan 2006 15:04\n\tSubject:\tLunch\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := parseHeader(t, test.text); !reflect.DeepEqual(got, want) {
+				t.Errorf("Header of %q = %q, want %q", test.text, got, want)
+			}
+		})
+	}
+}
+
+func TestMessageHeaderCanonicalFields(t *testing.T) {
+	header := MessageHeaderBlock{
+		{Name: "Subject", Value: "Lunch"},
+		{Name: "X-Mailer", Value: "Example Mail"},
+		{Name: "cc", Value: "Carol"},
+		{Name: "To", Value: "Bob"},
+		{Name: "Importance", Value: "High"},
+		{Name: "Sent", Value: "Monday, January 02, 2006 3:04 PM"},
+		{Name: "From", Value: "Alice"},
+	}
+
+	want := []Field{
+		{Name: "From", Value: "Alice"},
+		{Name: "Sent", Value: "Monday, January 02, 2006 3:04 PM"},
+		{Name: "To", Value: "Bob"},
+		{Name: "cc", Value: "Carol"},
+		{Name: "Subject", Value: "Lunch"},
+		{Name: "X-Mailer", Value: "Example Mail"},
+		{Name: "Importance", Value: "High"},
+	}
+
+	if got := header.CanonicalFields(); !reflect.DeepEqual(got, want) {
+		t.Errorf("CanonicalFields() = %q, want %q", got, want)
+	}
+
+	if header[0].Name != "Subject" {
+		t.Errorf("CanonicalFields() reordered the header itself: %q", header)
+	}
+}
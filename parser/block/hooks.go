@@ -0,0 +1,52 @@
+package block
+
+// ParseHooks are called when blocks are parsed, like for counting how often
+// each attribution format matches in a metrics system. Any of them may be nil,
+// and so may a `*ParseHooks`, in which case nothing is called.
+//
+// The success hooks are called once for each block the tokenizer finds. The
+// header is only parsed by `MessageHeaderParser`, so `ParseLeadingHeaders`,
+// which is for the message's own header, doesn't call `HeaderParsed`. The text
+// around a block is searched again for other blocks, so `AttributionFailed`
+// may be called more than once for the same text.
+type ParseHooks struct {
+	// AttributionMatched is called with the name of the format, like
+	// "OnDateTime", when an attribution is parsed.
+	AttributionMatched func(format string)
+
+	// AttributionFailed is called when text matches an attribution format but
+	// its date or time can't be parsed, so it isn't used.
+	AttributionFailed func(format string, err error)
+
+	// HeaderParsed is called with the number of fields when a quoted message
+	// header is parsed.
+	HeaderParsed func(fieldCount int)
+
+	// FooterStripped is called with the kind of block when a footer, like a
+	// legal disclaimer, is split off from the text of a message.
+	FooterStripped func(kind Kind)
+}
+
+func (h *ParseHooks) attributionMatched(format string) {
+	if h != nil && h.AttributionMatched != nil {
+		h.AttributionMatched(format)
+	}
+}
+
+func (h *ParseHooks) attributionFailed(format string, err error) {
+	if h != nil && h.AttributionFailed != nil {
+		h.AttributionFailed(format, err)
+	}
+}
+
+func (h *ParseHooks) headerParsed(fieldCount int) {
+	if h != nil && h.HeaderParsed != nil {
+		h.HeaderParsed(fieldCount)
+	}
+}
+
+func (h *ParseHooks) footerStripped(kind Kind) {
+	if h != nil && h.FooterStripped != nil {
+		h.FooterStripped(kind)
+	}
+}
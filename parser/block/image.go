@@ -0,0 +1,43 @@
+package block
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Clients which convert HTML to plain text often leave a reference like
+// "[cid:image001.png@01D2A3B4.C5D6E7F0]" where an inline image was. Like
+// group resources, these are only matched on a line by themselves.
+var imageReferenceRegex = regexp.MustCompile(fmt.Sprintf(`(?m)^%[1]s\[(?i:cid):([^\[\]\s]+)\]%[1]s$`, nonNewlineWhitespaceRegexPart))
+
+// ImageReferenceBlock is a reference to an inline image by its content ID,
+// which is rendered as the image when `RenderOptions.ResolveContentID` can
+// find an archived copy of it.
+type ImageReferenceBlock struct {
+	ContentID string
+}
+
+// Name returns a human-readable name for the image, which is usually the part
+// of the content ID before the "@", like "image001.png".
+func (b *ImageReferenceBlock) Name() string {
+	if atIndex := strings.IndexByte(b.ContentID, '@'); atIndex > 0 {
+		return b.ContentID[:atIndex]
+	}
+
+	return b.ContentID
+}
+
+func (b *ImageReferenceBlock) FromText(text string) (ok bool, before, after string) {
+	match := imageReferenceRegex.FindStringSubmatchIndex(text)
+	if match == nil {
+		return false, "", ""
+	}
+
+	matchStartIndex, matchEndIndex := match[0], match[1]
+	contentIDStartIndex, contentIDEndIndex := indicesForCaptureGroup(match, 1)
+
+	b.ContentID = text[contentIDStartIndex:contentIDEndIndex]
+
+	return true, text[:matchStartIndex], text[matchEndIndex:]
+}
@@ -0,0 +1,87 @@
+package block
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestImageReferenceFromText(t *testing.T) {
+	tests := []struct {
+		name          string
+		text          string
+		wantOk        bool
+		wantContentID string
+		wantName      string
+	}{
+		{
+			name:          "on its own line",
+			text:          "Here's the chart:\n[cid:image001.png@01D2A3B4.C5D6E7F0]\n",
+			wantOk:        true,
+			wantContentID: "image001.png@01D2A3B4.C5D6E7F0",
+			wantName:      "image001.png",
+		},
+		{
+			name:          "uppercase without a domain",
+			text:          "  [CID:chart.gif]\n",
+			wantOk:        true,
+			wantContentID: "chart.gif",
+			wantName:      "chart.gif",
+		},
+		{
+			name:   "in a sentence",
+			text:   "See [cid:image001.png] for the chart.\n",
+			wantOk: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			image := &ImageReferenceBlock{}
+
+			ok, _, _ := image.FromText(test.text)
+			if ok != test.wantOk {
+				t.Fatalf("FromText(%q) ok = %v, want %v", test.text, ok, test.wantOk)
+			}
+
+			if image.ContentID != test.wantContentID || image.Name() != test.wantName {
+				t.Errorf("FromText(%q) = %q, %q, want %q, %q", test.text, image.ContentID, image.Name(), test.wantContentID, test.wantName)
+			}
+		})
+	}
+}
+
+func TestImageReferenceWriteHtml(t *testing.T) {
+	image := &ImageReferenceBlock{ContentID: "image001.png@01D2A3B4.C5D6E7F0"}
+
+	resolve := func(contentID string) (string, bool) {
+		if contentID == image.ContentID {
+			return "/attachments/image001.png", true
+		}
+
+		return "", false
+	}
+
+	tests := []struct {
+		name    string
+		options RenderOptions
+		want    string
+	}{
+		{"resolved", RenderOptions{ResolveContentID: resolve}, `<img src="/attachments/image001.png" alt="image001.png">`},
+		{"unresolved", RenderOptions{ResolveContentID: func(string) (string, bool) { return "", false }}, "Image: image001.png"},
+		{"without a resolver", RenderOptions{}, "Image: image001.png"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var output strings.Builder
+
+			if err := image.WriteHtml(&output, test.options); err != nil {
+				t.Fatal(err)
+			}
+
+			if !strings.Contains(output.String(), test.want) {
+				t.Errorf("rendered HTML doesn't contain %s:\n%s", test.want, output.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package block
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+type Kind string
+
+const (
+	KindHardBreak      Kind = "HardBreak"
+	KindDivider        Kind = "Divider"
+	KindMessageHeader  Kind = "MessageHeader"
+	KindAttribution    Kind = "Attribution"
+	KindDisclaimer     Kind = "Disclaimer"
+	KindGroupResource  Kind = "GroupResource"
+	KindRawHtml        Kind = "RawHtml"
+	KindAsciiTable     Kind = "AsciiTable"
+	KindScissor        Kind = "Scissor"
+	KindTruncation     Kind = "Truncation"
+	KindImageReference Kind = "ImageReference"
+	KindPgp            Kind = "Pgp"
+	KindUnknown        Kind = "Unknown"
+)
+
+// KindOf returns the kind of a built-in block, or `KindUnknown` for any other
+// implementation of `Block`.
+func KindOf(b Block) Kind {
+	switch b.(type) {
+	case *HardBreakBlock:
+		return KindHardBreak
+	case *DividerBlock:
+		return KindDivider
+	case *MessageHeaderBlock:
+		return KindMessageHeader
+	case *AttributionBlock:
+		return KindAttribution
+	case *DisclaimerBlock:
+		return KindDisclaimer
+	case *GroupResourceBlock:
+		return KindGroupResource
+	case *RawHtmlBlock:
+		return KindRawHtml
+	case *AsciiTableBlock:
+		return KindAsciiTable
+	case *ScissorBlock:
+		return KindScissor
+	case *TruncationBlock:
+		return KindTruncation
+	case *ImageReferenceBlock:
+		return KindImageReference
+	case *PgpBlock:
+		return KindPgp
+	default:
+		return KindUnknown
+	}
+}
+
+// ContentLength returns the number of runes of meaningful content in a block,
+// like the name in an attribution or the field values in a header. Blocks
+// with no content of their own, like dividers, have a length of 0.
+func ContentLength(b Block) int {
+	switch concreteBlock := b.(type) {
+	case *MessageHeaderBlock:
+		length := 0
+
+		for _, field := range *concreteBlock {
+			length += utf8.RuneCountInString(field.Value)
+		}
+
+		return length
+	case *AttributionBlock:
+		return utf8.RuneCountInString(concreteBlock.Name)
+	case *DisclaimerBlock:
+		return utf8.RuneCountInString(strings.Join(strings.Fields(concreteBlock.Text), " "))
+	case *GroupResourceBlock:
+		return utf8.RuneCountInString(concreteBlock.Url)
+	case *ImageReferenceBlock:
+		return utf8.RuneCountInString(concreteBlock.ContentID)
+	case *RawHtmlBlock:
+		return utf8.RuneCountInString(concreteBlock.Html)
+	case *AsciiTableBlock:
+		length := 0
+
+		for _, row := range append([][]string{concreteBlock.Header}, concreteBlock.Rows...) {
+			for _, cell := range row {
+				length += utf8.RuneCountInString(cell)
+			}
+		}
+
+		return length
+	default:
+		return 0
+	}
+}
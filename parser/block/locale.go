@@ -0,0 +1,151 @@
+package block
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrUnrecognizedDate = errors.New("unrecognized date")
+
+// These map the lowercase names and abbreviations of months and weekdays in
+// the locales we recognize to their values. Abbreviations are listed without
+// a trailing period, which is optional when matching.
+var (
+	monthNames = map[string]time.Month{
+		// English
+		"jan": time.January, "feb": time.February, "mar": time.March, "apr": time.April,
+		"may": time.May, "jun": time.June, "jul": time.July, "aug": time.August,
+		"sep": time.September, "oct": time.October, "nov": time.November, "dec": time.December,
+		"january": time.January, "february": time.February, "march": time.March, "june": time.June,
+		"july": time.July, "october": time.October, "december": time.December,
+
+		// German
+		"januar": time.January, "februar": time.February, "mär": time.March, "märz": time.March,
+		"mrz": time.March, "april": time.April, "mai": time.May, "juni": time.June,
+		"juli": time.July, "august": time.August, "sept": time.September, "september": time.September,
+		"okt": time.October, "oktober": time.October, "november": time.November, "dez": time.December,
+		"dezember": time.December,
+
+		// French
+		"janv": time.January, "janvier": time.January, "févr": time.February, "février": time.February,
+		"mars": time.March, "avr": time.April, "avril": time.April, "juin": time.June,
+		"juil": time.July, "juillet": time.July, "août": time.August, "septembre": time.September,
+		"octobre": time.October, "novembre": time.November, "déc": time.December, "décembre": time.December,
+	}
+
+	weekdayNames = map[string]time.Weekday{
+		// English
+		"mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday, "thu": time.Thursday,
+		"fri": time.Friday, "sat": time.Saturday, "sun": time.Sunday,
+
+		// German
+		"mo": time.Monday, "di": time.Tuesday, "mi": time.Wednesday, "do": time.Thursday,
+		"fr": time.Friday, "sa": time.Saturday, "so": time.Sunday,
+		"montag": time.Monday, "dienstag": time.Tuesday, "mittwoch": time.Wednesday, "donnerstag": time.Thursday,
+		"freitag": time.Friday, "samstag": time.Saturday, "sonntag": time.Sunday,
+
+		// French
+		"lun": time.Monday, "mar": time.Tuesday, "mer": time.Wednesday, "jeu": time.Thursday,
+		"ven": time.Friday, "sam": time.Saturday, "dim": time.Sunday,
+		"lundi": time.Monday, "mardi": time.Tuesday, "mercredi": time.Wednesday, "jeudi": time.Thursday,
+		"vendredi": time.Friday, "samedi": time.Saturday, "dimanche": time.Sunday,
+	}
+)
+
+var (
+	monthNameRegexPart   = localizedNamesRegexPart(monthNameList())
+	weekdayNameRegexPart = localizedNamesRegexPart(weekdayNameList())
+
+	longDateWordRegex = regexp.MustCompile(`[\p{L}]+|\d+`)
+)
+
+func monthNameList() []string {
+	names := make([]string, 0, len(monthNames))
+
+	for name := range monthNames {
+		names = append(names, name)
+	}
+
+	return names
+}
+
+func weekdayNameList() []string {
+	names := make([]string, 0, len(weekdayNames))
+
+	for name := range weekdayNames {
+		names = append(names, name)
+	}
+
+	return names
+}
+
+// localizedNamesRegexPart matches any of `names`, case insensitively, with an
+// optional trailing period.
+func localizedNamesRegexPart(names []string) string {
+	sortedNames := append([]string(nil), names...)
+
+	// Longer names come first so that "märz" isn't matched as "mär".
+	sort.Slice(sortedNames, func(i, j int) bool {
+		if len(sortedNames[i]) != len(sortedNames[j]) {
+			return len(sortedNames[i]) > len(sortedNames[j])
+		}
+
+		return sortedNames[i] < sortedNames[j]
+	})
+
+	for i, name := range sortedNames {
+		sortedNames[i] = regexp.QuoteMeta(name)
+	}
+
+	return fmt.Sprintf(`(?i:%s)\.?`, strings.Join(sortedNames, "|"))
+}
+
+// leadingWeekday returns the weekday which `text` starts with, like "Mon" in
+// "Mon, 2 Jan".
+func leadingWeekday(text string) (weekday time.Weekday, ok bool) {
+	word := longDateWordRegex.FindString(text)
+	weekday, ok = weekdayNames[strings.ToLower(word)]
+
+	return weekday, ok
+}
+
+// parseLongDate parses a date with a month name, like "2 Jan 2006", "Mo., 2.
+// März 2006", or "lun. 2 janv. 2006". Go's `time.Parse` only understands
+// English month names, so this maps the month name to a `time.Month` itself.
+// A weekday, if there is one, always comes before the month name, so the last
+// name in `text` is the month. Other words, like "the" and "of" in "the 2nd of
+// January 2006", are skipped, and ordinal suffixes are split off the day.
+func parseLongDate(text string) (time.Time, error) {
+	var (
+		day, year int
+		month     time.Month
+	)
+
+	for _, word := range longDateWordRegex.FindAllString(text, -1) {
+		if number, err := strconv.Atoi(word); err == nil {
+			if len(word) == 4 {
+				year = number
+			} else {
+				day = number
+			}
+		} else if wordMonth, ok := monthNames[strings.ToLower(word)]; ok {
+			month = wordMonth
+		}
+	}
+
+	if day == 0 || month == 0 || year == 0 {
+		return time.Time{}, fmt.Errorf("%w: %q", ErrUnrecognizedDate, text)
+	}
+
+	date := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	if date.Day() != day {
+		return time.Time{}, fmt.Errorf("%w: %q", ErrUnrecognizedDate, text)
+	}
+
+	return date, nil
+}
@@ -0,0 +1,135 @@
+package block
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// PgpKind is the kind of PGP armor, from the "-----BEGIN PGP ...-----" line.
+type PgpKind string
+
+const (
+	PgpSignature     PgpKind = "SIGNATURE"
+	PgpMessage       PgpKind = "MESSAGE"
+	PgpPublicKey     PgpKind = "PUBLIC KEY BLOCK"
+	PgpPrivateKey    PgpKind = "PRIVATE KEY BLOCK"
+	PgpSignedMessage PgpKind = "SIGNED MESSAGE"
+)
+
+var pgpKinds = []PgpKind{PgpSignedMessage, PgpSignature, PgpMessage, PgpPublicKey, PgpPrivateKey}
+
+// Label returns a human-readable name for the kind of armor.
+func (k PgpKind) Label() string {
+	switch k {
+	case PgpSignature:
+		return "PGP signature"
+	case PgpMessage:
+		return "PGP encrypted message"
+	case PgpPublicKey:
+		return "PGP public key"
+	case PgpPrivateKey:
+		return "PGP private key"
+	case PgpSignedMessage:
+		return "PGP signed message"
+	default:
+		return "PGP block"
+	}
+}
+
+var (
+	pgpBeginRegex = regexp.MustCompile(fmt.Sprintf(`(?m)^%[1]s-----BEGIN PGP (%[2]s)-----%[1]s$`, nonNewlineWhitespaceRegexPart, pgpKindsRegexPart()))
+
+	// A clearsigned message starts with the banner and its "Hash:" headers,
+	// and the text after them is the message itself, so there's no end line.
+	pgpSignedMessageHeadersRegex = regexp.MustCompile(`^(?:\n[\t ]*Hash:.*)*`)
+
+	pgpEndRegexes = pgpEndRegexesForKinds()
+)
+
+func pgpKindsRegexPart() string {
+	kindRegexParts := make([]string, len(pgpKinds))
+
+	for i, kind := range pgpKinds {
+		kindRegexParts[i] = regexp.QuoteMeta(string(kind))
+	}
+
+	return strings.Join(kindRegexParts, "|")
+}
+
+func pgpEndRegexesForKinds() map[PgpKind]*regexp.Regexp {
+	regexes := make(map[PgpKind]*regexp.Regexp, len(pgpKinds))
+
+	for _, kind := range pgpKinds {
+		if kind != PgpSignedMessage {
+			regexes[kind] = regexp.MustCompile(fmt.Sprintf(`(?m)^%[1]s-----END PGP %[2]s-----%[1]s$\n?`, nonNewlineWhitespaceRegexPart, regexp.QuoteMeta(string(kind))))
+		}
+	}
+
+	return regexes
+}
+
+// PgpBlock is a section of PGP armor, like a signature or a public key, from
+// its "-----BEGIN PGP ...-----" line to its "-----END PGP ...-----" line.
+// Nothing inside it is parsed as another block, and it's rendered collapsed
+// since it's noise to most readers.
+type PgpBlock struct {
+	Kind  PgpKind
+	Armor string
+}
+
+// pgpArmorEnd returns the index in `text` after the end of the armor which
+// starts with the BEGIN line ending at `beginEndIndex`, or -1 if the armor
+// isn't closed.
+func pgpArmorEnd(text string, kind PgpKind, beginEndIndex int) int {
+	if kind == PgpSignedMessage {
+		headersEndIndex := beginEndIndex + pgpSignedMessageHeadersRegex.FindStringIndex(text[beginEndIndex:])[1]
+
+		if headersEndIndex < len(text) && text[headersEndIndex] == '\n' {
+			headersEndIndex++
+		}
+
+		return headersEndIndex
+	}
+
+	match := pgpEndRegexes[kind].FindStringIndex(text[beginEndIndex:])
+	if match == nil {
+		return -1
+	}
+
+	return beginEndIndex + match[1]
+}
+
+func (b *PgpBlock) FromText(text string) (ok bool, before, after string) {
+	for _, match := range pgpBeginRegex.FindAllStringSubmatchIndex(text, -1) {
+		matchStartIndex, beginEndIndex := match[0], match[1]
+		kindStartIndex, kindEndIndex := indicesForCaptureGroup(match, 1)
+		kind := PgpKind(text[kindStartIndex:kindEndIndex])
+
+		matchEndIndex := pgpArmorEnd(text, kind, beginEndIndex)
+		if matchEndIndex < 0 {
+			continue
+		}
+
+		b.Kind = kind
+		b.Armor = strings.TrimSpace(text[matchStartIndex:matchEndIndex])
+
+		return true, text[:matchStartIndex], text[matchEndIndex:]
+	}
+
+	return false, "", ""
+}
+
+// ContinuesParagraph returns whether `text` has armor which isn't closed,
+// since the blank line after the armor headers splits it into paragraphs.
+func (b *PgpBlock) ContinuesParagraph(text string) bool {
+	matches := pgpBeginRegex.FindAllStringSubmatchIndex(text, -1)
+	if len(matches) == 0 {
+		return false
+	}
+
+	lastMatch := matches[len(matches)-1]
+	kindStartIndex, kindEndIndex := indicesForCaptureGroup(lastMatch, 1)
+
+	return pgpArmorEnd(text, PgpKind(text[kindStartIndex:kindEndIndex]), lastMatch[1]) < 0
+}
@@ -0,0 +1,77 @@
+package block
+
+import "testing"
+
+func TestPgpFromText(t *testing.T) {
+	const (
+		signature = "-----BEGIN PGP SIGNATURE-----\nVersion: GnuPG v1\n\niD8DBQFD\n-----END PGP SIGNATURE-----"
+		publicKey = "-----BEGIN PGP PUBLIC KEY BLOCK-----\nVersion: GnuPG v1\n\nmQGiBD\n=Ab12\n-----END PGP PUBLIC KEY BLOCK-----"
+	)
+
+	tests := []struct {
+		name       string
+		text       string
+		wantOk     bool
+		wantKind   PgpKind
+		wantArmor  string
+		wantBefore string
+		wantAfter  string
+	}{
+		{
+			name:       "signature",
+			text:       "Signed.\n" + signature + "\nBye.\n",
+			wantOk:     true,
+			wantKind:   PgpSignature,
+			wantArmor:  signature,
+			wantBefore: "Signed.\n",
+			wantAfter:  "Bye.\n",
+		},
+		{
+			name:       "public key",
+			text:       "My key:\n" + publicKey + "\n",
+			wantOk:     true,
+			wantKind:   PgpPublicKey,
+			wantArmor:  publicKey,
+			wantBefore: "My key:\n",
+			wantAfter:  "",
+		},
+		{
+			name:       "signed message headers",
+			text:       "-----BEGIN PGP SIGNED MESSAGE-----\nHash: SHA1\n\nHello.\n",
+			wantOk:     true,
+			wantKind:   PgpSignedMessage,
+			wantArmor:  "-----BEGIN PGP SIGNED MESSAGE-----\nHash: SHA1",
+			wantBefore: "",
+			wantAfter:  "\nHello.\n",
+		},
+		{
+			name:   "mismatched end line",
+			text:   "-----BEGIN PGP PUBLIC KEY BLOCK-----\nmQGiBD\n-----END PGP SIGNATURE-----\n",
+			wantOk: false,
+		},
+		{
+			name:   "in a sentence",
+			text:   "Paste it after -----BEGIN PGP SIGNATURE----- like this.\n",
+			wantOk: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pgp := &PgpBlock{}
+
+			ok, before, after := pgp.FromText(test.text)
+			if ok != test.wantOk {
+				t.Fatalf("FromText(%q) ok = %v, want %v", test.text, ok, test.wantOk)
+			}
+
+			if pgp.Kind != test.wantKind || pgp.Armor != test.wantArmor {
+				t.Errorf("FromText(%q) Kind, Armor = %q, %q, want %q, %q", test.text, pgp.Kind, pgp.Armor, test.wantKind, test.wantArmor)
+			}
+
+			if before != test.wantBefore || after != test.wantAfter {
+				t.Errorf("FromText(%q) = %q, %q, want %q, %q", test.text, before, after, test.wantBefore, test.wantAfter)
+			}
+		})
+	}
+}
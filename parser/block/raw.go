@@ -0,0 +1,75 @@
+package block
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// RawHtmlBlock is a section of the body which is emitted verbatim, without
+// escaping or looking for other blocks inside it. The section starts and ends
+// with a line consisting of only `Sentinel`.
+//
+// Because the HTML isn't sanitized, this should only be enabled for archives
+// whose contents are trusted.
+type RawHtmlBlock struct {
+	Sentinel string
+	Html     string
+}
+
+type rawHtmlSentinelRegexes struct {
+	section  *regexp.Regexp
+	sentinel *regexp.Regexp
+}
+
+// Blocks are created for every paragraph, so the regexes for each sentinel
+// are compiled once and cached.
+var rawHtmlSentinelRegexCache sync.Map
+
+func regexesForRawHtmlSentinel(sentinel string) *rawHtmlSentinelRegexes {
+	if cached, ok := rawHtmlSentinelRegexCache.Load(sentinel); ok {
+		return cached.(*rawHtmlSentinelRegexes)
+	}
+
+	sentinelRegexPart := fmt.Sprintf(`^%[1]s%[2]s%[1]s$`, nonNewlineWhitespaceRegexPart, regexp.QuoteMeta(sentinel))
+
+	regexes := &rawHtmlSentinelRegexes{
+		section:  regexp.MustCompile(fmt.Sprintf(`(?ms)%[1]s\n?(.*?)%[1]s\n?`, sentinelRegexPart)),
+		sentinel: regexp.MustCompile(fmt.Sprintf(`(?m)%s`, sentinelRegexPart)),
+	}
+
+	cached, _ := rawHtmlSentinelRegexCache.LoadOrStore(sentinel, regexes)
+
+	return cached.(*rawHtmlSentinelRegexes)
+}
+
+func (b *RawHtmlBlock) FromText(text string) (ok bool, before, after string) {
+	if b.Sentinel == "" {
+		return false, "", ""
+	}
+
+	match := regexesForRawHtmlSentinel(b.Sentinel).section.FindStringSubmatchIndex(text)
+	if match == nil {
+		return false, "", ""
+	}
+
+	matchStartIndex, matchEndIndex := match[0], match[1]
+	htmlStartIndex, htmlEndIndex := indicesForCaptureGroup(match, 1)
+
+	b.Html = strings.TrimSpace(text[htmlStartIndex:htmlEndIndex])
+
+	return true, text[:matchStartIndex], text[matchEndIndex:]
+}
+
+// ContinuesParagraph returns whether `text` opens a raw section without
+// closing it, in which case the section continues into the next paragraph.
+func (b *RawHtmlBlock) ContinuesParagraph(text string) bool {
+	if b.Sentinel == "" {
+		return false
+	}
+
+	sentinels := regexesForRawHtmlSentinel(b.Sentinel).sentinel.FindAllStringIndex(text, -1)
+
+	return len(sentinels)%2 == 1
+}
@@ -0,0 +1,58 @@
+package block
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	hoursPerDay   = 24
+	daysPerMonth  = 30
+	daysPerYear   = 365
+	monthsPerYear = 12
+)
+
+func pluralizeUnit(count int, unit string) string {
+	if count == 1 {
+		return fmt.Sprintf("1 %s", unit)
+	}
+
+	return fmt.Sprintf("%d %ss", count, unit)
+}
+
+func relativeDuration(duration time.Duration) string {
+	days := int(duration.Hours()) / hoursPerDay
+
+	switch {
+	case duration < time.Minute:
+		return ""
+	case duration < time.Hour:
+		return pluralizeUnit(int(duration.Minutes()), "minute")
+	case days < 1:
+		return pluralizeUnit(int(duration.Hours()), "hour")
+	case days < daysPerMonth:
+		return pluralizeUnit(days, "day")
+	case days < daysPerYear:
+		months := days / daysPerMonth
+		if months >= monthsPerYear {
+			months = monthsPerYear - 1
+		}
+
+		return pluralizeUnit(months, "month")
+	default:
+		return pluralizeUnit(days/daysPerYear, "year")
+	}
+}
+
+// formatRelativeTime formats `t` relative to `now`, like "3 years ago".
+func formatRelativeTime(t, now time.Time) string {
+	if t.After(now) {
+		if relative := relativeDuration(t.Sub(now)); relative != "" {
+			return fmt.Sprintf("in %s", relative)
+		}
+	} else if relative := relativeDuration(now.Sub(t)); relative != "" {
+		return fmt.Sprintf("%s ago", relative)
+	}
+
+	return "just now"
+}
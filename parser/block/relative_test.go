@@ -0,0 +1,66 @@
+package block
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatRelativeTime(t *testing.T) {
+	now := time.Date(2009, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		time time.Time
+		want string
+	}{
+		{"seconds", now.Add(-30 * time.Second), "just now"},
+		{"one minute", now.Add(-time.Minute), "1 minute ago"},
+		{"hours", now.Add(-5 * time.Hour), "5 hours ago"},
+		{"days", now.AddDate(0, 0, -3), "3 days ago"},
+		{"months", now.AddDate(0, -2, 0), "1 month ago"},
+		{"almost a year", now.AddDate(0, 0, -364), "11 months ago"},
+		{"years", time.Date(2006, time.January, 2, 15, 4, 0, 0, time.UTC), "3 years ago"},
+		{"in the future", now.AddDate(0, 0, 2), "in 2 days"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := formatRelativeTime(test.time, now); got != test.want {
+				t.Errorf("formatRelativeTime(%v, %v) = %q, want %q", test.time, now, got, test.want)
+			}
+		})
+	}
+}
+
+func TestAttributionRelativeTime(t *testing.T) {
+	now := time.Date(2009, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	attribution := &AttributionBlock{}
+	parseAttribution(t, attribution, "On Mon, 2 Jan 2006 15:04, Alice wrote:\n")
+
+	var output strings.Builder
+	if err := attribution.WriteHtml(&output, RenderOptions{RelativeTimeNow: now}); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := `title="2 Jan 2006, 15:04 &#43;00:00">3 years ago</time>`; !strings.Contains(output.String(), want) {
+		t.Errorf("rendered HTML doesn't contain %s:\n%s", want, output.String())
+	}
+
+	if rendered := attribution.ToHtml(); strings.Contains(rendered, "ago") {
+		t.Errorf("attribution is relative without RelativeTimeNow:\n%s", rendered)
+	}
+
+	undated := &AttributionBlock{}
+	parseAttribution(t, undated, "Alice <alice@example.com> wrote:\n")
+
+	output.Reset()
+	if err := undated.WriteHtml(&output, RenderOptions{RelativeTimeNow: now}); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(output.String(), "<time") {
+		t.Errorf("attribution without a date has a time:\n%s", output.String())
+	}
+}
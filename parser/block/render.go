@@ -4,6 +4,7 @@ import (
 	_ "embed"
 	"github.com/Masterminds/sprig/v3"
 	"html/template"
+	"io"
 	"strings"
 	"time"
 )
@@ -11,61 +12,316 @@ import (
 //go:embed header.html.tmpl
 var messageHeaderTemplateString string
 
-var messageHeaderTemplate = template.Must(template.New("header-block").Funcs(sprig.FuncMap()).Parse(messageHeaderTemplateString))
+var messageHeaderTemplate = template.Must(template.New("header-block").Funcs(sprig.FuncMap()).Parse(strings.TrimSpace(messageHeaderTemplateString)))
 
 //go:embed attribution.html.tmpl
 var attributionTemplateString string
 
-var attributionTemplate = template.Must(template.New("attribution-block").Funcs(sprig.FuncMap()).Parse(attributionTemplateString))
+var attributionTemplate = template.Must(template.New("attribution-block").Funcs(sprig.FuncMap()).Parse(strings.TrimSpace(attributionTemplateString)))
+
+//go:embed disclaimer.html.tmpl
+var disclaimerTemplateString string
+
+var disclaimerTemplate = template.Must(template.New("disclaimer-block").Funcs(sprig.FuncMap()).Parse(strings.TrimSpace(disclaimerTemplateString)))
+
+//go:embed resource.html.tmpl
+var groupResourceTemplateString string
+
+var groupResourceTemplate = template.Must(template.New("group-resource-block").Funcs(sprig.FuncMap()).Parse(strings.TrimSpace(groupResourceTemplateString)))
+
+//go:embed table.html.tmpl
+var asciiTableTemplateString string
+
+var asciiTableTemplate = template.Must(template.New("ascii-table-block").Funcs(sprig.FuncMap()).Parse(strings.TrimSpace(asciiTableTemplateString)))
+
+//go:embed scissor.html.tmpl
+var scissorTemplateString string
+
+var scissorTemplate = template.Must(template.New("scissor-block").Funcs(sprig.FuncMap()).Parse(strings.TrimSpace(scissorTemplateString)))
+
+//go:embed truncation.html.tmpl
+var truncationTemplateString string
+
+var truncationTemplate = template.Must(template.New("truncation-block").Funcs(sprig.FuncMap()).Parse(strings.TrimSpace(truncationTemplateString)))
+
+//go:embed image.html.tmpl
+var imageReferenceTemplateString string
+
+var imageReferenceTemplate = template.Must(template.New("image-reference-block").Funcs(sprig.FuncMap()).Parse(strings.TrimSpace(imageReferenceTemplateString)))
+
+//go:embed pgp.html.tmpl
+var pgpTemplateString string
+
+var pgpTemplate = template.Must(template.New("pgp-block").Funcs(sprig.FuncMap()).Parse(strings.TrimSpace(pgpTemplateString)))
 
 type messageHeaderTemplateParams struct {
-	Fields []Field
+	Fields []messageHeaderFieldTemplateParams
+}
+
+type messageHeaderFieldTemplateParams struct {
+	Name    string
+	Value   string
+	Address *addressTemplateParams
+}
+
+type addressTemplateParams struct {
+	Name   string
+	Email  string
+	Mailto string
+}
+
+type disclaimerTemplateParams struct {
+	Text string
+}
+
+type groupResourceTemplateParams struct {
+	Url     string
+	Label   string
+	IsPhoto bool
+}
+
+type imageReferenceTemplateParams struct {
+	Src  string
+	Name string
+}
+
+type pgpTemplateParams struct {
+	Kind  string
+	Label string
+	Lines []string
+}
+
+type asciiTableTemplateParams struct {
+	Header []string
+	Rows   [][]string
 }
 
 type attributionTemplateParams struct {
 	Name              string
+	Role              string
+	Format            string
+	RawDatetime       string
+	RelativeDatetime  string
 	FormattedDatetime string
 	Timestamp         string
+	Compact           bool
+	ShortDate         string
 }
 
-func (b *MessageHeaderBlock) ToHtml() string {
-	params := messageHeaderTemplateParams{Fields: *b}
-
+func blockToHtml(b Block) string {
 	var output strings.Builder
 
-	if err := messageHeaderTemplate.Execute(&output, params); err != nil {
+	if err := b.WriteHtml(&output, RenderOptions{}); err != nil {
 		panic(err)
 	}
 
-	return strings.TrimSpace(output.String())
+	return output.String()
+}
+
+func newAddressTemplateParams(address Address) *addressTemplateParams {
+	params := &addressTemplateParams{Name: address.Name, Email: address.Email}
+
+	if linkableAddressRegex.MatchString(address.Email) {
+		params.Mailto = "mailto:" + address.Email
+	}
+
+	return params
+}
+
+func (b *MessageHeaderBlock) WriteHtml(w io.Writer, options RenderOptions) error {
+	fields := []Field(*b)
+	if options.CanonicalHeaderOrder {
+		fields = b.CanonicalFields()
+	}
+
+	params := messageHeaderTemplateParams{
+		Fields: make([]messageHeaderFieldTemplateParams, len(fields)),
+	}
+
+	for i, field := range fields {
+		params.Fields[i] = messageHeaderFieldTemplateParams{Name: field.Name, Value: field.Value}
+	}
+
+	// The sender is shown by name with their address after it when it can be
+	// parsed, and as-is otherwise.
+	if address, ok := b.From(); ok {
+		for i, field := range fields {
+			if strings.EqualFold(field.Name, fieldNameFrom) {
+				params.Fields[i].Address = newAddressTemplateParams(address)
+				break
+			}
+		}
+	}
+
+	return messageHeaderTemplate.Execute(w, params)
+}
+
+func (b *MessageHeaderBlock) ToHtml() string {
+	return blockToHtml(b)
+}
+
+func (p *MessageHeaderParser) WriteHtml(w io.Writer, options RenderOptions) error {
+	return p.Header.WriteHtml(w, options)
+}
+
+func (p *MessageHeaderParser) ToHtml() string {
+	return p.Header.ToHtml()
+}
+
+func (b *DividerBlock) WriteHtml(w io.Writer, _ RenderOptions) error {
+	_, err := io.WriteString(w, "<hr>")
+	return err
 }
 
 func (b *DividerBlock) ToHtml() string {
-	return "<hr>"
+	return blockToHtml(b)
 }
 
-func (b *AttributionBlock) ToHtml() string {
-	params := attributionTemplateParams{Name: b.Name}
+func (b *ScissorBlock) WriteHtml(w io.Writer, _ RenderOptions) error {
+	return scissorTemplate.Execute(w, nil)
+}
+
+func (b *ScissorBlock) ToHtml() string {
+	return blockToHtml(b)
+}
+
+func (b *TruncationBlock) WriteHtml(w io.Writer, _ RenderOptions) error {
+	return truncationTemplate.Execute(w, nil)
+}
+
+func (b *TruncationBlock) ToHtml() string {
+	return blockToHtml(b)
+}
+
+func (b *AttributionBlock) WriteHtml(w io.Writer, options RenderOptions) error {
+	params := attributionTemplateParams{Name: b.Name, Role: b.Role}
+
+	if options.IncludeFormat {
+		params.Format = b.Format
+	}
+
+	params.RawDatetime = b.RawTime
+	params.Compact = options.CompactAttributions
 
 	if !b.Time.IsZero() {
+		locale := DateLocaleEnglish
+		if options.DateLocale != nil {
+			locale = *options.DateLocale
+		}
+
 		params.Timestamp = b.Time.Format(time.RFC3339)
+		if b.UnknownOffset {
+			// Without an offset, this is a local date and time rather than an
+			// exact instant.
+			params.Timestamp = b.Time.Format("2006-01-02T15:04:05")
+		}
+		params.ShortDate = locale.FormatShortDate(b.Time)
 
-		if b.HasTime {
-			params.FormattedDatetime = b.Time.Format("2 Jan 2006, 15:04 -07:00")
+		if b.HasTime && b.UnknownOffset {
+			params.FormattedDatetime = locale.FormatDateTimeWithZoneName(b.Time, b.TimezoneName)
+		} else if b.HasTime {
+			params.FormattedDatetime = locale.FormatDateTime(b.Time)
 		} else {
-			params.FormattedDatetime = b.Time.Format("2 Jan 2006")
+			params.FormattedDatetime = locale.FormatDate(b.Time)
+		}
+
+		if !options.RelativeTimeNow.IsZero() {
+			params.RelativeDatetime = formatRelativeTime(b.Time, options.RelativeTimeNow)
 		}
 	}
 
-	var output strings.Builder
+	return attributionTemplate.Execute(w, params)
+}
 
-	if err := attributionTemplate.Execute(&output, params); err != nil {
-		panic(err)
-	}
+func (b *AttributionBlock) ToHtml() string {
+	return blockToHtml(b)
+}
 
-	return strings.TrimSpace(output.String())
+func (b *HardBreakBlock) WriteHtml(io.Writer, RenderOptions) error {
+	return nil
 }
 
 func (b *HardBreakBlock) ToHtml() string {
 	return ""
 }
+
+func (b *DisclaimerBlock) WriteHtml(w io.Writer, _ RenderOptions) error {
+	params := disclaimerTemplateParams{Text: b.Text}
+
+	return disclaimerTemplate.Execute(w, params)
+}
+
+func (b *DisclaimerBlock) ToHtml() string {
+	return blockToHtml(b)
+}
+
+func (b *RawHtmlBlock) WriteHtml(w io.Writer, _ RenderOptions) error {
+	_, err := io.WriteString(w, b.Html)
+	return err
+}
+
+func (b *RawHtmlBlock) ToHtml() string {
+	return blockToHtml(b)
+}
+
+func (b *AsciiTableBlock) WriteHtml(w io.Writer, _ RenderOptions) error {
+	params := asciiTableTemplateParams{Header: b.Header, Rows: b.Rows}
+
+	return asciiTableTemplate.Execute(w, params)
+}
+
+func (b *AsciiTableBlock) ToHtml() string {
+	return blockToHtml(b)
+}
+
+func (b *GroupResourceBlock) WriteHtml(w io.Writer, options RenderOptions) error {
+	params := groupResourceTemplateParams{
+		Url:     b.Url,
+		Label:   b.Url,
+		IsPhoto: b.Kind == GroupResourceKindPhoto,
+	}
+
+	if options.ResolveGroupResource != nil {
+		if resolved, ok := options.ResolveGroupResource(b.Url); ok {
+			params.Url = resolved
+		}
+	}
+
+	return groupResourceTemplate.Execute(w, params)
+}
+
+func (b *GroupResourceBlock) ToHtml() string {
+	return blockToHtml(b)
+}
+
+func (b *ImageReferenceBlock) WriteHtml(w io.Writer, options RenderOptions) error {
+	params := imageReferenceTemplateParams{Name: b.Name()}
+
+	if options.ResolveContentID != nil {
+		if resolved, ok := options.ResolveContentID(b.ContentID); ok {
+			params.Src = resolved
+		}
+	}
+
+	return imageReferenceTemplate.Execute(w, params)
+}
+
+func (b *ImageReferenceBlock) ToHtml() string {
+	return blockToHtml(b)
+}
+
+func (b *PgpBlock) WriteHtml(w io.Writer, _ RenderOptions) error {
+	params := pgpTemplateParams{
+		Kind:  string(b.Kind),
+		Label: b.Kind.Label(),
+		// The armor is written a line at a time since the contents of a
+		// `<pre>` would pick up the indentation of the markup around it.
+		Lines: strings.Split(b.Armor, "\n"),
+	}
+
+	return pgpTemplate.Execute(w, params)
+}
+
+func (b *PgpBlock) ToHtml() string {
+	return blockToHtml(b)
+}
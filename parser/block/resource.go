@@ -0,0 +1,63 @@
+package block
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+var ErrInvalidGroupResourceKind = errors.New("invalid group resource kind")
+
+type GroupResourceKind string
+
+const (
+	GroupResourceKindFile  GroupResourceKind = "File"
+	GroupResourceKindPhoto GroupResourceKind = "Photo"
+)
+
+const (
+	groupResourceRegexUrlIndex   = 1
+	groupResourceRegexGroupIndex = 2
+	groupResourceRegexKindIndex  = 3
+)
+
+// We only match links which are on a line by themselves so that we don't
+// split a sentence into separate paragraphs.
+var groupResourceRegex = regexp.MustCompile(fmt.Sprintf(`(?m)^%[1]s<?(https?://(?:[a-z]+\.)?groups\.yahoo\.com/group/([^/\s<>]+)/(files|photos)(?:/[^\s<>]*)?)>?%[1]s$`, nonNewlineWhitespaceRegexPart))
+
+func groupResourceKindFromPath(path string) GroupResourceKind {
+	switch path {
+	case "files":
+		return GroupResourceKindFile
+	case "photos":
+		return GroupResourceKindPhoto
+	default:
+		panic(fmt.Errorf("%w: %s", ErrInvalidGroupResourceKind, path))
+	}
+}
+
+type GroupResourceBlock struct {
+	Url   string
+	Group string
+	Kind  GroupResourceKind
+}
+
+func (b *GroupResourceBlock) FromText(text string) (ok bool, before, after string) {
+	match := groupResourceRegex.FindStringSubmatchIndex(text)
+	if match == nil {
+		return false, "", ""
+	}
+
+	matchStartIndex, matchEndIndex := match[0], match[1]
+
+	captureGroup := func(number int) string {
+		startIndex, endIndex := indicesForCaptureGroup(match, number)
+		return text[startIndex:endIndex]
+	}
+
+	b.Url = captureGroup(groupResourceRegexUrlIndex)
+	b.Group = captureGroup(groupResourceRegexGroupIndex)
+	b.Kind = groupResourceKindFromPath(captureGroup(groupResourceRegexKindIndex))
+
+	return true, text[:matchStartIndex], text[matchEndIndex:]
+}
@@ -0,0 +1,99 @@
+package block
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGroupResourceFromText(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		wantOk    bool
+		wantUrl   string
+		wantGroup string
+		wantKind  GroupResourceKind
+	}{
+		{
+			name:      "files link",
+			text:      "The notes are here:\nhttp://groups.yahoo.com/group/example/files/notes.txt\n",
+			wantOk:    true,
+			wantUrl:   "http://groups.yahoo.com/group/example/files/notes.txt",
+			wantGroup: "example",
+			wantKind:  GroupResourceKindFile,
+		},
+		{
+			name:      "photos link in angle brackets",
+			text:      "<http://ca.groups.yahoo.com/group/example/photos/album/123>\n",
+			wantOk:    true,
+			wantUrl:   "http://ca.groups.yahoo.com/group/example/photos/album/123",
+			wantGroup: "example",
+			wantKind:  GroupResourceKindPhoto,
+		},
+		{
+			name:   "in a sentence",
+			text:   "See http://groups.yahoo.com/group/example/files/ for the notes.\n",
+			wantOk: false,
+		},
+		{
+			name:   "other group page",
+			text:   "http://groups.yahoo.com/group/example/messages\n",
+			wantOk: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resource := &GroupResourceBlock{}
+
+			ok, _, _ := resource.FromText(test.text)
+			if ok != test.wantOk {
+				t.Fatalf("FromText(%q) ok = %v, want %v", test.text, ok, test.wantOk)
+			}
+
+			if resource.Url != test.wantUrl || resource.Group != test.wantGroup || resource.Kind != test.wantKind {
+				t.Errorf("FromText(%q) = %q, %q, %q, want %q, %q, %q", test.text, resource.Url, resource.Group, resource.Kind, test.wantUrl, test.wantGroup, test.wantKind)
+			}
+		})
+	}
+}
+
+func TestGroupResourceWriteHtml(t *testing.T) {
+	resource := &GroupResourceBlock{
+		Url:   "http://groups.yahoo.com/group/example/files/notes.txt",
+		Group: "example",
+		Kind:  GroupResourceKindFile,
+	}
+
+	resolve := func(url string) (string, bool) {
+		if url == resource.Url {
+			return "/files/example/notes.txt", true
+		}
+
+		return "", false
+	}
+
+	tests := []struct {
+		name     string
+		options  RenderOptions
+		wantHref string
+	}{
+		{"without a mapping", RenderOptions{}, `href="http://groups.yahoo.com/group/example/files/notes.txt"`},
+		{"with a mapping", RenderOptions{ResolveGroupResource: resolve}, `href="/files/example/notes.txt"`},
+		{"not in the mapping", RenderOptions{ResolveGroupResource: func(string) (string, bool) { return "", false }}, `href="http://groups.yahoo.com/group/example/files/notes.txt"`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var output strings.Builder
+
+			if err := resource.WriteHtml(&output, test.options); err != nil {
+				t.Fatal(err)
+			}
+
+			if !strings.Contains(output.String(), test.wantHref) {
+				t.Errorf("rendered HTML doesn't contain %s:\n%s", test.wantHref, output.String())
+			}
+		})
+	}
+}
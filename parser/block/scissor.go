@@ -0,0 +1,73 @@
+package block
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// DefaultScissorMarkers are the markers recognized in scissor lines when
+// `ScissorBlock.Markers` is empty.
+var DefaultScissorMarkers = []string{"8<", ">8", "cut here"}
+
+// ScissorBlock is a line like "--- 8< --- cut here --- 8< ---", which marks
+// where to cut a message, like between the discussion and the patch in a
+// patch email. The line must have at least one dash besides its markers so
+// that prose like "8<" on its own line isn't mistaken for one.
+type ScissorBlock struct {
+	// Markers are the case-insensitive texts which make a line of dashes a
+	// scissor line. This defaults to `DefaultScissorMarkers`.
+	Markers []string
+}
+
+// Blocks are created for every paragraph, so the regex for each set of
+// markers is compiled once and cached.
+var scissorRegexCache sync.Map
+
+func scissorRegexForMarkers(markers []string) *regexp.Regexp {
+	cacheKey := strings.Join(markers, "\x00")
+
+	if cached, ok := scissorRegexCache.Load(cacheKey); ok {
+		return cached.(*regexp.Regexp)
+	}
+
+	markerRegexParts := make([]string, len(markers))
+
+	for i, marker := range markers {
+		markerRegexParts[i] = regexp.QuoteMeta(marker)
+	}
+
+	regex := regexp.MustCompile(fmt.Sprintf(`(?mi)^[-\t ]*(?:(?:%s)[-\t ]*)+$\n?`, strings.Join(markerRegexParts, "|")))
+
+	cached, _ := scissorRegexCache.LoadOrStore(cacheKey, regex)
+
+	return cached.(*regexp.Regexp)
+}
+
+func (b *ScissorBlock) regex() *regexp.Regexp {
+	if len(b.Markers) == 0 {
+		return scissorRegexForMarkers(DefaultScissorMarkers)
+	}
+
+	return scissorRegexForMarkers(b.Markers)
+}
+
+// IsScissorLine returns whether `line` is a scissor line. A line like ">8 ---"
+// starts with a quote marker, so this is checked before quote markers are
+// stripped from it.
+func (b *ScissorBlock) IsScissorLine(line string) bool {
+	return strings.Contains(line, "-") && b.regex().MatchString(line)
+}
+
+func (b *ScissorBlock) FromText(text string) (ok bool, before, after string) {
+	for _, match := range b.regex().FindAllStringIndex(text, -1) {
+		matchStartIndex, matchEndIndex := match[0], match[1]
+
+		if strings.Contains(text[matchStartIndex:matchEndIndex], "-") {
+			return true, text[:matchStartIndex], text[matchEndIndex:]
+		}
+	}
+
+	return false, "", ""
+}
@@ -0,0 +1,76 @@
+package block
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	fieldNameSent = "Sent"
+	fieldNameDate = "Date"
+)
+
+// This matches a meridiem after a time, like " pm", "p.m.", or "PM".
+var meridiemRegex = regexp.MustCompile(`(?i)(\d)\s?([ap])\.?m\b\.?`)
+
+// normalizeMeridiem rewrites each meridiem after a time in `text` as " AM" or
+// " PM", which is the only form `time.Parse` accepts for the "PM" layout.
+func normalizeMeridiem(text string) string {
+	return meridiemRegex.ReplaceAllStringFunc(text, func(meridiem string) string {
+		match := meridiemRegex.FindStringSubmatch(meridiem)
+
+		return match[1] + " " + strings.ToUpper(match[2]) + "M"
+	})
+}
+
+// These are the layouts of the "Sent" and "Date" fields in quoted headers.
+// The first is the long form used by Outlook, like "Monday, January 02, 2006
+// 3:04 PM".
+var headerDateLayouts = []string{
+	"Monday, January 2, 2006 3:04 PM",
+	"Monday, January 2, 2006 3:04:05 PM",
+	"Monday, January 2, 2006 15:04",
+	"January 2, 2006 3:04 PM",
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 -0700 (MST)",
+	"1/2/2006 3:04:05 PM",
+	"1/2/2006 3:04 PM",
+}
+
+// SentTime returns the time in the "Sent" field, or the "Date" field if
+// there's no "Sent" field. Times without a time zone are assumed to be UTC.
+// A time zone name which isn't in `TimeZoneNames` has no known offset, so a
+// field with one isn't read as an exact time.
+func (b MessageHeaderBlock) SentTime() (sent time.Time, ok bool) {
+	for _, name := range []string{fieldNameSent, fieldNameDate} {
+		field, hasField := b.Field(name)
+		if !hasField {
+			continue
+		}
+
+		value := normalizeMeridiem(strings.Join(strings.Fields(field.Value), " "))
+
+		// Outlook may spell out the time zone, like "Monday, January 2, 2006
+		// 3:04 PM Pacific Standard Time".
+		location := time.UTC
+		if rest, zoneName, hasZoneName := splitFullTimeZoneName(value); hasZoneName {
+			zoneLocation, isKnown := timeZoneForName(zoneName)
+			if !isKnown {
+				continue
+			}
+
+			value, location = rest, zoneLocation
+		}
+
+		for _, layout := range headerDateLayouts {
+			if sent, err := time.ParseInLocation(layout, value, location); err == nil {
+				return sent.UTC(), true
+			}
+		}
+	}
+
+	return time.Time{}, false
+}
@@ -0,0 +1,64 @@
+package block
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMessageHeaderSentTime(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		wantTime time.Time
+		wantOk   bool
+	}{
+		{
+			name:     "Outlook long form",
+			text:     "From: Alice\nSent: Monday, January 02, 2006 3:04 PM\nTo: Bob\nSubject: Lunch\n",
+			wantTime: time.Date(2006, time.January, 2, 15, 4, 0, 0, time.UTC),
+			wantOk:   true,
+		},
+		{
+			name:     "lowercase meridiem",
+			text:     "From: Alice\nSent: Monday, January 2, 2006 3:04 p.m.\nSubject: Lunch\n",
+			wantTime: time.Date(2006, time.January, 2, 15, 4, 0, 0, time.UTC),
+			wantOk:   true,
+		},
+		{
+			name:     "lowercase meridiem without dots",
+			text:     "From: Alice\nSent: Monday, January 2, 2006 3:04 pm\nSubject: Lunch\n",
+			wantTime: time.Date(2006, time.January, 2, 15, 4, 0, 0, time.UTC),
+			wantOk:   true,
+		},
+		{
+			name:     "spelled out time zone",
+			text:     "From: Alice\nSent: Monday, January 2, 2006 3:04 PM Eastern Standard Time\nSubject: Lunch\n",
+			wantTime: time.Date(2006, time.January, 2, 20, 4, 0, 0, time.UTC),
+			wantOk:   true,
+		},
+		{
+			name:     "Date field",
+			text:     "From: Alice\nDate: Mon, 2 Jan 2006 15:04:05 -0500\nSubject: Lunch\n",
+			wantTime: time.Date(2006, time.January, 2, 20, 4, 5, 0, time.UTC),
+			wantOk:   true,
+		},
+		{
+			name:   "unparseable date",
+			text:   "From: Alice\nSent: sometime last week\nSubject: Lunch\n",
+			wantOk: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sent, ok := parseHeader(t, test.text).SentTime()
+			if ok != test.wantOk || !sent.Equal(test.wantTime) {
+				t.Errorf("SentTime() of %q = %v, %v, want %v, %v", test.text, sent, ok, test.wantTime, test.wantOk)
+			}
+
+			if ok, _, _ := (&AttributionBlock{}).FromText(test.text); ok {
+				t.Errorf("header %q is also an attribution", test.text)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package block
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const fieldNameSubject = "Subject"
+
+var (
+	// Some mailers number replies instead of stacking prefixes, like "Re[2]:"
+	// or "Re(3):".
+	subjectReplyPrefixRegex   = regexp.MustCompile(`(?i)^re\s*(?:[\[(]\s*(\d+)\s*[\])])?\s*:\s*`)
+	subjectForwardPrefixRegex = regexp.MustCompile(`(?i)^fwd?\s*:\s*`)
+	subjectWasSuffixRegex     = regexp.MustCompile(`(?i)\s*[(\[]\s*was\s*:\s*([^()\[\]]*?)\s*[)\]]\s*$`)
+)
+
+type Subject struct {
+	Base      string
+	IsReply   bool
+	IsForward bool
+
+	// ReplyDepth counts the reply prefixes, so "Re: Re:" and "Re[2]:" are
+	// both 2.
+	ReplyDepth int
+
+	// PrefixCount is the number of reply and forward prefixes which were
+	// stripped, so "Re: Fwd: Re:" is 3 and "Re[2]:" is 1.
+	PrefixCount int
+}
+
+func NormalizeSubject(subject string) Subject {
+	var normalized Subject
+
+	remaining := strings.TrimSpace(subject)
+
+	for {
+		if match := subjectReplyPrefixRegex.FindStringSubmatchIndex(remaining); match != nil {
+			normalized.IsReply = true
+			normalized.ReplyDepth += replyPrefixDepth(remaining, match)
+			normalized.PrefixCount++
+			remaining = remaining[match[1]:]
+		} else if match := subjectForwardPrefixRegex.FindStringIndex(remaining); match != nil {
+			normalized.IsForward = true
+			normalized.PrefixCount++
+			remaining = remaining[match[1]:]
+		} else {
+			break
+		}
+	}
+
+	normalized.Base = strings.TrimSpace(remaining)
+
+	return normalized
+}
+
+func replyPrefixDepth(text string, match []int) int {
+	if match[2] < 0 {
+		return 1
+	}
+
+	depth, err := strconv.Atoi(text[match[2]:match[3]])
+	if err != nil || depth < 1 {
+		return 1
+	}
+
+	return depth
+}
+
+// SplitWasSubject splits a subject which notes that the thread was renamed,
+// like "New topic (was: Re: Old topic)", into the current subject and the one
+// it replaced. It returns false if there is no "(was: ...)" suffix.
+func SplitWasSubject(subject string) (current, previous string, ok bool) {
+	match := subjectWasSuffixRegex.FindStringSubmatchIndex(subject)
+	if match == nil {
+		return subject, "", false
+	}
+
+	current = strings.TrimSpace(subject[:match[0]])
+	previous = subject[match[2]:match[3]]
+
+	if current == "" || previous == "" {
+		return subject, "", false
+	}
+
+	return current, previous, true
+}
+
+func (b MessageHeaderBlock) Subject() (subject Subject, ok bool) {
+	field, ok := b.Field(fieldNameSubject)
+	if !ok {
+		return Subject{}, false
+	}
+
+	return NormalizeSubject(field.Value), true
+}
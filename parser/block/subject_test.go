@@ -0,0 +1,75 @@
+package block
+
+import "testing"
+
+func TestNormalizeSubject(t *testing.T) {
+	tests := []struct {
+		subject string
+		want    Subject
+	}{
+		{"Lunch", Subject{Base: "Lunch"}},
+		{"Re: Lunch", Subject{Base: "Lunch", IsReply: true, ReplyDepth: 1, PrefixCount: 1}},
+		{"RE: re: Lunch", Subject{Base: "Lunch", IsReply: true, ReplyDepth: 2, PrefixCount: 2}},
+		{"Fwd: Lunch", Subject{Base: "Lunch", IsForward: true, PrefixCount: 1}},
+		{"FW: Fw:Lunch", Subject{Base: "Lunch", IsForward: true, PrefixCount: 2}},
+		{"Re: Re: Fwd: Lunch", Subject{Base: "Lunch", IsReply: true, IsForward: true, ReplyDepth: 2, PrefixCount: 3}},
+		{"  Re :  Lunch  ", Subject{Base: "Lunch", IsReply: true, ReplyDepth: 1, PrefixCount: 1}},
+		{"Reply: Lunch", Subject{Base: "Reply: Lunch"}},
+		{"Re[2]: Lunch", Subject{Base: "Lunch", IsReply: true, ReplyDepth: 2, PrefixCount: 1}},
+		{"Re(3): Lunch", Subject{Base: "Lunch", IsReply: true, ReplyDepth: 3, PrefixCount: 1}},
+		{"RE [2] : Lunch", Subject{Base: "Lunch", IsReply: true, ReplyDepth: 2, PrefixCount: 1}},
+		{"Re[2]: Re: Lunch", Subject{Base: "Lunch", IsReply: true, ReplyDepth: 3, PrefixCount: 2}},
+		{"Re[0]: Lunch", Subject{Base: "Lunch", IsReply: true, ReplyDepth: 1, PrefixCount: 1}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.subject, func(t *testing.T) {
+			if got := NormalizeSubject(test.subject); got != test.want {
+				t.Errorf("NormalizeSubject(%q) = %+v, want %+v", test.subject, got, test.want)
+			}
+		})
+	}
+}
+
+func TestMessageHeaderSubject(t *testing.T) {
+	header := MessageHeaderBlock{{Name: "From", Value: "Alice"}, {Name: "Subject", Value: "Re: Fwd: Lunch"}}
+
+	subject, ok := header.Subject()
+	if !ok {
+		t.Fatal("Subject() found no subject")
+	}
+
+	if want := (Subject{Base: "Lunch", IsReply: true, IsForward: true, ReplyDepth: 1, PrefixCount: 2}); subject != want {
+		t.Errorf("Subject() = %+v, want %+v", subject, want)
+	}
+
+	if _, ok := (MessageHeaderBlock{{Name: "From", Value: "Alice"}}).Subject(); ok {
+		t.Error("Subject() found a subject in a header without one")
+	}
+}
+
+func TestSplitWasSubject(t *testing.T) {
+	tests := []struct {
+		subject      string
+		wantCurrent  string
+		wantPrevious string
+		wantOk       bool
+	}{
+		{"New topic (was: Re: Old topic)", "New topic", "Re: Old topic", true},
+		{"New topic [WAS: Old topic]", "New topic", "Old topic", true},
+		{"New topic (was:Old topic)  ", "New topic", "Old topic", true},
+		{"Re: Lunch", "Re: Lunch", "", false},
+		{"(was: Old topic)", "(was: Old topic)", "", false},
+		{"New topic (was: )", "New topic (was: )", "", false},
+		{"It was: great (really)", "It was: great (really)", "", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.subject, func(t *testing.T) {
+			current, previous, ok := SplitWasSubject(test.subject)
+			if current != test.wantCurrent || previous != test.wantPrevious || ok != test.wantOk {
+				t.Errorf("SplitWasSubject(%q) = %q, %q, %v, want %q, %q, %v", test.subject, current, previous, ok, test.wantCurrent, test.wantPrevious, test.wantOk)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package block
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// A separator is a line of dashes broken up by "+" or "|", like
+	// "+-----+----+" or "-----|----". A line of only dashes is a divider.
+	asciiTableSeparatorRegex = regexp.MustCompile(fmt.Sprintf(`^%[1]s[|+]?(?:%[1]s[-=:]+%[1]s[|+])+(?:%[1]s[-=:]+)?%[1]s$`, nonNewlineWhitespaceRegexPart))
+	asciiTableRowRegex       = regexp.MustCompile(`^[^|]*\|.*$`)
+)
+
+const minAsciiTableColumns = 2
+
+// AsciiTableBlock is a table drawn with "|" between cells and lines like
+// "+---+---+" between rows. A separator directly after the first row makes it
+// a header row.
+type AsciiTableBlock struct {
+	Header []string
+	Rows   [][]string
+}
+
+type asciiTableLine struct {
+	isSeparator bool
+	cells       []string
+}
+
+func splitAsciiTableRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	line = strings.TrimSuffix(line, "|")
+
+	cells := strings.Split(line, "|")
+
+	for i, cell := range cells {
+		cells[i] = strings.TrimSpace(cell)
+	}
+
+	return cells
+}
+
+func parseAsciiTableLine(line string) (tableLine asciiTableLine, ok bool) {
+	if asciiTableSeparatorRegex.MatchString(line) {
+		return asciiTableLine{isSeparator: true}, true
+	}
+
+	if !asciiTableRowRegex.MatchString(line) {
+		return asciiTableLine{}, false
+	}
+
+	cells := splitAsciiTableRow(line)
+	if len(cells) < minAsciiTableColumns {
+		return asciiTableLine{}, false
+	}
+
+	return asciiTableLine{cells: cells}, true
+}
+
+// fromLines fills in the block from a run of table lines, returning false if
+// they don't look enough like a table. Pipes alone are common in prose and
+// signatures, so a table needs at least one separator and two rows.
+func (b *AsciiTableBlock) fromLines(lines []asciiTableLine) bool {
+	var (
+		rows         [][]string
+		hasSeparator bool
+		hasHeader    bool
+	)
+
+	for i, line := range lines {
+		if line.isSeparator {
+			hasSeparator = true
+			continue
+		}
+
+		if len(rows) == 0 && i+1 < len(lines) && lines[i+1].isSeparator {
+			hasHeader = true
+		}
+
+		rows = append(rows, line.cells)
+	}
+
+	if !hasSeparator || len(rows) < 2 {
+		return false
+	}
+
+	if hasHeader {
+		b.Header, rows = rows[0], rows[1:]
+	}
+
+	b.Rows = rows
+
+	return true
+}
+
+func (b *AsciiTableBlock) FromText(text string) (ok bool, before, after string) {
+	lineStartIndex := 0
+	runStartIndex := -1
+	var run []asciiTableLine
+
+	endRun := func(runEndIndex int) bool {
+		if runStartIndex < 0 {
+			return false
+		}
+
+		if b.fromLines(run) {
+			before, after = text[:runStartIndex], text[runEndIndex:]
+			return true
+		}
+
+		runStartIndex, run = -1, nil
+
+		return false
+	}
+
+	for lineStartIndex <= len(text) {
+		lineEndIndex := strings.IndexByte(text[lineStartIndex:], '\n')
+		nextLineStartIndex := len(text) + 1
+
+		if lineEndIndex < 0 {
+			lineEndIndex = len(text)
+		} else {
+			lineEndIndex += lineStartIndex
+			nextLineStartIndex = lineEndIndex + 1
+		}
+
+		if tableLine, isTableLine := parseAsciiTableLine(text[lineStartIndex:lineEndIndex]); isTableLine {
+			if runStartIndex < 0 {
+				runStartIndex = lineStartIndex
+			}
+
+			run = append(run, tableLine)
+		} else if endRun(lineStartIndex) {
+			return true, before, after
+		}
+
+		lineStartIndex = nextLineStartIndex
+	}
+
+	if endRun(len(text)) {
+		return true, before, after
+	}
+
+	return false, "", ""
+}
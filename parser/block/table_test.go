@@ -0,0 +1,103 @@
+package block
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAsciiTableFromText(t *testing.T) {
+	tests := []struct {
+		name       string
+		text       string
+		wantOk     bool
+		wantHeader []string
+		wantRows   [][]string
+		wantBefore string
+		wantAfter  string
+	}{
+		{
+			name: "bordered",
+			text: "Results:\n" +
+				"+-------+-----+\n" +
+				"| Name  | Age |\n" +
+				"+=======+=====+\n" +
+				"| Alice | 30  |\n" +
+				"| Bob   | 25  |\n" +
+				"+-------+-----+\n" +
+				"That's all.\n",
+			wantOk:     true,
+			wantHeader: []string{"Name", "Age"},
+			wantRows:   [][]string{{"Alice", "30"}, {"Bob", "25"}},
+			wantBefore: "Results:\n",
+			wantAfter:  "That's all.\n",
+		},
+		{
+			name: "pipe-delimited",
+			text: "Name  | Age\n" +
+				"------|----\n" +
+				"Alice | 30\n" +
+				"Bob   | 25\n",
+			wantOk:     true,
+			wantHeader: []string{"Name", "Age"},
+			wantRows:   [][]string{{"Alice", "30"}, {"Bob", "25"}},
+		},
+		{
+			name: "without a header",
+			text: "+-------+-----+\n" +
+				"| Alice | 30  |\n" +
+				"| Bob   | 25  |\n" +
+				"+-------+-----+\n",
+			wantOk:   true,
+			wantRows: [][]string{{"Alice", "30"}, {"Bob", "25"}},
+		},
+		{
+			name:   "pipes without a separator",
+			text:   "Alice | Bob\nCarol | Dave\n",
+			wantOk: false,
+		},
+		{
+			name:   "one row",
+			text:   "Name | Age\n-----|----\n",
+			wantOk: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			table := &AsciiTableBlock{}
+
+			ok, before, after := table.FromText(test.text)
+			if ok != test.wantOk {
+				t.Fatalf("FromText(%q) ok = %v, want %v", test.text, ok, test.wantOk)
+			}
+
+			if !ok {
+				return
+			}
+
+			if !reflect.DeepEqual(table.Header, test.wantHeader) || !reflect.DeepEqual(table.Rows, test.wantRows) {
+				t.Errorf("FromText(%q) Header, Rows = %q, %q, want %q, %q", test.text, table.Header, table.Rows, test.wantHeader, test.wantRows)
+			}
+
+			if before != test.wantBefore || after != test.wantAfter {
+				t.Errorf("FromText(%q) = %q, %q, want %q, %q", test.text, before, after, test.wantBefore, test.wantAfter)
+			}
+		})
+	}
+}
+
+func TestAsciiTableToHtml(t *testing.T) {
+	table := &AsciiTableBlock{
+		Header: []string{"Name", "Age"},
+		Rows:   [][]string{{"Alice", "30"}, {"Bob <b>", "25"}},
+	}
+
+	rendered := table.ToHtml()
+
+	for _, want := range []string{"<table", `<th scope="col">Name</th>`, "<td>Alice</td>", "<td>Bob &lt;b&gt;</td>"} {
+		if !strings.Contains(rendered, want) {
+			t.Errorf("rendered HTML doesn't contain %s:\n%s", want, rendered)
+		}
+	}
+}
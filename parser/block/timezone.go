@@ -0,0 +1,146 @@
+package block
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// TimeZoneNames maps time zone names which are spelled out, like "Pacific
+// Standard Time", to their locations. Names which aren't in the map are kept
+// as the time zone name of an attribution, but its offset is unknown, so the
+// time isn't an exact instant. See `AttributionBlock.UnknownOffset`.
+var TimeZoneNames = map[string]*time.Location{
+	"Hawaii Standard Time":         time.FixedZone("HST", -10*60*60),
+	"Alaska Standard Time":         time.FixedZone("AKST", -9*60*60),
+	"Alaska Daylight Time":         time.FixedZone("AKDT", -8*60*60),
+	"Pacific Standard Time":        time.FixedZone("PST", -8*60*60),
+	"Pacific Daylight Time":        time.FixedZone("PDT", -7*60*60),
+	"Mountain Standard Time":       time.FixedZone("MST", -7*60*60),
+	"Mountain Daylight Time":       time.FixedZone("MDT", -6*60*60),
+	"Central Standard Time":        time.FixedZone("CST", -6*60*60),
+	"Central Daylight Time":        time.FixedZone("CDT", -5*60*60),
+	"Eastern Standard Time":        time.FixedZone("EST", -5*60*60),
+	"Eastern Daylight Time":        time.FixedZone("EDT", -4*60*60),
+	"Atlantic Standard Time":       time.FixedZone("AST", -4*60*60),
+	"Atlantic Daylight Time":       time.FixedZone("ADT", -3*60*60),
+	"Greenwich Mean Time":          time.FixedZone("GMT", 0),
+	"Coordinated Universal Time":   time.FixedZone("UTC", 0),
+	"British Summer Time":          time.FixedZone("BST", 1*60*60),
+	"Central European Time":        time.FixedZone("CET", 1*60*60),
+	"Central European Summer Time": time.FixedZone("CEST", 2*60*60),
+}
+
+// A spelled-out time zone name is a few capitalized words ending in "Time",
+// like "Eastern Daylight Time". It may be in parentheses after the time.
+const fullTimeZoneNameRegexPart = `(?:[A-Z][a-z]+(?:-[A-Z][a-z]+)?\s+){1,3}Time`
+
+var (
+	fullTimeZoneNameRegex        = regexp.MustCompile(fmt.Sprintf(`^(.*?),?\s+\(?(%s)\)?$`, fullTimeZoneNameRegexPart))
+	fullTimeZoneNameClockLayouts = []string{"3:04 PM", "3:04:05 PM", "15:04", "15:04:05"}
+
+	parenTimeZoneNameRegex        = regexp.MustCompile(`^(.*?)\s+\(([A-Z]{2,5})\)$`)
+	parenTimeZoneNameClockLayouts = []string{"15:04:05", "15:04"}
+)
+
+// timeZoneForName returns the location for a spelled-out time zone name, and
+// whether it's in `TimeZoneNames`. When it isn't, this returns a zone with
+// the name and no offset, so that the name is kept along with the clock time
+// as written.
+func timeZoneForName(name string) (location *time.Location, isKnown bool) {
+	name = strings.Join(strings.Fields(name), " ")
+
+	if location, ok := TimeZoneNames[name]; ok {
+		return location, true
+	}
+
+	return time.FixedZone(name, 0), false
+}
+
+// splitFullTimeZoneName splits a spelled-out time zone name off the end of
+// `text`, like in "3:04 PM Pacific Standard Time".
+func splitFullTimeZoneName(text string) (rest, name string, ok bool) {
+	match := fullTimeZoneNameRegex.FindStringSubmatch(text)
+	if match == nil {
+		return text, "", false
+	}
+
+	return match[1], match[2], true
+}
+
+// hasUnknownFullTimeZoneName returns whether `text` ends with a spelled-out
+// time zone name which isn't in `TimeZoneNames`.
+func hasUnknownFullTimeZoneName(text string) bool {
+	_, name, ok := splitFullTimeZoneName(text)
+	if !ok {
+		return false
+	}
+
+	_, isKnown := timeZoneForName(name)
+
+	return !isKnown
+}
+
+// parseClockWithFullTimeZoneName parses a time of day followed by a
+// spelled-out time zone name.
+func parseClockWithFullTimeZoneName(text string) (time.Time, error) {
+	clock, name, ok := splitFullTimeZoneName(text)
+	if !ok {
+		return time.Time{}, fmt.Errorf("%w: %q", ErrInvalidTimeFormat, text)
+	}
+
+	location, _ := timeZoneForName(name)
+
+	clock = normalizeMeridiem(clock)
+
+	var err error
+
+	for _, layout := range fullTimeZoneNameClockLayouts {
+		var parsed time.Time
+
+		if parsed, err = time.ParseInLocation(layout, clock, location); err == nil {
+			return parsed, nil
+		}
+	}
+
+	return time.Time{}, err
+}
+
+// hasUnknownParenTimeZoneName returns whether `text` ends with a time zone
+// abbreviation in parentheses with no numeric offset to go with it. Only "UTC"
+// and "GMT" are known to have no offset; abbreviations like "EST" are
+// ambiguous on their own.
+func hasUnknownParenTimeZoneName(text string) bool {
+	match := parenTimeZoneNameRegex.FindStringSubmatch(text)
+	if match == nil {
+		return false
+	}
+
+	return match[2] != "UTC" && match[2] != "GMT"
+}
+
+// parseClockWithParenTimeZoneName parses a time of day followed by a time
+// zone abbreviation in parentheses, like "15:04:05 (UTC)". The abbreviation is
+// kept as the name of a zone with no offset, so the clock time is kept as
+// written. See `hasUnknownParenTimeZoneName`.
+func parseClockWithParenTimeZoneName(text string) (time.Time, error) {
+	match := parenTimeZoneNameRegex.FindStringSubmatch(text)
+	if match == nil {
+		return time.Time{}, fmt.Errorf("%w: %q", ErrInvalidTimeFormat, text)
+	}
+
+	location := time.FixedZone(match[2], 0)
+
+	var err error
+
+	for _, layout := range parenTimeZoneNameClockLayouts {
+		var parsed time.Time
+
+		if parsed, err = time.ParseInLocation(layout, match[1], location); err == nil {
+			return parsed, nil
+		}
+	}
+
+	return time.Time{}, err
+}
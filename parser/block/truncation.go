@@ -0,0 +1,64 @@
+package block
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// DefaultTruncationMarkers are the markers recognized in truncation notices
+// when `TruncationBlock.Markers` is empty.
+var DefaultTruncationMarkers = []string{"Message truncated", "Message was truncated", "Message clipped", "Truncated"}
+
+// TruncationBlock is a notice like "----- Message truncated -----" or
+// "[Message truncated]", which digests and size-limited archives left where
+// they cut off the rest of a message. Like a scissor line, the marker must be
+// set off by dashes or brackets so that prose isn't mistaken for one.
+type TruncationBlock struct {
+	// Markers are the case-insensitive texts which make a line a truncation
+	// notice. This defaults to `DefaultTruncationMarkers`.
+	Markers []string
+}
+
+var truncationRegexCache sync.Map
+
+func truncationRegexForMarkers(markers []string) *regexp.Regexp {
+	cacheKey := strings.Join(markers, "\x00")
+
+	if cached, ok := truncationRegexCache.Load(cacheKey); ok {
+		return cached.(*regexp.Regexp)
+	}
+
+	markerRegexParts := make([]string, len(markers))
+
+	for i, marker := range markers {
+		markerRegexParts[i] = regexp.QuoteMeta(marker)
+	}
+
+	markersRegexPart := strings.Join(markerRegexParts, "|")
+
+	regex := regexp.MustCompile(fmt.Sprintf(
+		`(?mi)^%[1]s(?:\[%[1]s(?:%[2]s)\.*%[1]s\]|-+%[1]s(?:%[2]s)\.*%[1]s-+)%[1]s$\n?`,
+		nonNewlineWhitespaceRegexPart,
+		markersRegexPart,
+	))
+
+	cached, _ := truncationRegexCache.LoadOrStore(cacheKey, regex)
+
+	return cached.(*regexp.Regexp)
+}
+
+func (b *TruncationBlock) FromText(text string) (ok bool, before, after string) {
+	markers := b.Markers
+	if len(markers) == 0 {
+		markers = DefaultTruncationMarkers
+	}
+
+	match := truncationRegexForMarkers(markers).FindStringIndex(text)
+	if match == nil {
+		return false, "", ""
+	}
+
+	return true, text[:match[0]], text[match[1]:]
+}
@@ -0,0 +1,35 @@
+package block
+
+import "testing"
+
+func TestTruncationFromText(t *testing.T) {
+	tests := []struct {
+		name       string
+		text       string
+		markers    []string
+		wantOk     bool
+		wantBefore string
+		wantAfter  string
+	}{
+		{"bracketed", "Above.\n[Message truncated]\nBelow.\n", nil, true, "Above.\n", "Below.\n"},
+		{"dashed", "Above.\n----- Message truncated -----\nBelow.\n", nil, true, "Above.\n", "Below.\n"},
+		{"with an ellipsis at the end", "Above.\n  [ message was truncated... ]  ", nil, true, "Above.\n", ""},
+		{"custom marker", "Above.\n-- Nachricht gekürzt --\nBelow.\n", []string{"Nachricht gekürzt"}, true, "Above.\n", "Below.\n"},
+		{"default marker with custom markers", "Above.\n[Message truncated]\nBelow.\n", []string{"Nachricht gekürzt"}, false, "", ""},
+		{"in a sentence", "The message truncated my reply.\n", nil, false, "", ""},
+		{"unbalanced", "Above.\n[Message truncated -----\nBelow.\n", nil, false, "", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ok, before, after := (&TruncationBlock{Markers: test.markers}).FromText(test.text)
+			if ok != test.wantOk {
+				t.Fatalf("FromText(%q) ok = %v, want %v", test.text, ok, test.wantOk)
+			}
+
+			if before != test.wantBefore || after != test.wantAfter {
+				t.Errorf("FromText(%q) = %q, %q, want %q, %q", test.text, before, after, test.wantBefore, test.wantAfter)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package block
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// validationReferenceTime is formatted with each date and time format to check
+// that the format's regex matches what its layout produces.
+var validationReferenceTime = time.Date(2006, time.January, 2, 15, 4, 5, 0, time.UTC)
+
+// ValidationErrors is every problem found by `Validate`, one per failing
+// attribution regex or format.
+type ValidationErrors []error
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+func validateFormatRegex(regex *regexp.Regexp, layout string) error {
+	if regex.NumSubexp() != 1 {
+		return fmt.Errorf("regex has %d capture groups, expected 1", regex.NumSubexp())
+	}
+
+	example := validationReferenceTime.Format(layout)
+	if match := regex.FindStringIndex(example); match == nil || match[0] != 0 || match[1] != len(example) {
+		return fmt.Errorf("regex doesn't match %q", example)
+	}
+
+	return nil
+}
+
+func (r *attributionRegex) validate() error {
+	if r.regex == nil {
+		return fmt.Errorf("%w %s: not compiled", ErrInvalidAttributionRegex, r.Name)
+	}
+
+	if strings.Contains(r.regex.String(), "%!") {
+		return fmt.Errorf("%w %s: template doesn't match its parts", ErrInvalidAttributionRegex, r.Name)
+	}
+
+	captures := make(map[attributionRegexCapture]bool)
+	expectedCaptureGroups := 0
+
+	for _, part := range r.Parts {
+		capture, isCapture := part.(attributionRegexCapture)
+		if !isCapture {
+			continue
+		}
+
+		// A kind may be captured more than once, like in each branch of an
+		// alternation, and each one has capture groups of its own.
+		captures[capture] = true
+		expectedCaptureGroups += len(r.matchersOfKind(capture))
+	}
+
+	formatCounts := []struct {
+		kind  attributionRegexCapture
+		count int
+	}{
+		{attributionRegexCaptureName, len(r.NameFormats)},
+		{attributionRegexCaptureDate, len(r.DateFormats)},
+		{attributionRegexCaptureRelativeDate, len(r.RelativeDateFormats)},
+		{attributionRegexCaptureTime, len(r.TimeFormats)},
+	}
+
+	for _, formatCount := range formatCounts {
+		switch {
+		case captures[formatCount.kind] && formatCount.count == 0:
+			return fmt.Errorf("%w %s: has a %s capture but no formats for it", ErrInvalidAttributionRegex, r.Name, formatCount.kind)
+		case !captures[formatCount.kind] && formatCount.count > 0:
+			return fmt.Errorf("%w %s: has %s formats but no capture for them", ErrInvalidAttributionRegex, r.Name, formatCount.kind)
+		}
+	}
+
+	if !captures[attributionRegexCaptureName] {
+		return fmt.Errorf("%w %s: has no %s capture", ErrInvalidAttributionRegex, r.Name, attributionRegexCaptureName)
+	}
+
+	if r.regex.NumSubexp() != expectedCaptureGroups {
+		return fmt.Errorf("%w %s: regex has %d capture groups, but its formats have %d", ErrInvalidAttributionRegex, r.Name, r.regex.NumSubexp(), expectedCaptureGroups)
+	}
+
+	return nil
+}
+
+// Validate checks that every built-in attribution regex and date and time
+// format is consistent, so that the capture groups of a match line up with the
+// formats they're parsed with. It returns a `ValidationErrors` describing each
+// problem, or nil if there are none.
+func Validate() error {
+	var errs ValidationErrors
+
+	for i := range attributionRegexes {
+		if err := attributionRegexes[i].validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	for _, format := range knownNameFormats() {
+		if format.Regex().NumSubexp() != 1 {
+			errs = append(errs, fmt.Errorf("%w: %s has %d capture groups, expected 1", ErrInvalidNameFormat, format, format.Regex().NumSubexp()))
+		}
+	}
+
+	for _, format := range allDateFormats() {
+		if err := validateFormatRegex(format.Regex(), format.FormatString()); err != nil {
+			errs = append(errs, fmt.Errorf("%w: %s: %v", ErrInvalidDateFormat, format, err))
+		}
+	}
+
+	for _, format := range allTimeFormats() {
+		if err := validateFormatRegex(format.Regex(), format.FormatString()); err != nil {
+			errs = append(errs, fmt.Errorf("%w: %s: %v", ErrInvalidTimeFormat, format, err))
+		}
+	}
+
+	for _, format := range allRelativeDateFormats() {
+		if format.Regex().NumSubexp() != 1 {
+			errs = append(errs, fmt.Errorf("%w: %s has %d capture groups, expected 1", ErrInvalidRelativeDateFormat, format, format.Regex().NumSubexp()))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package body
+
+import (
+	"fmt"
+	"github.com/acearchive/yg-render/block"
+	"html"
+	"io"
+	"regexp"
+)
+
+var wordRegex = regexp.MustCompile(`\S+`)
+
+func findEmoticon(emoticons []block.Emoticon, word string) (emoticon block.Emoticon, ok bool) {
+	for _, emoticon := range emoticons {
+		if emoticon.Text == word {
+			return emoticon, true
+		}
+	}
+
+	return block.Emoticon{}, false
+}
+
+// Emoticons are only recognized when they make up a whole word so that we
+// don't match things like URLs or source code. The text around them is
+// written with `writeText`.
+func writeTextWithEmoticons(w io.Writer, text string, emoticons []block.Emoticon, writeText func(w io.Writer, text string) error) error {
+	lastIndex := 0
+
+	for _, match := range wordRegex.FindAllStringIndex(text, -1) {
+		wordStartIndex, wordEndIndex := match[0], match[1]
+
+		emoticon, ok := findEmoticon(emoticons, text[wordStartIndex:wordEndIndex])
+		if !ok {
+			continue
+		}
+
+		if err := writeText(w, text[lastIndex:wordStartIndex]); err != nil {
+			return err
+		}
+
+		if _, err := fmt.Fprintf(w, `<span class="emoticon" role="img" aria-label="%s">%s</span>`, html.EscapeString(emoticon.Label), html.EscapeString(emoticon.Text)); err != nil {
+			return err
+		}
+
+		lastIndex = wordEndIndex
+	}
+
+	return writeText(w, text[lastIndex:])
+}
@@ -0,0 +1,42 @@
+package body
+
+import (
+	"github.com/acearchive/yg-render/block"
+	"strings"
+	"testing"
+)
+
+func TestRenderEmoticons(t *testing.T) {
+	tests := []struct {
+		name         string
+		text         string
+		want         string
+		wantEmoticon bool
+	}{
+		{"smile", "Thanks :-)\n", `Thanks <span class="emoticon" role="img" aria-label="smile">:-)</span>`, true},
+		{"wink", "See you ;)\n", `See you <span class="emoticon" role="img" aria-label="wink">;)</span>`, true},
+		{"frown", ":( too bad\n", `<span class="emoticon" role="img" aria-label="frown">:(</span> too bad`, true},
+		{"heart", "<3 it\n", `<span class="emoticon" role="img" aria-label="heart">&lt;3</span> it`, true},
+		{"in a word", "Call foo(:) here\n", "Call foo(:) here", false},
+		{"in a link", "See http://example.com/:-) here\n", "http://example.com/:-)", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tokens := tokenizeForTest(t, NewDefaultTokenizer(), test.text)
+
+			rendered := RenderWithOptions(tokens, block.RenderOptions{Emoticons: block.DefaultEmoticons})
+			if !strings.Contains(rendered, test.want) {
+				t.Errorf("rendered HTML of %q doesn't contain %q:\n%s", test.text, test.want, rendered)
+			}
+
+			if hasEmoticon := strings.Contains(rendered, `class="emoticon"`); hasEmoticon != test.wantEmoticon {
+				t.Errorf("rendered HTML of %q has an emoticon = %v, want %v:\n%s", test.text, hasEmoticon, test.wantEmoticon, rendered)
+			}
+		})
+	}
+
+	if rendered := Render(tokenizeForTest(t, NewDefaultTokenizer(), "Thanks :-)\n")); strings.Contains(rendered, "emoticon") {
+		t.Errorf("emoticons are marked up without Emoticons:\n%s", rendered)
+	}
+}
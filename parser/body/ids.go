@@ -0,0 +1,83 @@
+package body
+
+import (
+	"fmt"
+	"github.com/acearchive/yg-render/block"
+	"html"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// blockIDs numbers the blocks of a message body in the order they're
+// rendered, so the same body always gets the same IDs.
+type blockIDs struct {
+	prefix string
+	count  int
+}
+
+func (ids *blockIDs) next() string {
+	ids.count++
+	return fmt.Sprintf("%s-block-%d", ids.prefix, ids.count)
+}
+
+// takesBlockID returns whether a token starts a block which should get an ID.
+// Raw HTML is left alone since there's no telling what its first tag is.
+func takesBlockID(token Token) bool {
+	switch concreteToken := token.(type) {
+	case TextToken, EndParagraphToken, EndQuoteToken, replyTreeNodesToken:
+		return false
+	case BlockToken:
+		switch concreteToken.Block.(type) {
+		case *block.RawHtmlBlock, *block.HardBreakBlock:
+			return false
+		}
+	}
+
+	return token.TagType() != TagTypeClose
+}
+
+// openingTagEnd returns the index of the ">" which ends the first tag in
+// `markup`, or -1 if `markup` doesn't start with a tag. Attribute values are
+// escaped by the templates, so the first ">" ends the tag.
+func openingTagEnd(markup string) int {
+	if !strings.HasPrefix(markup, "<") {
+		return -1
+	}
+
+	if char, _ := utf8.DecodeRuneInString(markup[1:]); !unicode.IsLetter(char) {
+		return -1
+	}
+
+	return strings.IndexByte(markup, '>')
+}
+
+// writeTokenWithID writes a token with the next ID added to its outermost tag
+// when `ids` is set and the token starts a block.
+func writeTokenWithID(w io.Writer, token Token, ids *blockIDs, options block.RenderOptions) error {
+	if ids == nil || !takesBlockID(token) {
+		return token.WriteHtml(w, options)
+	}
+
+	var markup strings.Builder
+
+	if err := token.WriteHtml(&markup, options); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, withBlockID(markup.String(), ids))
+
+	return err
+}
+
+// withBlockID adds the next ID in `ids` to the first tag in `markup`, if it
+// starts with one.
+func withBlockID(markup string, ids *blockIDs) string {
+	tagEndIndex := openingTagEnd(markup)
+	if tagEndIndex < 0 {
+		return markup
+	}
+
+	return fmt.Sprintf(`%s id="%s"%s`, markup[:tagEndIndex], html.EscapeString(ids.next()), markup[tagEndIndex:])
+}
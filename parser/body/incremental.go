@@ -0,0 +1,105 @@
+package body
+
+import "strings"
+
+// IncrementalTokens is the result of tokenizing a body with
+// `TokenizeIncremental`, which can be extended with `Append` without
+// tokenizing all of it again. This is for live previews, where the body is
+// re-rendered as it's typed.
+type IncrementalTokens struct {
+	Text   string
+	Tokens []Token
+
+	// The tokens of `Text[:stableTextLength]` are `Tokens[:stableTokenCount]`
+	// and can't be changed by appending text, because that text ends with a
+	// blank, unquoted line that no paragraph is carried over past.
+	stableTextLength int
+	stableTokenCount int
+}
+
+// TokenizeIncremental tokenizes `text` the same way as `Tokenize`, but also
+// remembers where the last block which can't be changed by appending text
+// ends.
+func (t *Tokenizer) TokenizeIncremental(text string) IncrementalTokens {
+	return t.tokenizeAfterStablePrefix(IncrementalTokens{}, text)
+}
+
+// Append returns the tokens of `previous.Text` followed by `suffix`, which are
+// the same as tokenizing all of it. Only the text after the last block that
+// was already complete in `previous` is tokenized again.
+func (t *Tokenizer) Append(previous IncrementalTokens, suffix string) IncrementalTokens {
+	return t.tokenizeAfterStablePrefix(previous, previous.Text+suffix)
+}
+
+func (t *Tokenizer) tokenizeAfterStablePrefix(previous IncrementalTokens, text string) IncrementalTokens {
+	t.reset()
+
+	// Appending text moves earlier paragraphs further from the end of the
+	// body, which can change the blocks found in them, so nothing is stable
+	// when a block depends on that.
+	if t.usesBodyPosition() {
+		previous = IncrementalTokens{}
+	}
+
+	// This counts lines the same way as `ParseLines`.
+	lineCount := strings.Count(text, "\n")
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		lineCount++
+	}
+
+	lineIndex := strings.Count(text[:previous.stableTextLength], "\n")
+
+	result := IncrementalTokens{
+		Text:             text,
+		stableTextLength: previous.stableTextLength,
+		stableTokenCount: previous.stableTokenCount,
+	}
+
+	parser := newBlockParser(*t, 0)
+	scissor := t.scissorBlock()
+
+	for lineStartIndex := previous.stableTextLength; lineStartIndex < len(text); {
+		// A paragraph is ended by the line after it, like in
+		// `TokenizeLines`.
+		parser.linesAfter = lineCount - lineIndex
+		lineIndex++
+
+		lineEndIndex, nextLineStartIndex := len(text), len(text)
+		isComplete := false
+
+		if newlineIndex := strings.IndexByte(text[lineStartIndex:], '\n'); newlineIndex >= 0 {
+			lineEndIndex = lineStartIndex + newlineIndex
+			nextLineStartIndex = lineEndIndex + 1
+			isComplete = true
+		}
+
+		// This matches how `ParseLines` splits lines.
+		line := parseLine(strings.TrimSuffix(text[lineStartIndex:lineEndIndex], "\r"), t.ExtraQuoteMarkers, scissor)
+
+		for _, token := range t.rawTokenizeLine(line) {
+			parser.add(token)
+		}
+
+		// After a blank, unquoted line, the tokenizer is in the same state
+		// as when it starts, so nothing after it can change what came before.
+		if isComplete && line.IsEmpty() && line.QuoteDepth == 0 && !parser.pendingParagraph {
+			result.stableTextLength = nextLineStartIndex
+			result.stableTokenCount = previous.stableTokenCount + len(parser.output)
+		}
+
+		lineStartIndex = nextLineStartIndex
+	}
+
+	// This closes any paragraph or quote that's still open, like in
+	// `TokenizeLines`.
+	parser.linesAfter = 0
+
+	for _, token := range t.rawTokenizeLine(Line{Content: "", QuoteDepth: 0}) {
+		parser.add(token)
+	}
+
+	stableTokens := previous.Tokens[:previous.stableTokenCount:previous.stableTokenCount]
+	result.Tokens = append(stableTokens, parser.finish()...)
+
+	return result
+}
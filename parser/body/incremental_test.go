@@ -0,0 +1,64 @@
+package body
+
+import (
+	"github.com/acearchive/yg-render/block"
+	"testing"
+)
+
+var incrementalTestTexts = []string{
+	"Sounds good.\n\nOn Mon, 2 Jan 2006 15:04, Alice wrote:\n> Should we meet?\n>\n> > On Friday?\n\nSee you then.\n",
+	"-----Original Message-----\n\nFrom: Alice\nSubject: Lunch\n\nSee you there.\n",
+	"Signed.\n\n-----BEGIN PGP SIGNED MESSAGE-----\nHash: SHA1\n\nHello.\n\n-----BEGIN PGP SIGNATURE-----\nabc\n-----END PGP SIGNATURE-----\n\nBye.\n",
+	"First.\n-----\nSecond.\n\n--\nBob\n",
+	"No trailing newline\n\n> quoted",
+}
+
+func TestAppendMatchesTokenize(t *testing.T) {
+	for _, text := range incrementalTestTexts {
+		tokenizer := NewDefaultTokenizer()
+		want := describeTokens(tokenizeForTest(t, tokenizer, text))
+
+		// Split at every position, since text can be appended in the middle
+		// of a line.
+		for splitIndex := 0; splitIndex <= len(text); splitIndex++ {
+			previous := tokenizer.TokenizeIncremental(text[:splitIndex])
+
+			if got := describeTokens(tokenizer.Append(previous, text[splitIndex:]).Tokens); got != want {
+				t.Errorf("appending %q to %q:\ngot  %s\nwant %s", text[splitIndex:], text[:splitIndex], got, want)
+			}
+		}
+	}
+}
+
+func TestAppendEachCharacter(t *testing.T) {
+	for _, text := range incrementalTestTexts {
+		tokenizer := NewDefaultTokenizer()
+
+		result := tokenizer.TokenizeIncremental("")
+		for i := range text {
+			result = tokenizer.Append(result, text[i:i+1])
+
+			if got, want := describeTokens(result.Tokens), describeTokens(tokenizeForTest(t, tokenizer, text[:i+1])); got != want {
+				t.Fatalf("typing %q:\ngot  %s\nwant %s", text[:i+1], got, want)
+			}
+		}
+
+		if result.Text != text {
+			t.Errorf("Text = %q, want %q", result.Text, text)
+		}
+	}
+}
+
+func TestAppendWithFooterWindow(t *testing.T) {
+	tokenizer := NewTokenizer(func() []block.Block {
+		return block.AllBlocksWithOptions(block.ParseOptions{FooterWindow: 3})
+	})
+
+	text := "Hello.\n\nThis message is confidential and intended only for the addressee.\n"
+	want := describeTokens(tokenizeForTest(t, tokenizer, text+"More.\nAnd more.\nAnd more.\n"))
+
+	previous := tokenizer.TokenizeIncremental(text)
+	if got := describeTokens(tokenizer.Append(previous, "More.\nAnd more.\nAnd more.\n").Tokens); got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+}
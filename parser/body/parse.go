@@ -5,6 +5,7 @@ import (
 	"github.com/acearchive/yg-render/block"
 	"io"
 	"strings"
+	"unicode/utf8"
 )
 
 type TagType string
@@ -18,6 +19,7 @@ const (
 type Token interface {
 	TagType() TagType
 	ToHtml() string
+	WriteHtml(w io.Writer, options block.RenderOptions) error
 }
 
 type StartParagraphToken struct{}
@@ -61,6 +63,11 @@ func (TextToken) TagType() TagType {
 type Line struct {
 	QuoteDepth int
 	Content    string
+
+	// Indent is the whitespace before `Content`, not counting a single space
+	// after the last quote marker. It's kept so that preformatted text can be
+	// rendered with its original indentation.
+	Indent string
 }
 
 func (l Line) IsEmpty() bool {
@@ -76,14 +83,45 @@ func TrimSpaceStart(text string) string {
 	return strings.TrimLeft(text, whitespaceChars)
 }
 
+// ParseLine splits a line into its quote depth and content. The depth is the
+// number of leading quote markers, which may be packed together like ">>>"
+// or separated by any spacing like ">> >", so both of those have a depth
+// of 3.
 func ParseLine(line string) Line {
+	return ParseLineWithMarkers(line, "")
+}
+
+// ParseLineWithMarkers is like `ParseLine`, but also accepts each character
+// in `extraMarkers` as a quote marker, so "| |" has a depth of 2 when
+// `extraMarkers` is "|". Markers can be mixed, like in "> |".
+func ParseLineWithMarkers(line string, extraMarkers string) Line {
+	return parseLine(line, extraMarkers, &block.ScissorBlock{})
+}
+
+// parseLine is like `ParseLineWithMarkers`, but stops stripping quote markers
+// at a scissor line of `scissor`, so the ">" in ">8 ---" isn't read as one.
+// `scissor` may be nil when scissor lines aren't recognized.
+func parseLine(line string, extraMarkers string, scissor *block.ScissorBlock) Line {
 	quoteDepth := 0
 	content := TrimSpaceStart(line)
+	indent := line[:len(line)-len(content)]
+
+	for {
+		marker, markerSize := utf8.DecodeRuneInString(content)
+		if markerSize == 0 || (string(marker) != quoteChar && !strings.ContainsRune(extraMarkers, marker)) {
+			break
+		}
+
+		if scissor != nil && scissor.IsScissorLine(content) {
+			break
+		}
 
-	for strings.HasPrefix(content, quoteChar) {
 		quoteDepth++
-		content = strings.TrimPrefix(content, quoteChar)
-		content = TrimSpaceStart(content)
+		content = content[markerSize:]
+
+		trimmed := TrimSpaceStart(content)
+		indent = strings.TrimPrefix(content[:len(content)-len(trimmed)], " ")
+		content = trimmed
 	}
 
 	if len(strings.TrimSpace(content)) == 0 {
@@ -96,16 +134,21 @@ func ParseLine(line string) Line {
 	return Line{
 		Content:    content,
 		QuoteDepth: quoteDepth,
+		Indent:     indent,
 	}
 }
 
 func ParseLines(text io.Reader) ([]Line, error) {
+	return parseLinesWithMarkers(text, "", &block.ScissorBlock{})
+}
+
+func parseLinesWithMarkers(text io.Reader, extraMarkers string, scissor *block.ScissorBlock) ([]Line, error) {
 	var lines []Line
 
 	scanner := bufio.NewScanner(text)
 
 	for scanner.Scan() {
-		lines = append(lines, ParseLine(scanner.Text()))
+		lines = append(lines, parseLine(scanner.Text(), extraMarkers, scissor))
 	}
 
 	if err := scanner.Err(); err != nil {
@@ -116,6 +159,12 @@ func ParseLines(text io.Reader) ([]Line, error) {
 }
 
 type Tokenizer struct {
+	// ExtraQuoteMarkers are characters accepted as quote markers in addition
+	// to ">", like "|" for clients and converters that quote with it. This
+	// is off by default since text like ASCII tables also starts lines with
+	// "|".
+	ExtraQuoteMarkers string
+
 	previousLine      Line
 	currentQuoteDepth int
 	blockFactory      func() []block.Block
@@ -152,7 +201,7 @@ func (t *Tokenizer) rawTokenizeLine(line Line) []Token {
 		}
 
 		if !line.IsEmpty() {
-			tokens = append(tokens, StartParagraphToken{}, TextToken(line.Content))
+			tokens = append(tokens, StartParagraphToken{}, TextToken(line.Indent+line.Content))
 		}
 
 		t.currentQuoteDepth = line.QuoteDepth
@@ -167,13 +216,16 @@ func (t *Tokenizer) rawTokenizeLine(line Line) []Token {
 
 		t.currentQuoteDepth = line.QuoteDepth
 	case line.IsEmpty() && !t.previousLine.IsEmpty():
+		// Only the first of a run of blank lines, quoted or not, ends the
+		// paragraph; the rest produce no tokens, so a run of any length
+		// renders as a single paragraph break.
 		tokens = append(tokens, EndParagraphToken{})
 	case !line.IsEmpty():
 		if t.previousLine.IsEmpty() {
 			tokens = append(tokens, StartParagraphToken{})
 		}
 
-		tokens = append(tokens, TextToken(line.Content))
+		tokens = append(tokens, TextToken(line.Indent+line.Content))
 	}
 
 	t.previousLine = line
@@ -184,17 +236,31 @@ func (t *Tokenizer) rawTokenizeLine(line Line) []Token {
 func (t *Tokenizer) TokenizeLines(lines []Line) []Token {
 	t.reset()
 
-	var tokens []Token
+	parser := newBlockParser(*t, len(lines))
+
+	for lineIndex, line := range lines {
+		// A paragraph is ended by the line after it, so this is the number
+		// of lines after any paragraph ended by this line.
+		parser.linesAfter = len(lines) - lineIndex
+
+		for _, token := range t.rawTokenizeLine(line) {
+			parser.add(token)
+		}
+	}
+
+	// Tokenizing a trailing empty line closes any paragraph or quote that's
+	// still open when the text doesn't end with a blank line.
+	parser.linesAfter = 0
 
-	for _, line := range lines {
-		tokens = append(tokens, t.rawTokenizeLine(line)...)
+	for _, token := range t.rawTokenizeLine(Line{Content: "", QuoteDepth: 0}) {
+		parser.add(token)
 	}
 
-	return t.parseBlocks(tokens)
+	return parser.finish()
 }
 
 func (t *Tokenizer) Tokenize(body io.Reader) ([]Token, error) {
-	lines, err := ParseLines(body)
+	lines, err := parseLinesWithMarkers(body, t.ExtraQuoteMarkers, t.scissorBlock())
 	if err != nil {
 		return nil, err
 	}
@@ -202,50 +268,252 @@ func (t *Tokenizer) Tokenize(body io.Reader) ([]Token, error) {
 	return t.TokenizeLines(lines), nil
 }
 
-func (t Tokenizer) findBlocksInParagraph(text string) []Token {
+// walkParagraph finds the blocks in `text`, which is followed by `linesAfter`
+// lines in the body.
+func (t Tokenizer) walkParagraph(text string, linesAfter int, fn func(Token) error) error {
 	for _, newBlock := range t.blockFactory() {
+		if positioner, ok := newBlock.(block.BodyPositioner); ok {
+			positioner.SetLinesAfter(linesAfter)
+		}
+
 		if ok, before, after := newBlock.FromText(text); ok {
-			beforeBlocks := t.findBlocksInParagraph(before)
-			afterBlocks := t.findBlocksInParagraph(after)
+			beforeLinesAfter := linesAfter + strings.Count(text, "\n") - strings.Count(before, "\n")
 
-			output := make([]Token, 0, len(beforeBlocks)+len(afterBlocks)+1)
-			output = append(output, beforeBlocks...)
-			output = append(output, BlockToken{newBlock})
-			output = append(output, afterBlocks...)
+			if err := t.walkParagraph(before, beforeLinesAfter, fn); err != nil {
+				return err
+			}
 
-			return output
+			if parser, isParser := newBlock.(block.BlockParser); isParser {
+				newBlock = parser.ParsedBlock()
+			}
+
+			if err := fn(BlockToken{newBlock}); err != nil {
+				return err
+			}
+
+			return t.walkParagraph(after, linesAfter, fn)
 		}
 	}
 
 	if len(strings.TrimSpace(text)) == 0 {
-		return []Token{}
+		return nil
+	}
+
+	for _, token := range []Token{StartParagraphToken{}, TextToken(text), EndParagraphToken{}} {
+		if err := fn(token); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (t Tokenizer) findBlocksInParagraph(text string, linesAfter int) []Token {
+	output := []Token{}
+
+	// The callback never returns an error, so neither does the walk.
+	_ = t.walkParagraph(text, linesAfter, func(token Token) error {
+		output = append(output, token)
+		return nil
+	})
+
+	return output
+}
+
+// WalkBlocks calls `fn` for each block found in `text` in the order they
+// appear, using the same precedence as `Tokenize`. It stops and returns the
+// error if `fn` returns one. The text is treated as the end of the body.
+func (t Tokenizer) WalkBlocks(text string, fn func(block.Block) error) error {
+	return t.walkParagraph(text, 0, func(token Token) error {
+		if blockToken, isBlock := token.(BlockToken); isBlock {
+			return fn(blockToken.Block)
+		}
+
+		return nil
+	})
+}
+
+// scissorBlock returns the scissor block made by the block factory, which
+// holds the markers of scissor lines, or nil if it doesn't make one.
+func (t Tokenizer) scissorBlock() *block.ScissorBlock {
+	for _, newBlock := range t.blockFactory() {
+		if scissor, ok := newBlock.(*block.ScissorBlock); ok {
+			return scissor
+		}
 	}
 
-	return []Token{
-		StartParagraphToken{},
-		TextToken(text),
-		EndParagraphToken{},
+	return nil
+}
+
+// usesBodyPosition returns whether any block depends on how many lines of
+// the body come after it. See `block.BodyPositioner`.
+func (t Tokenizer) usesBodyPosition() bool {
+	for _, newBlock := range t.blockFactory() {
+		if positioner, ok := newBlock.(block.BodyPositioner); ok && positioner.UsesBodyPosition() {
+			return true
+		}
 	}
+
+	return false
 }
 
-func (t Tokenizer) parseBlocks(tokens []Token) []Token {
-	output := make([]Token, 0, len(tokens))
+func (t Tokenizer) continuesParagraph(text string) bool {
+	for _, newBlock := range t.blockFactory() {
+		if continuer, ok := newBlock.(block.ParagraphContinuer); ok && continuer.ContinuesParagraph(text) {
+			return true
+		}
+	}
 
-	var currentParagraph strings.Builder
+	return false
+}
+
+// blockParser finds the blocks in each paragraph of a stream of tokens from
+// `rawTokenizeLine`.
+type blockParser struct {
+	tokenizer        Tokenizer
+	output           []Token
+	currentParagraph strings.Builder
+
+	// A paragraph consisting of only an "Original Message" banner is carried
+	// over into the next paragraph so the header fields after it can be
+	// parsed, even when there's a blank line in between. The same goes for a
+	// paragraph which starts a block that spans paragraphs.
+	pendingParagraph bool
+
+	// pendingBanner is set when the carried over paragraph is a banner, which
+	// is only joined with the next paragraph if it starts with a header
+	// field.
+	pendingBanner bool
+
+	// carriedParagraphs are where each paragraph which was carried over ends
+	// in `currentParagraph`, so they can be split up again if the block they
+	// start is never ended.
+	carriedParagraphs []carriedParagraph
+
+	// linesAfter is the number of lines in the body after the paragraph
+	// ended by the next token, and paragraphLinesAfter is the same for the
+	// current paragraph.
+	linesAfter          int
+	paragraphLinesAfter int
+}
 
-	for _, token := range tokens {
-		switch concrete := token.(type) {
-		case StartParagraphToken:
-			currentParagraph.Reset()
-		case EndParagraphToken:
-			output = append(output, t.findBlocksInParagraph(currentParagraph.String())...)
-		case TextToken:
-			currentParagraph.WriteString(string(concrete))
-			currentParagraph.WriteString("\n")
+func newBlockParser(tokenizer Tokenizer, capacity int) *blockParser {
+	return &blockParser{
+		tokenizer: tokenizer,
+		output:    make([]Token, 0, capacity),
+	}
+}
+
+type carriedParagraph struct {
+	EndIndex   int
+	LinesAfter int
+}
+
+func (p *blockParser) carriesOver(text string) bool {
+	return block.IsMessageHeaderBanner(text) || p.tokenizer.continuesParagraph(text)
+}
+
+func (p *blockParser) flushParagraph() {
+	if p.pendingParagraph {
+		p.findBlocksInCarriedParagraphs()
+		p.pendingParagraph = false
+	}
+
+	p.pendingBanner = false
+	p.carriedParagraphs = nil
+}
+
+// findBlocksInCarriedParagraphs finds the blocks in the paragraphs which were
+// carried over when the body ends or a quote starts before the block they
+// start is ended, like PGP armor without an END line. Each of them is its own
+// paragraph again, except for runs of them which do end a block, so the rest
+// of the body isn't joined into one paragraph.
+func (p *blockParser) findBlocksInCarriedParagraphs() {
+	text := p.currentParagraph.String()
+	paragraphs := p.carriedParagraphs
+
+	startIndexOf := func(i int) int {
+		if i == 0 {
+			return 0
+		}
+
+		// The paragraphs are separated by a newline.
+		return paragraphs[i-1].EndIndex + 1
+	}
+
+	for i := 0; i < len(paragraphs); {
+		lastIndex := i
+
+		if p.carriesOver(text[startIndexOf(i):paragraphs[i].EndIndex]) {
+			for lastIndex < len(paragraphs)-1 && p.carriesOver(text[startIndexOf(i):paragraphs[lastIndex].EndIndex]) {
+				lastIndex++
+			}
+
+			if p.carriesOver(text[startIndexOf(i):paragraphs[lastIndex].EndIndex]) {
+				lastIndex = i
+			}
+		}
+
+		paragraphText := text[startIndexOf(i):paragraphs[lastIndex].EndIndex]
+		p.output = append(p.output, p.tokenizer.findBlocksInParagraph(paragraphText, paragraphs[lastIndex].LinesAfter)...)
+
+		i = lastIndex + 1
+	}
+}
+
+func (p *blockParser) add(token Token) {
+	switch concrete := token.(type) {
+	case StartParagraphToken:
+		switch {
+		case p.pendingBanner:
+			// Whether to join the banner with this paragraph depends on its
+			// first line.
+			p.pendingParagraph = false
+		case p.pendingParagraph:
+			p.currentParagraph.WriteString("\n")
+			p.pendingParagraph = false
 		default:
-			output = append(output, token)
+			p.currentParagraph.Reset()
+		}
+	case EndParagraphToken:
+		p.paragraphLinesAfter = p.linesAfter
+
+		if p.carriesOver(p.currentParagraph.String()) {
+			p.pendingParagraph = true
+			p.pendingBanner = block.IsMessageHeaderBanner(p.currentParagraph.String())
+			p.carriedParagraphs = append(p.carriedParagraphs, carriedParagraph{
+				EndIndex:   p.currentParagraph.Len(),
+				LinesAfter: p.paragraphLinesAfter,
+			})
+
+			return
+		}
+
+		p.output = append(p.output, p.tokenizer.findBlocksInParagraph(p.currentParagraph.String(), p.paragraphLinesAfter)...)
+		p.carriedParagraphs = nil
+	case TextToken:
+		if p.pendingBanner {
+			p.pendingBanner = false
+
+			if block.StartsWithField(string(concrete)) {
+				p.currentParagraph.WriteString("\n")
+			} else {
+				p.output = append(p.output, p.tokenizer.findBlocksInParagraph(p.currentParagraph.String(), p.paragraphLinesAfter)...)
+				p.currentParagraph.Reset()
+				p.carriedParagraphs = nil
+			}
 		}
+
+		p.currentParagraph.WriteString(string(concrete))
+		p.currentParagraph.WriteString("\n")
+	default:
+		p.flushParagraph()
+		p.output = append(p.output, token)
 	}
+}
 
-	return output
+func (p *blockParser) finish() []Token {
+	p.flushParagraph()
+
+	return p.output
 }
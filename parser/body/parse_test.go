@@ -0,0 +1,543 @@
+package body
+
+import (
+	"errors"
+	"fmt"
+	"github.com/acearchive/yg-render/block"
+	"strings"
+	"testing"
+)
+
+func tokenizeForTest(t *testing.T, tokenizer Tokenizer, text string) []Token {
+	t.Helper()
+
+	tokens, err := tokenizer.Tokenize(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return tokens
+}
+
+// describeTokens writes the tokens on one line, like `p "Hi\n" /p`, with the
+// type of each block, so a failure shows what was parsed.
+func describeTokens(tokens []Token) string {
+	descriptions := make([]string, len(tokens))
+
+	for i, token := range tokens {
+		switch concreteToken := token.(type) {
+		case StartParagraphToken:
+			descriptions[i] = "p"
+		case EndParagraphToken:
+			descriptions[i] = "/p"
+		case StartQuoteToken:
+			descriptions[i] = "quote"
+		case EndQuoteToken:
+			descriptions[i] = "/quote"
+		case TextToken:
+			descriptions[i] = fmt.Sprintf("%q", string(concreteToken))
+		case BlockToken:
+			descriptions[i] = fmt.Sprintf("%T", concreteToken.Block)
+		default:
+			descriptions[i] = fmt.Sprintf("%T", token)
+		}
+	}
+
+	return strings.Join(descriptions, " ")
+}
+
+func TestTokenizeAttributionWithoutColon(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			name: "before a quote",
+			text: "On Mon, 2 Jan 2006, Alice wrote\n> I agree.\n",
+			want: `*block.AttributionBlock quote p "I agree.\n" /p /quote`,
+		},
+		{
+			name: "before more text",
+			text: "On Mon, 2 Jan 2006, Alice wrote\nthat it is good.\n",
+			want: `p "On Mon, 2 Jan 2006, Alice wrote\nthat it is good.\n" /p`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := describeTokens(tokenizeForTest(t, NewDefaultTokenizer(), test.text)); got != test.want {
+				t.Errorf("tokens of %q:\ngot  %s\nwant %s", test.text, got, test.want)
+			}
+		})
+	}
+}
+
+func TestTokenizeProseWithYearlessDate(t *testing.T) {
+	for _, text := range []string{
+		"On March 3 the team wrote\nthe code for the release.\n",
+		"On Jan 2 Bob wrote\nthe code\n",
+		"On Jan 2 Bob wrote\n\nthe code\n",
+	} {
+		for _, token := range tokenizeForTest(t, NewDefaultTokenizer(), text) {
+			if _, isBlock := token.(BlockToken); isBlock {
+				t.Errorf("tokens of %q have a block: %s", text, describeTokens(tokenizeForTest(t, NewDefaultTokenizer(), text)))
+				break
+			}
+		}
+	}
+}
+
+func TestTokenizePgpArmor(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			name: "across paragraphs",
+			text: "Signed.\n\n-----BEGIN PGP SIGNATURE-----\nVersion: GnuPG v1\n\niD8DBQFD\n-----END PGP SIGNATURE-----\n\nBye.\n",
+			want: `p "Signed.\n" /p *block.PgpBlock p "Bye.\n" /p`,
+		},
+		{
+			name: "without an end line",
+			text: "-----BEGIN PGP SIGNATURE-----\nVersion: GnuPG v1\n\nFirst.\n\nSecond.\n",
+			want: `p "-----BEGIN PGP SIGNATURE-----\nVersion: GnuPG v1\n" /p p "First.\n" /p p "Second.\n" /p`,
+		},
+		{
+			name: "without an end line before a quote",
+			text: "-----BEGIN PGP MESSAGE-----\n\nFirst.\n> Quoted.\n",
+			want: `p "-----BEGIN PGP MESSAGE-----\n" /p p "First.\n" /p quote p "Quoted.\n" /p /quote`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := describeTokens(tokenizeForTest(t, NewDefaultTokenizer(), test.text)); got != test.want {
+				t.Errorf("tokens of %q:\ngot  %s\nwant %s", test.text, got, test.want)
+			}
+		})
+	}
+}
+
+func TestTokenizeRawHtml(t *testing.T) {
+	tokenizer := NewTokenizer(func() []block.Block {
+		return block.AllBlocksWithOptions(block.ParseOptions{RawHtmlSentinel: "%%raw%%"})
+	})
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			name: "in one paragraph",
+			text: "Before.\n%%raw%%\n<b>Curated</b>\n%%raw%%\nAfter.\n",
+			want: `p "Before.\n" /p *block.RawHtmlBlock p "After.\n" /p`,
+		},
+		{
+			name: "across paragraphs",
+			text: "%%raw%%\n<p>One</p>\n\n<p>Two</p>\n%%raw%%\n\nAfter.\n",
+			want: `*block.RawHtmlBlock p "After.\n" /p`,
+		},
+		{
+			name: "without a closing sentinel",
+			text: "%%raw%%\n<b>One</b>\n\nTwo.\n\nThree.\n",
+			want: `p "%%raw%%\n<b>One</b>\n" /p p "Two.\n" /p p "Three.\n" /p`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := describeTokens(tokenizeForTest(t, tokenizer, test.text)); got != test.want {
+				t.Errorf("tokens of %q:\ngot  %s\nwant %s", test.text, got, test.want)
+			}
+		})
+	}
+}
+
+func TestRenderRawHtml(t *testing.T) {
+	tokenizer := NewTokenizer(func() []block.Block {
+		return block.AllBlocksWithOptions(block.ParseOptions{RawHtmlSentinel: "%%raw%%"})
+	})
+
+	rendered := Render(tokenizeForTest(t, tokenizer, "%%raw%%\n<b>Curated & kept</b>\n%%raw%%\n"))
+
+	if !strings.Contains(rendered, "<b>Curated & kept</b>") {
+		t.Errorf("raw section was escaped or dropped:\n%s", rendered)
+	}
+
+	if escaped := Render(tokenizeForTest(t, NewDefaultTokenizer(), "%%raw%%\n<b>Curated</b>\n%%raw%%\n")); strings.Contains(escaped, "<b>") {
+		t.Errorf("raw section was passed through without a sentinel option:\n%s", escaped)
+	}
+}
+
+func TestTokenizeScissorLine(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			name: "in a paragraph",
+			text: "Intro.\n--- 8< --- cut here --- 8< ---\nPatch.\n",
+			want: `p "Intro.\n" /p *block.ScissorBlock p "Patch.\n" /p`,
+		},
+		{
+			name: "starting with a quote marker",
+			text: "Intro.\n\n>8 ---\n\nPatch.\n",
+			want: `p "Intro.\n" /p *block.ScissorBlock p "Patch.\n" /p`,
+		},
+		{
+			name: "between dashes",
+			text: "-- >8 --\nPatch.\n",
+			want: `*block.ScissorBlock p "Patch.\n" /p`,
+		},
+		{
+			name: "in a quote",
+			text: "> Intro.\n> >8 ---\n",
+			want: `quote p "Intro.\n" /p *block.ScissorBlock /quote`,
+		},
+		{
+			name: "without dashes",
+			text: "8<\n",
+			want: `p "8<\n" /p`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := describeTokens(tokenizeForTest(t, NewDefaultTokenizer(), test.text)); got != test.want {
+				t.Errorf("tokens of %q:\ngot  %s\nwant %s", test.text, got, test.want)
+			}
+		})
+	}
+}
+
+func TestTokenizeWithoutTrailingNewline(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			name: "attribution",
+			text: "Sounds good.\n\nOn Mon, Jan 2, 2006 at 3:04 PM Alice <alice@example.com> wrote:",
+			want: `p "Sounds good.\n" /p *block.AttributionBlock`,
+		},
+		{
+			name: "paragraph",
+			text: "Sounds good.",
+			want: `p "Sounds good.\n" /p`,
+		},
+		{
+			name: "quote",
+			text: "> Sounds good.",
+			want: `quote p "Sounds good.\n" /p /quote`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := describeTokens(tokenizeForTest(t, NewDefaultTokenizer(), test.text)); got != test.want {
+				t.Errorf("tokens of %q:\ngot  %s\nwant %s", test.text, got, test.want)
+			}
+		})
+	}
+}
+
+func TestWalkBlocks(t *testing.T) {
+	const text = "On Mon, Jan 2, 2006 at 3:04 PM Alice <alice@example.com> wrote:\n" +
+		"-----\n" +
+		"On Tue, Jan 3, 2006 at 3:04 PM Bob <bob@example.com> wrote:\n"
+
+	errStop := errors.New("stop")
+
+	var visited []string
+
+	err := NewDefaultTokenizer().WalkBlocks(text, func(walkedBlock block.Block) error {
+		visited = append(visited, fmt.Sprintf("%T", walkedBlock))
+		if len(visited) == 2 {
+			return errStop
+		}
+
+		return nil
+	})
+
+	if !errors.Is(err, errStop) {
+		t.Errorf("WalkBlocks() error = %v, want %v", err, errStop)
+	}
+
+	if got, want := strings.Join(visited, " "), "*block.AttributionBlock *block.DividerBlock"; got != want {
+		t.Errorf("WalkBlocks() visited %s, want %s", got, want)
+	}
+}
+
+func TestTokenizeBannerBeforeBlankLine(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			name: "before header fields",
+			text: "-----Original Message-----\n\nSubject: Lunch\nFrom: Alice\n\nSee you there.\n",
+			want: `*block.MessageHeaderBlock p "See you there.\n" /p`,
+		},
+		{
+			name: "before prose",
+			text: "-----Original Message-----\n\nSee you there.\n",
+			want: `p "-----Original Message-----\n" /p p "See you there.\n" /p`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := describeTokens(tokenizeForTest(t, NewDefaultTokenizer(), test.text)); got != test.want {
+				t.Errorf("tokens of %q:\ngot  %s\nwant %s", test.text, got, test.want)
+			}
+		})
+	}
+}
+
+func TestTokenizeTextAfterAttributionColon(t *testing.T) {
+	text := "On Mon, 2 Jan 2006 15:04, Alice wrote: I agree, because it's simpler.\n"
+
+	if got, want := describeTokens(tokenizeForTest(t, NewDefaultTokenizer(), text)), `*block.AttributionBlock p "I agree, because it's simpler.\n" /p`; got != want {
+		t.Errorf("tokens of %q:\ngot  %s\nwant %s", text, got, want)
+	}
+}
+
+func TestParseLineQuoteDepth(t *testing.T) {
+	tests := []struct {
+		name         string
+		line         string
+		extraMarkers string
+		wantDepth    int
+		wantContent  string
+	}{
+		{"tight", ">>>text", "", 3, "text"},
+		{"spaced", "> > > text", "", 3, "text"},
+		{"mixed spacing", ">> >text", "", 3, "text"},
+		{"tabs and leading space", " >\t>> text", "", 3, "text"},
+		{"extra markers", "> | text", "|", 2, "text"},
+		{"unquoted", "text > more", "", 0, "text > more"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			line := ParseLineWithMarkers(test.line, test.extraMarkers)
+			if line.QuoteDepth != test.wantDepth || line.Content != test.wantContent {
+				t.Errorf("ParseLineWithMarkers(%q, %q) = %d, %q, want %d, %q", test.line, test.extraMarkers, line.QuoteDepth, line.Content, test.wantDepth, test.wantContent)
+			}
+		})
+	}
+}
+
+func TestTokenizeTightQuoteMarkers(t *testing.T) {
+	tight := tokenizeForTest(t, NewDefaultTokenizer(), ">>>Forwarded text.\n")
+	spaced := tokenizeForTest(t, NewDefaultTokenizer(), ">> > Forwarded text.\n")
+
+	if describeTokens(tight) != describeTokens(spaced) {
+		t.Errorf("tight and spaced markers differ:\ntight  %s\nspaced %s", describeTokens(tight), describeTokens(spaced))
+	}
+
+	if got, want := describeTokens(tight), `quote quote quote p "Forwarded text.\n" /p /quote /quote /quote`; got != want {
+		t.Errorf("tokens:\ngot  %s\nwant %s", got, want)
+	}
+}
+
+func TestTokenizeExtraQuoteMarkers(t *testing.T) {
+	tests := []struct {
+		name         string
+		text         string
+		extraMarkers string
+		want         string
+	}{
+		{
+			name:         "nested",
+			text:         "| | Nested.\n|\n| Outer.\n",
+			extraMarkers: "|",
+			want:         describeTokens(tokenizeForTest(t, NewDefaultTokenizer(), "> > Nested.\n>\n> Outer.\n")),
+		},
+		{
+			name:         "attribution",
+			text:         "| On Mon, Jan 2, 2006 at 3:04 PM, Alice <alice@example.com> wrote:\n| | Hello.\n",
+			extraMarkers: "|",
+			want:         `quote *block.AttributionBlock quote p "Hello.\n" /p /quote /quote`,
+		},
+		{
+			name:         "disabled",
+			text:         "| Not quoted.\n",
+			extraMarkers: "",
+			want:         `p "| Not quoted.\n" /p`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tokenizer := NewDefaultTokenizer()
+			tokenizer.ExtraQuoteMarkers = test.extraMarkers
+
+			if got := describeTokens(tokenizeForTest(t, tokenizer, test.text)); got != test.want {
+				t.Errorf("tokens:\ngot  %s\nwant %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseHooks(t *testing.T) {
+	var events []string
+
+	hooks := &block.ParseHooks{
+		AttributionMatched: func(format string) {
+			events = append(events, "attribution matched "+format)
+		},
+		AttributionFailed: func(format string, err error) {
+			events = append(events, fmt.Sprintf("attribution failed %s: %v", format, err))
+		},
+		HeaderParsed: func(fieldCount int) {
+			events = append(events, fmt.Sprintf("header parsed with %d fields", fieldCount))
+		},
+		FooterStripped: func(kind block.Kind) {
+			events = append(events, fmt.Sprintf("footer stripped %s", kind))
+		},
+	}
+
+	tokenizer := NewTokenizer(func() []block.Block {
+		return block.AllBlocksWithOptions(block.ParseOptions{Hooks: hooks})
+	})
+
+	tokenizeForTest(t, tokenizer, "Hi.\n\n"+
+		"On Mon, 2 Jan 2006 15:04, Alice wrote:\n> Hello.\n\n"+
+		"On Mon, 2 Jan 2006 25:99, Bob wrote:\n> Hello again.\n\n"+
+		"-----Original Message-----\nFrom: Carol\nSubject: Lunch\n\nSee you.\n\n"+
+		"This email is confidential and intended only for the addressee.\n")
+
+	want := []string{
+		"attribution matched OnDateTime",
+		`attribution failed OnDateTime: parsing time "25:99": hour out of range`,
+		"header parsed with 2 fields",
+		"footer stripped Disclaimer",
+	}
+
+	if got := strings.Join(events, "\n"); got != strings.Join(want, "\n") {
+		t.Errorf("hook calls:\ngot\n%s\nwant\n%s", got, strings.Join(want, "\n"))
+	}
+
+	// The hooks are optional.
+	tokenizeForTest(t, NewTokenizer(func() []block.Block {
+		return block.AllBlocksWithOptions(block.ParseOptions{Hooks: &block.ParseHooks{}})
+	}), "On Mon, 2 Jan 2006 15:04, Alice wrote:\n> Hello.\n")
+}
+
+func TestTokenizeTruncationNotice(t *testing.T) {
+	for _, notice := range []string{"[Message truncated]", "----- Message truncated -----"} {
+		t.Run(notice, func(t *testing.T) {
+			tokens := tokenizeForTest(t, NewDefaultTokenizer(), "Above.\n"+notice+"\nBelow.\n")
+
+			if got, want := describeTokens(tokens), `p "Above.\n" /p *block.TruncationBlock p "Below.\n" /p`; got != want {
+				t.Errorf("tokens:\ngot  %s\nwant %s", got, want)
+			}
+		})
+	}
+}
+
+func TestTokenizeStackedHeaders(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		want      string
+		wantFroms []string
+	}{
+		{
+			name: "with banners",
+			text: "-----Original Message-----\nFrom: Bob\nSent: Tuesday, January 03, 2006 9:30 AM\nSubject: RE: Lunch\n" +
+				"-----Original Message-----\nFrom: Alice\nSent: Monday, January 02, 2006 3:04 PM\nSubject: Lunch\n\n" +
+				"Where should we go?\n",
+			want:      `*block.MessageHeaderBlock *block.MessageHeaderBlock p "Where should we go?\n" /p`,
+			wantFroms: []string{"Bob", "Alice"},
+		},
+		{
+			name:      "without banners",
+			text:      "From: Bob\nTo: Carol\nSubject: RE: Lunch\nFrom: Alice\nTo: Bob\nSubject: Lunch\n\nWhere should we go?\n",
+			want:      `*block.MessageHeaderBlock *block.MessageHeaderBlock p "Where should we go?\n" /p`,
+			wantFroms: []string{"Bob", "Alice"},
+		},
+		{
+			name: "each before its message",
+			text: "Sure.\n\n-----Original Message-----\nFrom: Bob\nSubject: RE: Lunch\n\nNoon?\n\n" +
+				"-----Original Message-----\nFrom: Alice\nSubject: Lunch\n\nWhere should we go?\n",
+			want:      `p "Sure.\n" /p *block.MessageHeaderBlock p "Noon?\n" /p *block.MessageHeaderBlock p "Where should we go?\n" /p`,
+			wantFroms: []string{"Bob", "Alice"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tokens := tokenizeForTest(t, NewDefaultTokenizer(), test.text)
+
+			if got := describeTokens(tokens); got != test.want {
+				t.Errorf("tokens:\ngot  %s\nwant %s", got, test.want)
+			}
+
+			var froms []string
+			for _, token := range tokens {
+				if blockToken, ok := token.(BlockToken); ok {
+					if header, ok := blockToken.Block.(*block.MessageHeaderBlock); ok {
+						from, _ := header.Field("From")
+						froms = append(froms, from.Value)
+					}
+				}
+			}
+
+			if got, want := strings.Join(froms, ", "), strings.Join(test.wantFroms, ", "); got != want {
+				t.Errorf("From fields = %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+func TestTokenizeFooterWindow(t *testing.T) {
+	text := "Sounds good.\n\n" +
+		"On Mon, 2 Jan 2006 15:04, Alice wrote:\n" +
+		"> Should we meet?\n>\n" +
+		"> This email is confidential and intended only for the addressee.\n\n" +
+		"See you Friday.\n" +
+		"Bob\n\n" +
+		"This email is confidential and intended only for the addressee.\n" +
+		"Please delete it if you received it in error.\n"
+
+	tests := []struct {
+		name         string
+		footerWindow int
+		want         string
+	}{
+		{
+			name:         "only at the end",
+			footerWindow: 3,
+			want: `p "Sounds good.\n" /p *block.AttributionBlock quote p "Should we meet?\n" /p ` +
+				`p "This email is confidential and intended only for the addressee.\n" /p /quote ` +
+				`p "See you Friday.\nBob\n" /p *block.DisclaimerBlock`,
+		},
+		{
+			name:         "anywhere",
+			footerWindow: 0,
+			want: `p "Sounds good.\n" /p *block.AttributionBlock quote p "Should we meet?\n" /p ` +
+				`*block.DisclaimerBlock /quote ` +
+				`p "See you Friday.\nBob\n" /p *block.DisclaimerBlock`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tokenizer := NewTokenizer(func() []block.Block {
+				return block.AllBlocksWithOptions(block.ParseOptions{FooterWindow: test.footerWindow})
+			})
+
+			if got := describeTokens(tokenizeForTest(t, tokenizer, text)); got != test.want {
+				t.Errorf("tokens:\ngot  %s\nwant %s", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package body
+
+import "github.com/acearchive/yg-render/block"
+
+type PostingStyle string
+
+const (
+	// PostingStyleNone means the message doesn't have both new and quoted
+	// content.
+	PostingStyleNone        PostingStyle = "None"
+	PostingStyleTopPost     PostingStyle = "TopPost"
+	PostingStyleBottomPost  PostingStyle = "BottomPost"
+	PostingStyleInterleaved PostingStyle = "Interleaved"
+)
+
+type contentKind int
+
+const (
+	contentKindNew contentKind = iota
+	contentKindQuoted
+)
+
+func contentRuns(tokens []Token) []contentKind {
+	var runs []contentKind
+
+	appendRun := func(kind contentKind) {
+		if len(runs) == 0 || runs[len(runs)-1] != kind {
+			runs = append(runs, kind)
+		}
+	}
+
+	quoteDepth := 0
+
+	// Outlook-style replies put the original message after a header block
+	// without quoting it, so everything after a header counts as quoted.
+	afterHeader := false
+
+	for _, token := range tokens {
+		switch concreteToken := token.(type) {
+		case StartQuoteToken:
+			quoteDepth++
+		case EndQuoteToken:
+			quoteDepth--
+		case TextToken:
+			if quoteDepth > 0 || afterHeader {
+				appendRun(contentKindQuoted)
+			} else {
+				appendRun(contentKindNew)
+			}
+		case BlockToken:
+			switch concreteToken.Block.(type) {
+			case *block.AttributionBlock:
+				appendRun(contentKindQuoted)
+			case *block.MessageHeaderBlock:
+				appendRun(contentKindQuoted)
+
+				if quoteDepth == 0 {
+					afterHeader = true
+				}
+			}
+		}
+	}
+
+	return runs
+}
+
+func ClassifyPostingStyle(tokens []Token) PostingStyle {
+	runs := contentRuns(tokens)
+
+	switch {
+	case len(runs) < 2:
+		return PostingStyleNone
+	case len(runs) > 2:
+		return PostingStyleInterleaved
+	case runs[0] == contentKindNew:
+		return PostingStyleTopPost
+	default:
+		return PostingStyleBottomPost
+	}
+}
@@ -0,0 +1,56 @@
+package body
+
+import "testing"
+
+func TestClassifyPostingStyle(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want PostingStyle
+	}{
+		{
+			name: "top-posted",
+			text: "Sounds good to me.\n\n" +
+				"On Mon, Jan 2, 2006 at 3:04 PM Alice <alice@example.com> wrote:\n" +
+				"> Should we meet on Friday?\n",
+			want: PostingStyleTopPost,
+		},
+		{
+			name: "bottom-posted",
+			text: "On Mon, Jan 2, 2006 at 3:04 PM Alice <alice@example.com> wrote:\n" +
+				"> Should we meet on Friday?\n\n" +
+				"Sounds good to me.\n",
+			want: PostingStyleBottomPost,
+		},
+		{
+			name: "interleaved",
+			text: "> Should we meet on Friday?\n\n" +
+				"Sounds good to me.\n\n" +
+				"> Where?\n\n" +
+				"At the library.\n",
+			want: PostingStyleInterleaved,
+		},
+		{
+			name: "top-posted above an Outlook header",
+			text: "Sounds good to me.\n\n" +
+				"From: Alice <alice@example.com>\n" +
+				"Sent: Monday, January 2, 2006 3:04 PM\n" +
+				"Subject: Friday\n\n" +
+				"Should we meet on Friday?\n",
+			want: PostingStyleTopPost,
+		},
+		{
+			name: "without a quote",
+			text: "Sounds good to me.\n",
+			want: PostingStyleNone,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := ClassifyPostingStyle(tokenizeForTest(t, NewDefaultTokenizer(), test.text)); got != test.want {
+				t.Errorf("ClassifyPostingStyle(%q) = %v, want %v", test.text, got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package body
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strconv"
+)
+
+// This matches mentions of other messages in the group by number, like "see
+// message 1234", "msg #1234", or "post no. 1234". Because "post" and "msg"
+// are also used in prose, like "I will post 2 photos", they need a "#" or
+// "no." before the number, or a number with at least three digits.
+var messageReferenceRegex = regexp.MustCompile(`(?i)\b(?:(?:message\s*(?:#|no\.?)?|(?:msg|post)\s*(?:#|no\.))\s*(\d+)|(?:msg|post)\s+(\d{3,}))\b`)
+
+const (
+	messageReferenceRegexNumberIndex     = 1
+	messageReferenceRegexBareNumberIndex = 2
+)
+
+// Mentions of messages inside URLs and email addresses, like
+// "http://example.com/post123", aren't references, and linking them would
+// split the address.
+var messageReferenceAddressRegex = regexp.MustCompile(`(?i)\b(?:[a-z][a-z0-9+.-]*://|www\.)\S+|[^\s<>()@]+@[^\s<>()@]+`)
+
+// isInsideRanges returns whether the range from `startIndex` to `endIndex`
+// overlaps any of `ranges`, which are pairs of indices.
+func isInsideRanges(ranges [][]int, startIndex, endIndex int) bool {
+	for _, r := range ranges {
+		if startIndex < r[1] && endIndex > r[0] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// writeTextWithMessageReferences writes `text` with each mention of a message
+// which `resolve` finds a link for as a link to it. The text around them, and
+// mentions which can't be resolved, are written with `writeText`.
+func writeTextWithMessageReferences(w io.Writer, text string, resolve func(number int) (href string, ok bool), writeText func(w io.Writer, text string) error) error {
+	lastIndex := 0
+	addressRanges := messageReferenceAddressRegex.FindAllStringIndex(text, -1)
+
+	for _, match := range messageReferenceRegex.FindAllStringSubmatchIndex(text, -1) {
+		referenceStartIndex, referenceEndIndex := match[0], match[1]
+		if isInsideRanges(addressRanges, referenceStartIndex, referenceEndIndex) {
+			continue
+		}
+
+		numberStartIndex, numberEndIndex := indicesForCaptureGroup(match, messageReferenceRegexNumberIndex)
+		if numberStartIndex < 0 {
+			numberStartIndex, numberEndIndex = indicesForCaptureGroup(match, messageReferenceRegexBareNumberIndex)
+		}
+
+		number, err := strconv.Atoi(text[numberStartIndex:numberEndIndex])
+		if err != nil {
+			continue
+		}
+
+		href, ok := resolve(number)
+		if !ok {
+			continue
+		}
+
+		if err := writeText(w, text[lastIndex:referenceStartIndex]); err != nil {
+			return err
+		}
+
+		if _, err := fmt.Fprintf(w, `<a class="message-reference" href="%s">`, html.EscapeString(href)); err != nil {
+			return err
+		}
+
+		if err := writeText(w, text[referenceStartIndex:referenceEndIndex]); err != nil {
+			return err
+		}
+
+		if _, err := io.WriteString(w, "</a>"); err != nil {
+			return err
+		}
+
+		lastIndex = referenceEndIndex
+	}
+
+	return writeText(w, text[lastIndex:])
+}
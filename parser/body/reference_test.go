@@ -0,0 +1,47 @@
+package body
+
+import (
+	"github.com/acearchive/yg-render/block"
+	"strings"
+	"testing"
+)
+
+func TestRenderMessageReferences(t *testing.T) {
+	resolve := func(number int) (string, bool) {
+		if number == 1234 {
+			return "#message-1234", true
+		}
+
+		return "", false
+	}
+
+	tests := []struct {
+		name     string
+		text     string
+		want     string
+		wantLink bool
+	}{
+		{"resolved", "See message 1234 for details.\n", `See <a class="message-reference" href="#message-1234">message 1234</a> for details.`, true},
+		{"with a number sign", "As in msg #1234, yes.\n", `As in <a class="message-reference" href="#message-1234">msg #1234</a>, yes.`, true},
+		{"post number", "Read post no. 1234 first.\n", `Read <a class="message-reference" href="#message-1234">post no. 1234</a> first.`, true},
+		{"unresolved", "See message 99 for details.\n", "See message 99 for details.", false},
+		{"prose", "I will post 2 photos.\n", "I will post 2 photos.", false},
+		{"in a link", "It's at http://example.com/post1234 now.\n", "http://example.com/post1234", false},
+		{"in an address", "Mail message1234@example.com please.\n", "message1234@example.com", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tokens := tokenizeForTest(t, NewDefaultTokenizer(), test.text)
+			rendered := RenderWithOptions(tokens, block.RenderOptions{ResolveMessageReference: resolve})
+
+			if !strings.Contains(rendered, test.want) {
+				t.Errorf("rendered HTML of %q doesn't contain %q:\n%s", test.text, test.want, rendered)
+			}
+
+			if hasLink := strings.Contains(rendered, `class="message-reference"`); hasLink != test.wantLink {
+				t.Errorf("rendered HTML of %q has a link = %v, want %v:\n%s", test.text, hasLink, test.wantLink, rendered)
+			}
+		})
+	}
+}
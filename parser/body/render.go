@@ -1,7 +1,11 @@
 package body
 
 import (
+	"bytes"
+	"fmt"
+	"github.com/acearchive/yg-render/block"
 	"html"
+	"io"
 	"strings"
 )
 
@@ -24,51 +28,410 @@ func IndentMultilineString(text string, indent int) string {
 	return output.String()
 }
 
-func (StartParagraphToken) ToHtml() string {
-	return "<p>"
+// indentWriter indents each line written to it the same way as
+// `IndentMultilineString`. Call `Close` after each complete chunk of text to
+// terminate the final line.
+type indentWriter struct {
+	writer        io.Writer
+	indent        string
+	pendingIndent bool
 }
 
-func (EndParagraphToken) ToHtml() string {
-	return "</p>"
+func newIndentWriter(w io.Writer, indent int) *indentWriter {
+	return &indentWriter{
+		writer:        w,
+		indent:        strings.Repeat(" ", indent),
+		pendingIndent: true,
+	}
+}
+
+func (w *indentWriter) Write(p []byte) (int, error) {
+	written := 0
+
+	for len(p) > 0 {
+		if w.pendingIndent {
+			if _, err := io.WriteString(w.writer, w.indent); err != nil {
+				return written, err
+			}
+
+			w.pendingIndent = false
+		}
+
+		lineEnd := len(p)
+		if newlineIndex := bytes.IndexByte(p, '\n'); newlineIndex >= 0 {
+			lineEnd = newlineIndex + 1
+			w.pendingIndent = true
+		}
+
+		n, err := w.writer.Write(p[:lineEnd])
+		written += n
+
+		if err != nil {
+			return written, err
+		}
+
+		p = p[lineEnd:]
+	}
+
+	return written, nil
+}
+
+func (w *indentWriter) Close() error {
+	if w.pendingIndent {
+		if _, err := io.WriteString(w.writer, w.indent); err != nil {
+			return err
+		}
+	}
+
+	_, err := io.WriteString(w.writer, "\n")
+
+	return err
+}
+
+func tokenToHtml(token Token) string {
+	var output strings.Builder
+
+	if err := token.WriteHtml(&output, block.RenderOptions{}); err != nil {
+		panic(err)
+	}
+
+	return output.String()
+}
+
+func (StartParagraphToken) WriteHtml(w io.Writer, _ block.RenderOptions) error {
+	_, err := io.WriteString(w, "<p>")
+	return err
+}
+
+func (t StartParagraphToken) ToHtml() string {
+	return tokenToHtml(t)
+}
+
+func (EndParagraphToken) WriteHtml(w io.Writer, _ block.RenderOptions) error {
+	_, err := io.WriteString(w, "</p>")
+	return err
+}
+
+func (t EndParagraphToken) ToHtml() string {
+	return tokenToHtml(t)
+}
+
+func (StartQuoteToken) WriteHtml(w io.Writer, _ block.RenderOptions) error {
+	_, err := io.WriteString(w, "<blockquote>")
+	return err
+}
+
+func (t StartQuoteToken) ToHtml() string {
+	return tokenToHtml(t)
+}
+
+func (EndQuoteToken) WriteHtml(w io.Writer, _ block.RenderOptions) error {
+	_, err := io.WriteString(w, "</blockquote>")
+	return err
+}
+
+func (t EndQuoteToken) ToHtml() string {
+	return tokenToHtml(t)
+}
+
+// replyQuoteToken is rendered in place of a `StartQuoteToken` introduced by
+// an attribution, so that themes can indent quoted replies relative to the new
+// content. `Depth` is the number of replies the quote is nested in,
+// starting at 1.
+type replyQuoteToken struct {
+	Depth int
+
+	// AuthorColor is the color of the author of the quote, when quotes are
+	// tinted by author.
+	AuthorColor string
+}
+
+func (replyQuoteToken) TagType() TagType {
+	return TagTypeOpen
+}
+
+func (t replyQuoteToken) WriteHtml(w io.Writer, _ block.RenderOptions) error {
+	if t.AuthorColor != "" {
+		_, err := fmt.Fprintf(w, `<blockquote class="quote-reply quote-reply-depth-%d quote-reply-author"%s>`, t.Depth, authorColorStyle(t.AuthorColor))
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, `<blockquote class="quote-reply quote-reply-depth-%d">`, t.Depth)
+	return err
+}
+
+// quoteAuthorColor returns the color to tint the quote introduced by
+// `attribution` with, or "" if quotes aren't tinted by author.
+func quoteAuthorColor(attribution *block.AttributionBlock, options block.RenderOptions) string {
+	if options.QuoteAuthorColor == nil || attribution == nil {
+		return ""
+	}
+
+	return options.QuoteAuthorColor(attribution)
 }
 
-func (StartQuoteToken) ToHtml() string {
-	return "<blockquote>"
+// authorColorStyle returns a `style` attribute setting the color of the
+// author of a quote as a CSS variable for themes to use.
+func authorColorStyle(color string) string {
+	return fmt.Sprintf(` style="--quote-author-color: %s"`, html.EscapeString(color))
+}
+
+func (t replyQuoteToken) ToHtml() string {
+	return tokenToHtml(t)
+}
+
+func isAttributionToken(token Token) bool {
+	blockToken, isBlock := token.(BlockToken)
+	if !isBlock {
+		return false
+	}
+
+	_, isAttribution := blockToken.Block.(*block.AttributionBlock)
+
+	return isAttribution
 }
 
-func (EndQuoteToken) ToHtml() string {
-	return "</blockquote>"
+// isHardBreakToken returns whether a token is a hard break, like a "<br>"
+// line.
+func isHardBreakToken(token Token) bool {
+	blockToken, isBlock := token.(BlockToken)
+	if !isBlock {
+		return false
+	}
+
+	_, isBreak := blockToken.Block.(*block.HardBreakBlock)
+
+	return isBreak
+}
+
+// quoteAfterAttribution returns the index of the quote introduced by the
+// attribution at `attributionIndex`, which is the next quote when there's
+// nothing but hard breaks in between. It returns false otherwise. Blank lines
+// never become tokens, so they don't need to be skipped, but a "<br>" line
+// between the attribution and the quote becomes a hard break.
+func quoteAfterAttribution(tokens []Token, attributionIndex int) (quoteIndex int, ok bool) {
+	for i := attributionIndex + 1; i < len(tokens); i++ {
+		if _, isQuote := tokens[i].(StartQuoteToken); isQuote {
+			return i, true
+		}
+
+		if !isHardBreakToken(tokens[i]) {
+			break
+		}
+	}
+
+	return -1, false
+}
+
+// replyQuoteIndices returns the indices of the quotes in `tokens` which are
+// introduced by an attribution, mapped to that attribution.
+func replyQuoteIndices(tokens []Token) map[int]*block.AttributionBlock {
+	indices := make(map[int]*block.AttributionBlock)
+
+	for i, token := range tokens {
+		if !isAttributionToken(token) {
+			continue
+		}
+
+		if quoteIndex, ok := quoteAfterAttribution(tokens, i); ok {
+			indices[quoteIndex] = token.(BlockToken).Block.(*block.AttributionBlock)
+		}
+	}
+
+	return indices
+}
+
+func (b BlockToken) WriteHtml(w io.Writer, options block.RenderOptions) error {
+	return b.Block.WriteHtml(w, options)
 }
 
 func (b BlockToken) ToHtml() string {
 	return b.Block.ToHtml()
 }
 
+// trimLineIndents removes the indentation from each line of `text`, which
+// HTML would collapse anyway.
+func trimLineIndents(text string) string {
+	lines := strings.Split(text, "\n")
+
+	for i, line := range lines {
+		lines[i] = TrimSpaceStart(line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (t TextToken) WriteHtml(w io.Writer, options block.RenderOptions) error {
+	var text string
+	if options.PreserveWhitespace {
+		text = strings.TrimRight(string(t), "\n")
+	} else {
+		text = strings.TrimSpace(trimLineIndents(string(t)))
+	}
+
+	writeText := writeEscapedText
+
+	// Preserved text is usually code or ASCII art, where carets and
+	// underscores aren't scripts.
+	if options.ScriptNotation && !options.PreserveWhitespace {
+		writeText = writeTextWithScripts
+	}
+
+	if resolve := options.ResolveMessageReference; resolve != nil {
+		writeInnerText := writeText
+
+		writeText = func(w io.Writer, text string) error {
+			return writeTextWithMessageReferences(w, text, resolve, writeInnerText)
+		}
+	}
+
+	if len(options.Emoticons) > 0 {
+		return writeTextWithEmoticons(w, text, options.Emoticons, writeText)
+	}
+
+	return writeText(w, text)
+}
+
+func writeEscapedText(w io.Writer, text string) error {
+	_, err := io.WriteString(w, html.EscapeString(text))
+	return err
+}
+
 func (t TextToken) ToHtml() string {
-	return html.EscapeString(strings.TrimSpace(string(t)))
+	return tokenToHtml(t)
 }
 
-func Render(tokens []Token) string {
-	var output strings.Builder
+const (
+	preservedParagraphStartTag = `<pre class="preserved-text">`
+	preservedParagraphEndTag   = "</pre>"
+)
 
-	indentLevel := 0
+// Text only ever appears inside a paragraph.
+func isParagraphToken(token Token) bool {
+	switch token.(type) {
+	case StartParagraphToken, EndParagraphToken, TextToken:
+		return true
+	default:
+		return false
+	}
+}
+
+// writePreservedToken writes the tokens of a paragraph when whitespace is
+// preserved. The contents of a `<pre>` can't be indented like the rest of the
+// markup, so the text is written as-is and the closing tag directly follows
+// it.
+func writePreservedToken(w io.Writer, token Token, indent int, ids *blockIDs, options block.RenderOptions) error {
+	switch token.(type) {
+	case StartParagraphToken:
+		startTag := preservedParagraphStartTag
+		if ids != nil {
+			startTag = withBlockID(startTag, ids)
+		}
 
-	writeToken := func(token Token) {
-		output.WriteString(IndentMultilineString(token.ToHtml(), indentLevel*IndentLen))
+		_, err := io.WriteString(w, strings.Repeat(" ", indent)+startTag+"\n")
+		return err
+	case EndParagraphToken:
+		_, err := io.WriteString(w, preservedParagraphEndTag+"\n")
+		return err
+	default:
+		return token.WriteHtml(w, options)
 	}
+}
+
+func WriteHtml(w io.Writer, tokens []Token, options block.RenderOptions) error {
+	var ids *blockIDs
+	if options.BlockIDPrefix != "" {
+		ids = &blockIDs{prefix: options.BlockIDPrefix}
+	}
+
+	if options.ReplyTree {
+		return writeReplyTreeNodes(w, BuildReplyTree(tokens).Nodes, 0, 0, ids, options)
+	}
+
+	return writeTokens(w, tokens, 0, ids, options)
+}
+
+// writeTokens writes `tokens` starting at `indentLevel`, numbering blocks with
+// `ids` when it's set.
+func writeTokens(w io.Writer, tokens []Token, indentLevel int, ids *blockIDs, options block.RenderOptions) error {
+	writeToken := func(token Token) error {
+		if nodes, isNodes := token.(replyTreeNodesToken); isNodes {
+			return nodes.writeHtml(w, indentLevel, options)
+		}
+
+		if options.PreserveWhitespace && isParagraphToken(token) {
+			return writePreservedToken(w, token, indentLevel*IndentLen, ids, options)
+		}
+
+		indented := newIndentWriter(w, indentLevel*IndentLen)
+
+		if err := writeTokenWithID(indented, token, ids, options); err != nil {
+			return err
+		}
+
+		return indented.Close()
+	}
+
+	// Whether each open quote is a reply to an attribution.
+	var openQuoteIsReply []bool
+	replyDepth := 0
+
+	var replyQuotes map[int]*block.AttributionBlock
+	if options.ReplyQuoteClasses || options.QuoteAuthorColor != nil {
+		replyQuotes = replyQuoteIndices(tokens)
+	}
+
+	for tokenIndex, token := range tokens {
+		switch token.(type) {
+		case StartQuoteToken:
+			attribution, isReply := replyQuotes[tokenIndex]
+			openQuoteIsReply = append(openQuoteIsReply, isReply)
+
+			if isReply {
+				replyDepth++
+				token = replyQuoteToken{Depth: replyDepth, AuthorColor: quoteAuthorColor(attribution, options)}
+			}
+		case EndQuoteToken:
+			if len(openQuoteIsReply) > 0 {
+				if openQuoteIsReply[len(openQuoteIsReply)-1] {
+					replyDepth--
+				}
+
+				openQuoteIsReply = openQuoteIsReply[:len(openQuoteIsReply)-1]
+			}
+		}
 
-	for _, token := range tokens {
 		switch token.TagType() {
 		case TagTypeOpen:
-			writeToken(token)
+			if err := writeToken(token); err != nil {
+				return err
+			}
 			indentLevel++
 		case TagTypeClose:
 			indentLevel--
-			writeToken(token)
+			if err := writeToken(token); err != nil {
+				return err
+			}
 		case TagTypeSelfClose:
-			writeToken(token)
+			if err := writeToken(token); err != nil {
+				return err
+			}
 		}
 	}
 
+	return nil
+}
+
+func RenderWithOptions(tokens []Token, options block.RenderOptions) string {
+	var output strings.Builder
+
+	if err := WriteHtml(&output, tokens, options); err != nil {
+		panic(err)
+	}
+
 	return output.String()
 }
+
+func Render(tokens []Token) string {
+	return RenderWithOptions(tokens, block.RenderOptions{})
+}
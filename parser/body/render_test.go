@@ -0,0 +1,322 @@
+package body
+
+import (
+	"flag"
+	"github.com/acearchive/yg-render/block"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "Write the rendered HTML of golden tests to their files in testdata")
+
+// checkGolden compares `rendered` to the file `name` in testdata, or writes it
+// there with -update.
+func checkGolden(t *testing.T, name string, rendered string) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name)
+
+	if *updateGolden {
+		if err := ioutil.WriteFile(path, []byte(rendered), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		return
+	}
+
+	want, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rendered != string(want) {
+		t.Errorf("rendered HTML doesn't match %s:\n%s", path, rendered)
+	}
+}
+
+const twoLevelReplyMessage = `Sounds good.
+
+On Mon, 2 Jan 2006 15:04, Bob wrote:
+> Let's meet Friday.
+>
+> On Sun, 1 Jan 2006 10:00, Alice wrote:
+> > When should we meet?
+`
+
+func TestRenderReplyQuoteClasses(t *testing.T) {
+	tokens := tokenizeForTest(t, NewDefaultTokenizer(), twoLevelReplyMessage)
+
+	checkGolden(t, "two_level_reply.html", RenderWithOptions(tokens, block.RenderOptions{ReplyQuoteClasses: true}))
+
+	if rendered := Render(tokens); strings.Contains(rendered, "quote-reply") {
+		t.Errorf("quotes have reply classes without ReplyQuoteClasses:\n%s", rendered)
+	}
+}
+
+func TestRenderAttributionFormat(t *testing.T) {
+	tokens := tokenizeForTest(t, NewDefaultTokenizer(), "On Mon, 2 Jan 2006 15:04, Bob wrote:\n> Let's meet Friday.\n")
+
+	checkGolden(t, "attribution_format.html", RenderWithOptions(tokens, block.RenderOptions{IncludeFormat: true}))
+
+	if rendered := Render(tokens); strings.Contains(rendered, "data-format") {
+		t.Errorf("attribution has a format without IncludeFormat:\n%s", rendered)
+	}
+}
+
+const asciiArtMessage = `Here's the layout:
+
+  +------+      +------+
+  | a<b  | ---> |  c   |
+  +------+      +------+
+`
+
+func TestRenderPreserveWhitespace(t *testing.T) {
+	tokens := tokenizeForTest(t, NewDefaultTokenizer(), asciiArtMessage)
+
+	checkGolden(t, "ascii_art_preserved.html", RenderWithOptions(tokens, block.RenderOptions{PreserveWhitespace: true}))
+	checkGolden(t, "ascii_art_collapsed.html", Render(tokens))
+}
+
+// This is a typical reply from an archive, with an attribution, a quote and a
+// signature.
+const benchmarkRenderMessage = `Thanks, that fixed it. I'll send the rest of the files this weekend.
+
+On Mon, Jan 2, 2006 at 3:04 PM, Alice Example <alice@example.com> wrote:
+> Try running it again with the larger files and see whether the
+> problem comes back. If it does, send me the log.
+>
+> > Has anyone else seen this with the new version?
+
+--
+Bob
+`
+
+func benchmarkRenderTokens(b *testing.B) []Token {
+	tokenizer := NewDefaultTokenizer()
+
+	// Archives are rendered a thread at a time, so this is many messages.
+	tokens, err := tokenizer.Tokenize(strings.NewReader(strings.Repeat(benchmarkRenderMessage+"\n", 100)))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	return tokens
+}
+
+// This is how the body was rendered before `WriteHtml`, by indenting and
+// concatenating the HTML string of each token.
+func BenchmarkToHtml(b *testing.B) {
+	tokens := benchmarkRenderTokens(b)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		var output strings.Builder
+
+		indentLevel := 0
+
+		for _, token := range tokens {
+			if token.TagType() == TagTypeClose {
+				indentLevel--
+			}
+
+			output.WriteString(IndentMultilineString(token.ToHtml(), indentLevel*IndentLen))
+
+			if token.TagType() == TagTypeOpen {
+				indentLevel++
+			}
+		}
+	}
+}
+
+func BenchmarkRender(b *testing.B) {
+	tokens := benchmarkRenderTokens(b)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		Render(tokens)
+	}
+}
+
+// Writing to a file or a buffer that's reused doesn't build the whole
+// document as a string, unlike `Render`. Concatenating the HTML of each token
+// was about 1362 KB/op and 12619 allocs/op. Rendering to a string is about
+// 962 KB/op and 7234 allocs/op, and writing is about 275 KB/op and 7211
+// allocs/op.
+func BenchmarkWriteHtml(b *testing.B) {
+	tokens := benchmarkRenderTokens(b)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if err := WriteHtml(io.Discard, tokens, block.RenderOptions{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+var blockIDRegex = regexp.MustCompile(`\bid="([^"]*)"`)
+
+func TestRenderBlockIDs(t *testing.T) {
+	options := block.RenderOptions{BlockIDPrefix: "message-1"}
+	rendered := RenderWithOptions(tokenizeForTest(t, NewDefaultTokenizer(), twoLevelReplyMessage), options)
+
+	checkGolden(t, "block_ids.html", rendered)
+
+	if again := RenderWithOptions(tokenizeForTest(t, NewDefaultTokenizer(), twoLevelReplyMessage), options); again != rendered {
+		t.Errorf("block IDs differ between renders:\n%s\n%s", rendered, again)
+	}
+
+	seen := make(map[string]bool)
+
+	for _, match := range blockIDRegex.FindAllStringSubmatch(rendered, -1) {
+		if seen[match[1]] {
+			t.Errorf("block ID %q is used more than once", match[1])
+		}
+
+		seen[match[1]] = true
+	}
+
+	if len(seen) == 0 {
+		t.Errorf("no block IDs in:\n%s", rendered)
+	}
+}
+
+func TestRenderReplyTree(t *testing.T) {
+	tokens := tokenizeForTest(t, NewDefaultTokenizer(), threeLevelReplyMessage)
+
+	var names []string
+	for tree := BuildReplyTree(tokens); tree != nil; {
+		var reply *ReplyTree
+
+		for _, node := range tree.Nodes {
+			if node.Reply != nil {
+				reply = node.Reply
+			}
+		}
+
+		if reply != nil {
+			names = append(names, reply.Attribution.Name)
+		}
+
+		tree = reply
+	}
+
+	if got, want := strings.Join(names, ", "), "Carol, Bob, Alice"; got != want {
+		t.Errorf("nested replies = %s, want %s", got, want)
+	}
+
+	checkGolden(t, "reply_tree.html", RenderWithOptions(tokens, block.RenderOptions{ReplyTree: true}))
+}
+
+func TestRenderReplyQuoteAfterBlankLine(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		wantReply bool
+	}{
+		{"adjacent", "On Mon, 2 Jan 2006 15:04, Bob wrote:\n> Let's meet Friday.\n", true},
+		{"blank line", "On Mon, 2 Jan 2006 15:04, Bob wrote:\n\n> Let's meet Friday.\n", true},
+		{"several blank lines", "On Mon, 2 Jan 2006 15:04, Bob wrote:\n\n  \n\n> Let's meet Friday.\n", true},
+		{"hard break", "On Mon, 2 Jan 2006 15:04, Bob wrote:\n<br>\n> Let's meet Friday.\n", true},
+		{"text in between", "On Mon, 2 Jan 2006 15:04, Bob wrote:\n\nI disagree.\n\n> Let's meet Friday.\n", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rendered := RenderWithOptions(tokenizeForTest(t, NewDefaultTokenizer(), test.text), block.RenderOptions{ReplyQuoteClasses: true})
+
+			if isReply := strings.Contains(rendered, `<blockquote class="quote-reply`); isReply != test.wantReply {
+				t.Errorf("quote in %q is a reply = %v, want %v:\n%s", test.text, isReply, test.wantReply, rendered)
+			}
+		})
+	}
+}
+
+func TestRenderHeaderFrom(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		golden string
+	}{
+		{"named address", "From: Alice Example <alice@example.com>\nSubject: Lunch\n", "header_from_named.html"},
+		{"bare address", "From: alice@example.com\nSubject: Lunch\n", "header_from_bare.html"},
+		{"redacted address", "From: Alice Example <alice@y...>\nSubject: Lunch\n", "header_from_redacted.html"},
+		{"not an address", "From: Alice Example\nSubject: Lunch\n", "header_from_name.html"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checkGolden(t, test.golden, Render(tokenizeForTest(t, NewDefaultTokenizer(), test.text)))
+		})
+	}
+}
+
+func TestRenderPgpArmor(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		golden string
+	}{
+		{
+			name:   "signature",
+			text:   "Signed.\n\n-----BEGIN PGP SIGNATURE-----\nVersion: GnuPG v1\n\niD8DBQFD\n-----END PGP SIGNATURE-----\n",
+			golden: "pgp_signature.html",
+		},
+		{
+			// The armor has a line which looks like a divider, which isn't
+			// parsed.
+			name:   "public key",
+			text:   "My key:\n\n-----BEGIN PGP PUBLIC KEY BLOCK-----\nVersion: GnuPG v1\n\nmQGiBD+/x\n-----\n=Ab12\n-----END PGP PUBLIC KEY BLOCK-----\n",
+			golden: "pgp_public_key.html",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checkGolden(t, test.golden, Render(tokenizeForTest(t, NewDefaultTokenizer(), test.text)))
+		})
+	}
+}
+
+func TestRenderCompactAttribution(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		golden string
+	}{
+		{"with a time", "On Mon, 2 Jan 2006 15:04, Bob wrote:\n> Let's meet Friday.\n", "compact_attribution_time.html"},
+		{"without a time", "Bob <bob@example.com> wrote:\n> Let's meet Friday.\n", "compact_attribution_name.html"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tokens := tokenizeForTest(t, NewDefaultTokenizer(), test.text)
+
+			checkGolden(t, test.golden, RenderWithOptions(tokens, block.RenderOptions{CompactAttributions: true}))
+		})
+	}
+}
+
+func TestRenderQuotedBlankLineRun(t *testing.T) {
+	run := tokenizeForTest(t, NewDefaultTokenizer(), "> First.\n>\n>\n>\n>\n>\n> Second.\n")
+	single := tokenizeForTest(t, NewDefaultTokenizer(), "> First.\n>\n> Second.\n")
+
+	if got, want := describeTokens(run), `quote p "First.\n" /p p "Second.\n" /p /quote`; got != want {
+		t.Errorf("tokens:\ngot  %s\nwant %s", got, want)
+	}
+
+	for _, options := range []block.RenderOptions{{}, {PreserveWhitespace: true}} {
+		if got, want := RenderWithOptions(run, options), RenderWithOptions(single, options); got != want {
+			t.Errorf("five blank quoted lines with %+v render as:\n%s\nwant:\n%s", options, got, want)
+		}
+	}
+}
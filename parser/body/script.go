@@ -0,0 +1,144 @@
+package body
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var (
+	// A superscript or subscript is either a group in braces, like "x^{n+1}",
+	// or a single token, which is a number or a letter on its own, like "x^2",
+	// "x^-1", or "x_i". Groups can't contain whitespace so that scripts never
+	// span words.
+	scriptRegex = regexp.MustCompile(`[\p{L}\p{N})]([\^_])(?:\{([^{}\s]+)\}|([-+]?\p{N}+)|(\p{L})(?:[^\p{L}\p{N}]|$))`)
+
+	// Text in backticks is usually code, which is left as-is.
+	codeSpanRegex = regexp.MustCompile("`[^`\n]+`")
+
+	// Subscripts are only allowed after a single letter, like "x_1", or what
+	// looks like a chemical formula, like "H_2O" or "Fe_2O_3", since
+	// underscores are common in identifiers like "file_2".
+	subscriptBaseRegex = regexp.MustCompile(`^(?:\p{L}|(?:\p{Lu}\p{Ll}?)+)$`)
+)
+
+const (
+	scriptRegexMarkerIndex = 1
+	scriptRegexGroupIndex  = 2
+	scriptRegexNumberIndex = 3
+	scriptRegexLetterIndex = 4
+)
+
+// looksLikeAddress returns whether a word is a URL, email address, or path,
+// where carets and underscores aren't scripts.
+func looksLikeAddress(word string) bool {
+	return strings.Contains(word, "://") || strings.ContainsAny(word, `@/\`)
+}
+
+func wordContaining(text string, index int) string {
+	startIndex := strings.LastIndexFunc(text[:index], unicode.IsSpace) + 1
+
+	endIndex := strings.IndexFunc(text[index:], unicode.IsSpace)
+	if endIndex < 0 {
+		endIndex = len(text)
+	} else {
+		endIndex += index
+	}
+
+	return text[startIndex:endIndex]
+}
+
+// letterRunBefore returns the letters immediately before `index`.
+func letterRunBefore(text string, index int) string {
+	return text[strings.LastIndexFunc(text[:index], func(r rune) bool { return !unicode.IsLetter(r) })+1 : index]
+}
+
+// writeTextWithScripts writes `text` with notations like "x^2" and "H_2O" as
+// superscripts and subscripts, escaping everything else.
+func writeTextWithScripts(w io.Writer, text string) error {
+	lastIndex := 0
+
+	for _, match := range codeSpanRegex.FindAllStringIndex(text, -1) {
+		codeStartIndex, codeEndIndex := match[0], match[1]
+
+		if err := writeScripts(w, text[lastIndex:codeStartIndex]); err != nil {
+			return err
+		}
+
+		if _, err := io.WriteString(w, html.EscapeString(text[codeStartIndex:codeEndIndex])); err != nil {
+			return err
+		}
+
+		lastIndex = codeEndIndex
+	}
+
+	return writeScripts(w, text[lastIndex:])
+}
+
+func writeScripts(w io.Writer, text string) error {
+	lastIndex := 0
+
+	for searchIndex := 0; searchIndex < len(text); {
+		match := scriptRegex.FindStringSubmatchIndex(text[searchIndex:])
+		if match == nil {
+			break
+		}
+
+		for i := range match {
+			if match[i] >= 0 {
+				match[i] += searchIndex
+			}
+		}
+
+		markerIndex := match[2*scriptRegexMarkerIndex]
+		isSubscript := text[markerIndex] == '_'
+
+		contentStartIndex, contentEndIndex := indicesForCaptureGroup(match, scriptRegexGroupIndex)
+		scriptEndIndex := contentEndIndex + len("}")
+
+		if contentStartIndex < 0 {
+			contentStartIndex, contentEndIndex = indicesForCaptureGroup(match, scriptRegexNumberIndex)
+			scriptEndIndex = contentEndIndex
+		}
+
+		if contentStartIndex < 0 {
+			contentStartIndex, contentEndIndex = indicesForCaptureGroup(match, scriptRegexLetterIndex)
+			scriptEndIndex = contentEndIndex
+		}
+
+		if looksLikeAddress(wordContaining(text, markerIndex)) ||
+			(isSubscript && !subscriptBaseRegex.MatchString(letterRunBefore(text, markerIndex))) {
+			searchIndex = markerIndex + 1
+			continue
+		}
+
+		tag := "sup"
+		if isSubscript {
+			tag = "sub"
+		}
+
+		if _, err := fmt.Fprintf(
+			w,
+			"%s<%s>%s</%s>",
+			html.EscapeString(text[lastIndex:markerIndex]),
+			tag,
+			html.EscapeString(text[contentStartIndex:contentEndIndex]),
+			tag,
+		); err != nil {
+			return err
+		}
+
+		lastIndex, searchIndex = scriptEndIndex, scriptEndIndex
+	}
+
+	_, err := io.WriteString(w, html.EscapeString(text[lastIndex:]))
+
+	return err
+}
+
+func indicesForCaptureGroup(match []int, group int) (startIndex, endIndex int) {
+	return match[2*group], match[2*group+1]
+}
@@ -0,0 +1,46 @@
+package body
+
+import (
+	"github.com/acearchive/yg-render/block"
+	"strings"
+	"testing"
+)
+
+func TestRenderScriptNotation(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"superscript", "Solve x^2 = 4\n", "Solve x<sup>2</sup> = 4"},
+		{"negative superscript", "It's 10^-3 meters\n", "It&#39;s 10<sup>-3</sup> meters"},
+		{"braced group", "Then x^{n+1} grows\n", "Then x<sup>n+1</sup> grows"},
+		{"letter superscript", "Take e^x twice\n", "Take e<sup>x</sup> twice"},
+		{"chemical formula", "Add H_2O and Fe_2O_3\n", "Add H<sub>2</sub>O and Fe<sub>2</sub>O<sub>3</sub>"},
+		{"letter subscript", "Let x_i be\n", "Let x<sub>i</sub> be"},
+		{"identifier", "Open file_2 now\n", "Open file_2 now"},
+		{"link", "See http://example.com/a^2 now\n", "http://example.com/a^2"},
+		{"code", "Run `x^2` here\n", "Run `x^2` here"},
+		{"word after caret", "Read x^two here\n", "Read x^two here"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tokens := tokenizeForTest(t, NewDefaultTokenizer(), test.text)
+
+			if rendered := RenderWithOptions(tokens, block.RenderOptions{ScriptNotation: true}); !strings.Contains(rendered, test.want) {
+				t.Errorf("rendered HTML of %q doesn't contain %q:\n%s", test.text, test.want, rendered)
+			}
+		})
+	}
+
+	tokens := tokenizeForTest(t, NewDefaultTokenizer(), "Solve x^2 = 4\n")
+
+	if rendered := Render(tokens); strings.Contains(rendered, "<sup>") {
+		t.Errorf("scripts are marked up without ScriptNotation:\n%s", rendered)
+	}
+
+	if rendered := RenderWithOptions(tokens, block.RenderOptions{ScriptNotation: true, PreserveWhitespace: true}); strings.Contains(rendered, "<sup>") {
+		t.Errorf("scripts are marked up in preserved text:\n%s", rendered)
+	}
+}
@@ -0,0 +1,60 @@
+package body
+
+import "github.com/acearchive/yg-render/block"
+
+// QuotedAttribution is an attribution along with how deeply it's quoted in
+// the message body.
+type QuotedAttribution struct {
+	Attribution *block.AttributionBlock
+	QuoteDepth  int
+}
+
+// ThreadSkeleton returns the attributions in the body in the order they
+// appear, which for a reply that quotes the whole thread is the ancestry of
+// the message: the attribution at depth 0 introduces the parent message, the
+// one at depth 1 the grandparent, and so on.
+func ThreadSkeleton(tokens []Token) []QuotedAttribution {
+	var skeleton []QuotedAttribution
+
+	quoteDepth := 0
+
+	for _, token := range tokens {
+		switch concreteToken := token.(type) {
+		case StartQuoteToken:
+			quoteDepth++
+		case EndQuoteToken:
+			quoteDepth--
+		case BlockToken:
+			if attribution, isAttribution := concreteToken.Block.(*block.AttributionBlock); isAttribution {
+				skeleton = append(skeleton, QuotedAttribution{
+					Attribution: attribution,
+					QuoteDepth:  quoteDepth + attribution.QuoteDepth,
+				})
+			}
+		}
+	}
+
+	return skeleton
+}
+
+// SplitAtScissorLine splits the body at its first unquoted scissor line, like
+// "--- 8< ---", returning the tokens on either side of it. It returns false if
+// there is no such line.
+func SplitAtScissorLine(tokens []Token) (above, below []Token, ok bool) {
+	quoteDepth := 0
+
+	for i, token := range tokens {
+		switch concreteToken := token.(type) {
+		case StartQuoteToken:
+			quoteDepth++
+		case EndQuoteToken:
+			quoteDepth--
+		case BlockToken:
+			if _, isScissor := concreteToken.Block.(*block.ScissorBlock); isScissor && quoteDepth == 0 {
+				return tokens[:i], tokens[i+1:], true
+			}
+		}
+	}
+
+	return tokens, nil, false
+}
@@ -0,0 +1,72 @@
+package body
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const threeLevelReplyMessage = `Sounds good to me.
+
+On Tue, 3 Jan 2006 09:30, Carol wrote:
+> Friday works.
+>
+> On Mon, 2 Jan 2006 15:04, Bob wrote:
+> > Let's meet Friday.
+> >
+> > On Sun, 1 Jan 2006 10:00, Alice wrote:
+> > > When should we meet?
+`
+
+func TestThreadSkeleton(t *testing.T) {
+	skeleton := ThreadSkeleton(tokenizeForTest(t, NewDefaultTokenizer(), threeLevelReplyMessage))
+
+	descriptions := make([]string, len(skeleton))
+	for i, quoted := range skeleton {
+		descriptions[i] = fmt.Sprintf("%s@%d", quoted.Attribution.Name, quoted.QuoteDepth)
+	}
+
+	if got, want := strings.Join(descriptions, " "), "Carol@0 Bob@1 Alice@2"; got != want {
+		t.Errorf("ThreadSkeleton() = %s, want %s", got, want)
+	}
+}
+
+func TestSplitAtScissorLine(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		wantOk    bool
+		wantAbove string
+		wantBelow string
+	}{
+		{
+			name:      "unquoted",
+			text:      "Discussion.\n-- >8 --\nPatch.\n",
+			wantOk:    true,
+			wantAbove: `p "Discussion.\n" /p`,
+			wantBelow: `p "Patch.\n" /p`,
+		},
+		{
+			name:      "only quoted",
+			text:      "> Discussion.\n> -- >8 --\n> Patch.\n",
+			wantAbove: `quote p "Discussion.\n" /p *block.ScissorBlock p "Patch.\n" /p /quote`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			above, below, ok := SplitAtScissorLine(tokenizeForTest(t, NewDefaultTokenizer(), test.text))
+			if ok != test.wantOk {
+				t.Fatalf("ok = %v, want %v", ok, test.wantOk)
+			}
+
+			if got := describeTokens(above); got != test.wantAbove {
+				t.Errorf("above:\ngot  %s\nwant %s", got, test.wantAbove)
+			}
+
+			if got := describeTokens(below); got != test.wantBelow {
+				t.Errorf("below:\ngot  %s\nwant %s", got, test.wantBelow)
+			}
+		})
+	}
+}
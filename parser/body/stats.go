@@ -0,0 +1,52 @@
+package body
+
+import (
+	"github.com/acearchive/yg-render/block"
+	"strings"
+	"unicode/utf8"
+)
+
+// BlockKindParagraph is the kind of a `BlockStat` for a paragraph of text,
+// which isn't a `block.Block`.
+const BlockKindParagraph block.Kind = "Paragraph"
+
+// BlockStat describes one paragraph or block in a message body.
+type BlockStat struct {
+	Kind       block.Kind
+	QuoteDepth int
+
+	// Length is the number of runes of meaningful content. For paragraphs,
+	// runs of whitespace count as a single space.
+	Length int
+}
+
+// Stats returns a `BlockStat` for each paragraph and block in the body, in
+// the order they appear.
+func Stats(tokens []Token) []BlockStat {
+	var stats []BlockStat
+
+	quoteDepth := 0
+
+	for _, token := range tokens {
+		switch concreteToken := token.(type) {
+		case StartQuoteToken:
+			quoteDepth++
+		case EndQuoteToken:
+			quoteDepth--
+		case TextToken:
+			stats = append(stats, BlockStat{
+				Kind:       BlockKindParagraph,
+				QuoteDepth: quoteDepth,
+				Length:     utf8.RuneCountInString(strings.Join(strings.Fields(string(concreteToken)), " ")),
+			})
+		case BlockToken:
+			stats = append(stats, BlockStat{
+				Kind:       block.KindOf(concreteToken.Block),
+				QuoteDepth: quoteDepth,
+				Length:     block.ContentLength(concreteToken.Block),
+			})
+		}
+	}
+
+	return stats
+}
@@ -0,0 +1,27 @@
+package body
+
+import (
+	"github.com/acearchive/yg-render/block"
+	"reflect"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	text := "Sounds   good.\n\n" +
+		"On Mon, 2 Jan 2006 15:04, Alice wrote:\n" +
+		"> Should we meet?\n\n" +
+		"-----\n\n" +
+		"This email is confidential.\n"
+
+	want := []BlockStat{
+		{Kind: BlockKindParagraph, Length: len("Sounds good.")},
+		{Kind: block.KindAttribution, Length: len("Alice")},
+		{Kind: BlockKindParagraph, QuoteDepth: 1, Length: len("Should we meet?")},
+		{Kind: block.KindDivider},
+		{Kind: block.KindDisclaimer, Length: len("This email is confidential.")},
+	}
+
+	if got := Stats(tokenizeForTest(t, NewDefaultTokenizer(), text)); !reflect.DeepEqual(got, want) {
+		t.Errorf("Stats(%q) = %+v, want %+v", text, got, want)
+	}
+}
@@ -0,0 +1,180 @@
+package body
+
+import (
+	"fmt"
+	"github.com/acearchive/yg-render/block"
+	"io"
+)
+
+// ReplyTree is a message body with each attribution and the quote after it
+// nested as a reply, so a body which quotes the whole thread becomes a tree of
+// the messages in it.
+type ReplyTree struct {
+	// Attribution introduces the quoted message. It's nil for the root.
+	Attribution *block.AttributionBlock
+
+	Nodes []ReplyTreeNode
+}
+
+// ReplyTreeNode is either a run of tokens or a nested reply.
+type ReplyTreeNode struct {
+	Tokens []Token
+	Reply  *ReplyTree
+}
+
+// matchingEndQuote returns the index of the `EndQuoteToken` which closes the
+// quote opened at `startIndex`, or -1 if it's never closed.
+func matchingEndQuote(tokens []Token, startIndex int) int {
+	depth := 0
+
+	for i := startIndex; i < len(tokens); i++ {
+		switch tokens[i].(type) {
+		case StartQuoteToken:
+			depth++
+		case EndQuoteToken:
+			depth--
+
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+// BuildReplyTree nests each attribution in `tokens` which is followed by a
+// quote, along with that quote, as a reply. Blank lines between them are
+// dropped. Other quotes are left as-is.
+func BuildReplyTree(tokens []Token) *ReplyTree {
+	return buildReplyTree(nil, tokens)
+}
+
+func buildReplyTree(attribution *block.AttributionBlock, tokens []Token) *ReplyTree {
+	tree := &ReplyTree{Attribution: attribution}
+	runStartIndex := 0
+
+	for i := 0; i < len(tokens); i++ {
+		if !isAttributionToken(tokens[i]) {
+			continue
+		}
+
+		quoteIndex, isReply := quoteAfterAttribution(tokens, i)
+		if !isReply {
+			continue
+		}
+
+		endIndex := matchingEndQuote(tokens, quoteIndex)
+		if endIndex < 0 {
+			break
+		}
+
+		if runStartIndex < i {
+			tree.Nodes = append(tree.Nodes, ReplyTreeNode{Tokens: tokens[runStartIndex:i]})
+		}
+
+		replyAttribution := tokens[i].(BlockToken).Block.(*block.AttributionBlock)
+		tree.Nodes = append(tree.Nodes, ReplyTreeNode{Reply: buildReplyTree(replyAttribution, tokens[quoteIndex+1:endIndex])})
+
+		i = endIndex
+		runStartIndex = endIndex + 1
+	}
+
+	if runStartIndex < len(tokens) {
+		tree.Nodes = append(tree.Nodes, ReplyTreeNode{Tokens: tokens[runStartIndex:]})
+	}
+
+	return tree
+}
+
+// WriteReplyTreeHtml writes `tree` with each reply in a container holding its
+// attribution and the quoted message, which are nested for each level of
+// replies.
+func WriteReplyTreeHtml(w io.Writer, tree *ReplyTree, options block.RenderOptions) error {
+	var ids *blockIDs
+	if options.BlockIDPrefix != "" {
+		ids = &blockIDs{prefix: options.BlockIDPrefix}
+	}
+
+	return writeReplyTreeNodes(w, tree.Nodes, 0, 0, ids, options)
+}
+
+func writeIndentedString(w io.Writer, text string, indentLevel int) error {
+	indented := newIndentWriter(w, indentLevel*IndentLen)
+
+	if _, err := io.WriteString(indented, text); err != nil {
+		return err
+	}
+
+	return indented.Close()
+}
+
+// replyTreeNodesToken is rendered as the nodes of a reply nested in a quote,
+// so that `writeTokens` writes them at the quote's indent level. It's written
+// by `writeReplyTreeNodes` rather than through an indenting writer, so that
+// preserved text in it isn't indented.
+type replyTreeNodesToken struct {
+	Nodes []ReplyTreeNode
+	Depth int
+	ids   *blockIDs
+}
+
+func (replyTreeNodesToken) TagType() TagType {
+	return TagTypeSelfClose
+}
+
+func (t replyTreeNodesToken) WriteHtml(w io.Writer, options block.RenderOptions) error {
+	return t.writeHtml(w, 0, options)
+}
+
+func (t replyTreeNodesToken) writeHtml(w io.Writer, indentLevel int, options block.RenderOptions) error {
+	return writeReplyTreeNodes(w, t.Nodes, t.Depth, indentLevel, t.ids, options)
+}
+
+func (t replyTreeNodesToken) ToHtml() string {
+	return tokenToHtml(t)
+}
+
+func writeReplyTreeNodes(w io.Writer, nodes []ReplyTreeNode, depth, indentLevel int, ids *blockIDs, options block.RenderOptions) error {
+	for _, node := range nodes {
+		if node.Reply == nil {
+			if err := writeTokens(w, node.Tokens, indentLevel, ids, options); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		container := fmt.Sprintf(`<div class="reply-tree reply-tree-depth-%d">`, depth+1)
+		if color := quoteAuthorColor(node.Reply.Attribution, options); color != "" {
+			container = fmt.Sprintf(`<div class="reply-tree reply-tree-depth-%d reply-tree-author"%s>`, depth+1, authorColorStyle(color))
+		}
+
+		if err := writeIndentedString(w, container, indentLevel); err != nil {
+			return err
+		}
+
+		if err := writeTokens(w, []Token{BlockToken{node.Reply.Attribution}}, indentLevel+1, ids, options); err != nil {
+			return err
+		}
+
+		// The quote is written apart from the attribution so that it isn't
+		// rendered like a reply quote, which the container already stands in
+		// for.
+		quote := []Token{StartQuoteToken{}}
+		if len(node.Reply.Nodes) > 0 {
+			quote = append(quote, replyTreeNodesToken{Nodes: node.Reply.Nodes, Depth: depth + 1, ids: ids})
+		}
+		quote = append(quote, EndQuoteToken{})
+
+		if err := writeTokens(w, quote, indentLevel+1, ids, options); err != nil {
+			return err
+		}
+
+		if err := writeIndentedString(w, "</div>", indentLevel); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
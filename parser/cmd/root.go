@@ -3,6 +3,7 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"github.com/acearchive/yg-render/block"
 	"github.com/acearchive/yg-render/logger"
 	"github.com/acearchive/yg-render/parse"
 	"github.com/acearchive/yg-render/render"
@@ -10,9 +11,13 @@ import (
 	"io/ioutil"
 	"os"
 	"strings"
+	"time"
 )
 
-var ErrInvalidLinkInput = errors.New("malformed --link input")
+var (
+	ErrInvalidLinkInput  = errors.New("malformed --link input")
+	ErrInvalidDateLocale = errors.New("invalid --date-locale")
+)
 
 var (
 	flagPageSize    int
@@ -25,6 +30,38 @@ var (
 	flagLinks       []string
 	flagLocale      string
 	flagDescription string
+	flagDecodeQP    bool
+	flagDebugFormat bool
+	flagEmoticons   bool
+	flagRelative    bool
+	flagEmptyFields bool
+	flagDayFirst    bool
+	flagDotMonth    bool
+	flagRawSentinel string
+	flagTemplate    string
+	flagPreserve    bool
+	flagCollapse    bool
+	flagHeading     int
+	flagKeepTimes   bool
+	flagCitations   bool
+	flagTables      bool
+	flagAvatars     bool
+	flagScissors    []string
+	flagTruncations []string
+	flagLenient     bool
+	flagQuoteMarks  string
+	flagBlockIDs    bool
+	flagCanonical   bool
+	flagReplyTree   bool
+	flagHeaderOrder bool
+	flagScripts     bool
+	flagCompact     bool
+	flagDiffQuotes  bool
+	flagFooterLines int
+	flagDateLocale  string
+	flagQuoteColors bool
+	flagReplyQuotes bool
+	flagDetectHtml  bool
 )
 
 const (
@@ -42,11 +79,56 @@ func init() {
 	rootCmd.Flags().StringArrayVar(&flagLinks, "link", nil, "Add a link to the top of the page in the generated site")
 	rootCmd.Flags().StringVar(&flagLocale, "locale", "en_US", "The locale of the generated site")
 	rootCmd.Flags().StringVar(&flagDescription, "description", "", "Override the default site description for search results and social previews")
+	rootCmd.Flags().BoolVar(&flagDecodeQP, "decode-quoted-printable", false, "Decode quoted-printable escapes in message bodies that don't declare that encoding")
+	rootCmd.Flags().BoolVar(&flagDebugFormat, "debug-formats", false, "Annotate parsed markup in the generated HTML with the format it was parsed from")
+	rootCmd.Flags().BoolVar(&flagEmoticons, "emoticons", false, "Mark up emoticons like :-) in message bodies so they can be styled and announced by screen readers")
+	rootCmd.Flags().BoolVar(&flagRelative, "relative-times", false, "Show times in quoted attributions relative to when the site was generated")
+	rootCmd.Flags().BoolVar(&flagDetectHtml, "detect-html-bodies", false, "Convert plain text message bodies which look like HTML documents to text, not just ones labeled as HTML")
+	rootCmd.Flags().BoolVar(&flagEmptyFields, "empty-header-fields", false, "Include fields with no value in quoted message headers")
+	rootCmd.Flags().BoolVar(&flagLenient, "lenient-attributions", false, "Also recognize quote attributions which are prone to false positives, like \"FROM JOHNDOE WROTE:\"")
+	rootCmd.Flags().StringVar(&flagQuoteMarks, "extra-quote-markers", "", "Characters to accept as quote markers in addition to \">\", like \"|\"")
+	rootCmd.Flags().BoolVar(&flagCanonical, "canonicalize-emails", false, "Lowercase the domains of email addresses and strip their brackets so the same address always compares equal")
+	rootCmd.Flags().IntVar(&flagFooterLines, "footer-window", 0, "Only strip disclaimers which start within this many lines of the end of a message, so one quoted earlier in the message is kept; 0 strips them anywhere")
+	rootCmd.Flags().BoolVar(&flagDayFirst, "day-first-dates", false, "Read slashed numeric dates in quoted attributions as DD/MM instead of MM/DD")
+	rootCmd.Flags().BoolVar(&flagDotMonth, "month-first-dotted-dates", false, "Read numeric dates with periods in quoted attributions as MM.DD instead of DD.MM")
+	rootCmd.Flags().StringVar(&flagRawSentinel, "raw-html-sentinel", "", "Pass HTML between lines consisting of only this text through verbatim; only use this with trusted archives")
+	rootCmd.Flags().BoolVar(&flagTables, "ascii-tables", false, "Render tables drawn with \"|\" and \"+---+\" borders in message bodies as HTML tables")
+	rootCmd.Flags().StringArrayVar(&flagScissors, "scissor-marker", nil, "Recognize lines of dashes containing this text as scissor lines instead of the defaults, like \"8<\"; pass more than once for more markers")
+	rootCmd.Flags().StringArrayVar(&flagTruncations, "truncation-marker", nil, "Recognize lines like \"--- this text ---\" or \"[this text]\" as notices that the message was truncated instead of the defaults, like \"Message truncated\"; pass more than once for more markers")
+	rootCmd.Flags().BoolVar(&flagScripts, "script-notation", false, "Render notations like \"x^2\" and \"H_2O\" in message bodies as superscripts and subscripts")
+	rootCmd.Flags().StringVar(&flagDateLocale, "date-locale", "", "Show dates in quoted attributions the way they're written in this language, one of \"en\", \"de\", or \"fr\", regardless of how they were written in the message")
+	rootCmd.Flags().BoolVar(&flagCompact, "compact-attributions", false, "Show attributions as a short line with the name and date, like \"Alice • Jan 2, 2006\"")
+	rootCmd.Flags().BoolVar(&flagPreserve, "preserve-whitespace", false, "Render message text as preformatted, keeping its line breaks and indentation")
+	rootCmd.Flags().BoolVar(&flagCollapse, "collapse-repeated-quotes", false, "Replace quotes of messages shown earlier in the thread with a link back to them")
+	rootCmd.Flags().BoolVar(&flagDiffQuotes, "diff-quotes", false, "Show which lines of a quote differ from the message it replies to")
+	rootCmd.Flags().BoolVar(&flagReplyQuotes, "reply-quote-classes", false, "Mark each quote introduced by an attribution with classes for how deeply it's nested, so themes can indent quoted replies")
+	rootCmd.Flags().BoolVar(&flagQuoteColors, "color-quotes-by-author", false, "Tint each quoted reply with a color for its author, which is the same throughout the thread")
+	rootCmd.Flags().BoolVar(&flagHeaderOrder, "canonical-header-order", false, "Show the fields of quoted message headers in a consistent order, starting with From, Sent, To, Cc, and Subject")
+	rootCmd.Flags().BoolVar(&flagReplyTree, "reply-tree", false, "Nest each quoted reply and its attribution inside the reply that quotes it")
+	rootCmd.Flags().BoolVar(&flagBlockIDs, "block-ids", false, "Give each paragraph, quote, and block in a message an ID for linking to it")
+	rootCmd.Flags().BoolVar(&flagAvatars, "avatars", false, "Show a placeholder avatar with each sender's initials next to their messages")
+	rootCmd.Flags().BoolVar(&flagCitations, "footnote-citations", false, "Replace quote attributions with numbered citations and list them as footnotes at the end of each page")
+	rootCmd.Flags().IntVar(&flagHeading, "heading-level", 1, "The level of the top heading on each page, for embedding pages in a larger document")
+	rootCmd.Flags().StringVar(&flagTemplate, "template", "", "The path of a custom Go template to render each page with instead of the default")
+	rootCmd.Flags().BoolVar(&flagKeepTimes, "keep-parsed-times", false, "Show times in quoted attributions with the offset they were written with instead of in UTC")
 	rootCmd.Flags().StringVarP(&flagOutput, "output", "o", DefaultOutputPath, "The directory to write the generated HTML to")
 	rootCmd.Flags().StringVarP(&flagBase, "base", "b", DefaultBasePath, "The base URL for the generated site")
 	rootCmd.Flags().BoolVarP(&flagVerbose, "verbose", "v", false, "Print verbose output.")
 }
 
+func parseDateLocale(input string) (*block.DateLocale, error) {
+	if input == "" {
+		return nil, nil
+	}
+
+	locale, ok := block.DateLocales[input]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidDateLocale, input)
+	}
+
+	return &locale, nil
+}
+
 func parseLinkInputs(inputs []string) ([]render.ExternalLinkConfig, error) {
 	configs := make([]render.ExternalLinkConfig, len(inputs))
 
@@ -78,7 +160,27 @@ var rootCmd = &cobra.Command{
 			logger.Verbose.SetOutput(ioutil.Discard)
 		}
 
-		thread, err := parse.Directory(args[0])
+		inputConfig := parse.InputConfig{
+			DecodeQuotedPrintable:  flagDecodeQP,
+			AllowEmptyHeaderFields: flagEmptyFields,
+			DayFirstDates:          flagDayFirst,
+			DottedMonthFirstDates:  flagDotMonth,
+			RawHtmlSentinel:        flagRawSentinel,
+			KeepParsedTimes:        flagKeepTimes,
+			AsciiTables:            flagTables,
+			ScissorMarkers:         flagScissors,
+			TruncationMarkers:      flagTruncations,
+			LenientAttributions:    flagLenient,
+			ExtraQuoteMarkers:      flagQuoteMarks,
+			FooterWindow:           flagFooterLines,
+			DetectHtmlBodies:       flagDetectHtml,
+		}
+
+		if flagCanonical {
+			inputConfig.CanonicalizeEmail = block.CanonicalizeEmail
+		}
+
+		thread, err := parse.Directory(args[0], inputConfig)
 		if err != nil {
 			return err
 		}
@@ -88,15 +190,64 @@ var rootCmd = &cobra.Command{
 			return err
 		}
 
+		dateLocale, err := parseDateLocale(flagDateLocale)
+		if err != nil {
+			return err
+		}
+
+		var emoticons []block.Emoticon
+		if flagEmoticons {
+			emoticons = block.DefaultEmoticons
+		}
+
+		var relativeTimeNow time.Time
+		if flagRelative {
+			relativeTimeNow = time.Now()
+		}
+
 		config := render.OutputConfig{
-			Title:             flagTitle,
-			CustomDescription: flagDescription,
-			PageSize:          flagPageSize,
-			IncludeSearch:     !flagNoSearch,
-			BaseUrl:           flagBase,
-			AddRepoLink:       !flagNoRepo,
-			Links:             linkConfigs,
-			Locale:            flagLocale,
+			Title:                  flagTitle,
+			CustomDescription:      flagDescription,
+			PageSize:               flagPageSize,
+			IncludeSearch:          !flagNoSearch,
+			BaseUrl:                flagBase,
+			AddRepoLink:            !flagNoRepo,
+			Links:                  linkConfigs,
+			Locale:                 flagLocale,
+			CollapseRepeatedQuotes: flagCollapse,
+			DiffQuotes:             flagDiffQuotes,
+			ColorQuotesByAuthor:    flagQuoteColors,
+			BaseHeadingLevel:       flagHeading,
+			Avatars:                flagAvatars,
+			BlockIDs:               flagBlockIDs,
+			BlockOptions: block.RenderOptions{
+				IncludeFormat:        flagDebugFormat,
+				Emoticons:            emoticons,
+				RelativeTimeNow:      relativeTimeNow,
+				PreserveWhitespace:   flagPreserve,
+				ReplyTree:            flagReplyTree,
+				ReplyQuoteClasses:    flagReplyQuotes,
+				CanonicalHeaderOrder: flagHeaderOrder,
+				ScriptNotation:       flagScripts,
+				CompactAttributions:  flagCompact,
+				DateLocale:           dateLocale,
+			},
+		}
+
+		if flagCitations {
+			config.Citations = render.CiteAncestorByName
+		}
+
+		if flagTemplate != "" {
+			templateText, err := ioutil.ReadFile(flagTemplate)
+			if err != nil {
+				return err
+			}
+
+			config.Template, err = render.ParseTemplate(string(templateText))
+			if err != nil {
+				return err
+			}
 		}
 
 		if err := render.Execute(flagOutput, config, thread); err != nil {
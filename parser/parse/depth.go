@@ -0,0 +1,84 @@
+package parse
+
+import (
+	"github.com/acearchive/yg-render/body"
+	"strings"
+)
+
+// QuoteSegment is a run of paragraphs in a message body at the same quote
+// depth, where 0 is text the author wrote and 1 is a quote of another
+// message.
+type QuoteSegment struct {
+	Depth int
+
+	// Text is the text of each paragraph, separated by blank lines.
+	Text string
+}
+
+// QuoteSegments splits a message body into runs of paragraphs at the same
+// quote depth, in the order they appear. Blocks, like attributions and quoted
+// headers, aren't included in the text.
+func QuoteSegments(tokens []body.Token) []QuoteSegment {
+	var (
+		segments         []QuoteSegment
+		paragraphs       []string
+		currentParagraph []string
+	)
+
+	quoteDepth, segmentDepth := 0, 0
+
+	endParagraph := func() {
+		if text := strings.Join(currentParagraph, "\n"); strings.TrimSpace(text) != "" {
+			paragraphs = append(paragraphs, strings.Trim(text, "\n"))
+		}
+
+		currentParagraph = nil
+	}
+
+	endSegment := func() {
+		endParagraph()
+
+		if len(paragraphs) > 0 {
+			segments = append(segments, QuoteSegment{Depth: segmentDepth, Text: strings.Join(paragraphs, "\n\n")})
+		}
+
+		paragraphs = nil
+	}
+
+	for _, token := range tokens {
+		switch concreteToken := token.(type) {
+		case body.StartQuoteToken:
+			endSegment()
+			quoteDepth++
+			segmentDepth = quoteDepth
+		case body.EndQuoteToken:
+			endSegment()
+			quoteDepth--
+			segmentDepth = quoteDepth
+		case body.EndParagraphToken:
+			endParagraph()
+		case body.TextToken:
+			currentParagraph = append(currentParagraph, string(concreteToken))
+		}
+	}
+
+	endSegment()
+
+	return segments
+}
+
+// TextByQuoteDepth returns the text of a message body at each quote depth,
+// joining the segments from `QuoteSegments` with blank lines.
+func TextByQuoteDepth(tokens []body.Token) map[int]string {
+	text := make(map[int]string)
+
+	for _, segment := range QuoteSegments(tokens) {
+		if existing, ok := text[segment.Depth]; ok {
+			text[segment.Depth] = existing + "\n\n" + segment.Text
+		} else {
+			text[segment.Depth] = segment.Text
+		}
+	}
+
+	return text
+}
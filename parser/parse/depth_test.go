@@ -0,0 +1,42 @@
+package parse
+
+import (
+	"reflect"
+	"testing"
+)
+
+const depthTestText = "Sounds good.\n\n" +
+	"On Mon, 2 Jan 2006 15:04, Bob wrote:\n" +
+	"> Let's meet Friday.\n" +
+	">\n" +
+	"> On Sun, 1 Jan 2006 10:00, Alice wrote:\n" +
+	"> > When should we meet?\n" +
+	">\n" +
+	"> Or Saturday.\n\n" +
+	"See you\nthen.\n"
+
+func TestQuoteSegments(t *testing.T) {
+	want := []QuoteSegment{
+		{Depth: 0, Text: "Sounds good."},
+		{Depth: 1, Text: "Let's meet Friday."},
+		{Depth: 2, Text: "When should we meet?"},
+		{Depth: 1, Text: "Or Saturday."},
+		{Depth: 0, Text: "See you\nthen."},
+	}
+
+	if got := QuoteSegments(messageForTest(t, depthTestText).Body.Tokens); !reflect.DeepEqual(got, want) {
+		t.Errorf("QuoteSegments() = %q, want %q", got, want)
+	}
+}
+
+func TestTextByQuoteDepth(t *testing.T) {
+	want := map[int]string{
+		0: "Sounds good.\n\nSee you\nthen.",
+		1: "Let's meet Friday.\n\nOr Saturday.",
+		2: "When should we meet?",
+	}
+
+	if got := TextByQuoteDepth(messageForTest(t, depthTestText).Body.Tokens); !reflect.DeepEqual(got, want) {
+		t.Errorf("TextByQuoteDepth() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,49 @@
+package parse
+
+import (
+	"regexp"
+	"strings"
+)
+
+// A digest message starts after a line of underscores with a "Message: N"
+// field, which may be separated from it by blank lines. Lines of underscores
+// which aren't followed by one are part of the message, like in signatures.
+// The digest's own footer, like its "Yahoo! Groups Links", comes after two
+// lines of underscores in a row.
+var (
+	digestDelimiterRegex      = regexp.MustCompile(`(?m)^[\t ]*_{10,}[\t ]*\n(?:[\t ]*\n)*(Message: +\d+[\t ]*(?:\n|$))`)
+	digestLeadingMessageRegex = regexp.MustCompile(`^\s*(Message: +\d+[\t ]*(?:\n|$))`)
+	digestFooterRegex         = regexp.MustCompile(`(?m)^[\t ]*_{10,}[\t ]*\n[\t ]*_{10,}[\t ]*$`)
+)
+
+const digestDelimiterMessageIndex = 1
+
+// SplitDigest splits the text of a Yahoo Groups digest into the text of each
+// message in it. Digest messages are separated by a line of underscores and
+// start with a "Message: N" field. The digest's own table of contents before
+// the first message and its footer after the last one are discarded.
+func SplitDigest(text string) []string {
+	matches := digestDelimiterRegex.FindAllStringSubmatchIndex(text, -1)
+
+	// The first message may not have a line of underscores before it.
+	if leadingMatch := digestLeadingMessageRegex.FindStringSubmatchIndex(text); leadingMatch != nil {
+		matches = append([][]int{leadingMatch}, matches...)
+	}
+
+	messages := make([]string, 0, len(matches))
+
+	for i, match := range matches {
+		messageStartIndex := match[2*digestDelimiterMessageIndex]
+
+		messageEndIndex := len(text)
+		if i+1 < len(matches) {
+			messageEndIndex = matches[i+1][0]
+		} else if footerMatch := digestFooterRegex.FindStringIndex(text[messageStartIndex:]); footerMatch != nil {
+			messageEndIndex = messageStartIndex + footerMatch[0]
+		}
+
+		messages = append(messages, strings.TrimSpace(text[messageStartIndex:messageEndIndex]))
+	}
+
+	return messages
+}
@@ -0,0 +1,85 @@
+package parse
+
+import (
+	"reflect"
+	"testing"
+)
+
+const digestUnderscores = "________________________________________________________________________"
+
+const threeMessageDigest = `There are 3 messages in this issue.
+
+Topics in this digest:
+
+      1. Meeting on Friday
+           From: Alice
+      2. Re: Meeting on Friday
+           From: Bob
+      3. Re: Meeting on Friday
+           From: Carol
+
+
+` + digestUnderscores + `
+` + digestUnderscores + `
+
+Message: 1
+   Date: Mon, 2 Jan 2006 15:04:05 -0000
+   From: Alice
+Subject: Meeting on Friday
+
+Should we meet on Friday?
+
+` + digestUnderscores + `
+
+Message: 2
+   Date: Mon, 2 Jan 2006 16:04:05 -0000
+   From: Bob
+Subject: Re: Meeting on Friday
+
+Sounds good.
+
+Bob
+____________________
+Sent from my phone
+
+` + digestUnderscores + `
+
+Message: 3
+   Date: Mon, 2 Jan 2006 17:04:05 -0000
+   From: Carol
+Subject: Re: Meeting on Friday
+
+Me too.
+
+
+` + digestUnderscores + `
+` + digestUnderscores + `
+
+------------------------------------------------------------------------
+Yahoo! Groups Links
+
+<*> To visit your group on the web, go to:
+    http://groups.yahoo.com/group/example/
+------------------------------------------------------------------------
+`
+
+func TestSplitDigest(t *testing.T) {
+	want := []string{
+		"Message: 1\n   Date: Mon, 2 Jan 2006 15:04:05 -0000\n   From: Alice\nSubject: Meeting on Friday\n\nShould we meet on Friday?",
+		"Message: 2\n   Date: Mon, 2 Jan 2006 16:04:05 -0000\n   From: Bob\nSubject: Re: Meeting on Friday\n\nSounds good.\n\nBob\n____________________\nSent from my phone",
+		"Message: 3\n   Date: Mon, 2 Jan 2006 17:04:05 -0000\n   From: Carol\nSubject: Re: Meeting on Friday\n\nMe too.",
+	}
+
+	if got := SplitDigest(threeMessageDigest); !reflect.DeepEqual(got, want) {
+		t.Errorf("SplitDigest() = %q, want %q", got, want)
+	}
+}
+
+func TestSplitDigestWithoutTableOfContents(t *testing.T) {
+	text := "Message: 1\nFrom: Alice\n\nHi.\n\n" + digestUnderscores + "\n\nMessage: 2\nFrom: Bob\n\nHello.\n"
+	want := []string{"Message: 1\nFrom: Alice\n\nHi.", "Message: 2\nFrom: Bob\n\nHello."}
+
+	if got := SplitDigest(text); !reflect.DeepEqual(got, want) {
+		t.Errorf("SplitDigest(%q) = %q, want %q", text, got, want)
+	}
+}
@@ -3,11 +3,13 @@ package parse
 import (
 	"errors"
 	"fmt"
+	"github.com/acearchive/yg-render/block"
 	"github.com/acearchive/yg-render/body"
 	"github.com/acearchive/yg-render/logger"
 	"io"
 	"net/mail"
 	"regexp"
+	"time"
 )
 
 type MimeHeader string
@@ -57,6 +59,17 @@ func userFromEmail(email *mail.Message) string {
 	return rawAddress
 }
 
+// emailAddressFromEmail returns the address in the `From` header, which may
+// be redacted like "alice@...".
+func emailAddressFromEmail(email *mail.Message) string {
+	addresses := block.ParseAddressList(email.Header.Get(MimeHeaderFrom))
+	if len(addresses) == 0 {
+		return ""
+	}
+
+	return addresses[0].Email
+}
+
 func flairFromEmail(email *mail.Message) string {
 	if profData := email.Header.Get(MimeHeaderProfData); profData != "" {
 		return profData
@@ -76,15 +89,32 @@ func flairFromEmail(email *mail.Message) string {
 	return ""
 }
 
-func bodyFromEmail(email *mail.Message) (MessageBody, error) {
-	rawTextBody, err := DecodeMessageBody(email)
+func bodyFromEmail(email *mail.Message, date time.Time, config InputConfig) (MessageBody, error) {
+	rawTextBody, err := DecodeMessageBody(email, config)
 	if err != nil {
 		return MessageBody{}, err
 	}
 
 	var messageBody MessageBody
 
-	tokenizer := body.NewDefaultTokenizer()
+	tokenizer := body.NewTokenizer(func() []block.Block {
+		return block.AllBlocksWithOptions(block.ParseOptions{
+			Reference:              date,
+			AllowEmptyHeaderFields: config.AllowEmptyHeaderFields,
+			DayFirstDates:          config.DayFirstDates,
+			DottedMonthFirstDates:  config.DottedMonthFirstDates,
+			RawHtmlSentinel:        config.RawHtmlSentinel,
+			KeepParsedTimes:        config.KeepParsedTimes,
+			AsciiTables:            config.AsciiTables,
+			ScissorMarkers:         config.ScissorMarkers,
+			TruncationMarkers:      config.TruncationMarkers,
+			LenientAttributions:    config.LenientAttributions,
+			CanonicalizeEmail:      config.CanonicalizeEmail,
+			FooterWindow:           config.FooterWindow,
+			Hooks:                  config.Hooks,
+		})
+	})
+	tokenizer.ExtraQuoteMarkers = config.ExtraQuoteMarkers
 
 	messageBody.Tokens, err = tokenizer.Tokenize(rawTextBody)
 	if err != nil {
@@ -96,7 +126,7 @@ func bodyFromEmail(email *mail.Message) (MessageBody, error) {
 	return messageBody, nil
 }
 
-func Email(contents io.Reader) (Message, error) {
+func Email(contents io.Reader, config InputConfig) (Message, error) {
 	rawMessage, err := mail.ReadMessage(contents)
 	if err != nil {
 		return Message{}, fmt.Errorf("%w: %v", ErrMalformedEmail, err)
@@ -113,6 +143,10 @@ func Email(contents io.Reader) (Message, error) {
 	}
 
 	message.User = userFromEmail(rawMessage)
+	message.Email = emailAddressFromEmail(rawMessage)
+	if config.CanonicalizeEmail != nil && message.Email != "" {
+		message.Email = config.CanonicalizeEmail(message.Email)
+	}
 	message.Flair = flairFromEmail(rawMessage)
 
 	if message.Flair == message.User {
@@ -127,7 +161,7 @@ func Email(contents io.Reader) (Message, error) {
 		message.Title = &messageTitle
 	}
 
-	message.Body, err = bodyFromEmail(rawMessage)
+	message.Body, err = bodyFromEmail(rawMessage, message.Date, config)
 	if err != nil {
 		return Message{}, fmt.Errorf("%w: %v", ErrMalformedEmail, err)
 	}
@@ -10,7 +10,7 @@ import (
 
 const EmailExtension = ".eml"
 
-func Directory(path string) (MessageThread, error) {
+func Directory(path string, config InputConfig) (MessageThread, error) {
 	entries, err := os.ReadDir(path)
 	if err != nil {
 		return nil, err
@@ -36,7 +36,7 @@ func Directory(path string) (MessageThread, error) {
 			return nil, err
 		}
 
-		message, parseErr := Email(file)
+		message, parseErr := Email(file, config)
 
 		if err := file.Close(); err != nil {
 			return nil, err
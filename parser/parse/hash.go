@@ -0,0 +1,162 @@
+package parse
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/acearchive/yg-render/block"
+	"github.com/acearchive/yg-render/body"
+	"strings"
+	"unicode/utf8"
+)
+
+// newContentParagraphs returns the text of each paragraph the author of a
+// message wrote themselves. Quoted text and attributions are skipped, and
+// everything from the first divider, disclaimer, or forwarded message header
+// onward is dropped, since that's where signatures, mailing list footers, and
+// top-posted history go. Paragraphs with only whitespace are skipped.
+func newContentParagraphs(tokens []body.Token) []string {
+	var (
+		paragraphs       []string
+		currentParagraph []string
+	)
+
+	quoteDepth := 0
+
+	endParagraph := func() {
+		if text := strings.Join(currentParagraph, "\n"); strings.TrimSpace(text) != "" {
+			paragraphs = append(paragraphs, text)
+		}
+
+		currentParagraph = nil
+	}
+
+tokenLoop:
+	for _, token := range tokens {
+		switch concreteToken := token.(type) {
+		case body.StartQuoteToken:
+			quoteDepth++
+		case body.EndQuoteToken:
+			quoteDepth--
+		case body.EndParagraphToken:
+			endParagraph()
+		case body.BlockToken:
+			if quoteDepth > 0 {
+				continue
+			}
+
+			switch concreteToken.Block.(type) {
+			case *block.DividerBlock, *block.DisclaimerBlock, *block.MessageHeaderBlock:
+				break tokenLoop
+			}
+		case body.TextToken:
+			if quoteDepth > 0 {
+				continue
+			}
+
+			currentParagraph = append(currentParagraph, string(concreteToken))
+		}
+	}
+
+	endParagraph()
+
+	return paragraphs
+}
+
+// NormalizeBodyText returns the text of a message body that's used to detect
+// duplicates with `BodyHash`. This is the same text as `Message.NewContent`,
+// but whitespace within each paragraph is collapsed to single spaces, and
+// paragraphs are separated by newlines.
+func NormalizeBodyText(tokens []body.Token) string {
+	paragraphs := newContentParagraphs(tokens)
+
+	for i, paragraph := range paragraphs {
+		paragraphs[i] = strings.Join(strings.Fields(paragraph), " ")
+	}
+
+	return strings.Join(paragraphs, "\n")
+}
+
+// NewContent returns the text the author of the message wrote themselves,
+// without quoted replies, signatures, footers, or top-posted history after an
+// "Original Message" header. Lines are trimmed, and paragraphs are separated
+// by blank lines.
+func (m Message) NewContent() string {
+	paragraphs := newContentParagraphs(m.Body.Tokens)
+
+	for i, paragraph := range paragraphs {
+		lines := strings.Split(strings.TrimSpace(paragraph), "\n")
+
+		for j, line := range lines {
+			lines[j] = strings.TrimSpace(line)
+		}
+
+		paragraphs[i] = strings.Join(lines, "\n")
+	}
+
+	return strings.Join(paragraphs, "\n\n")
+}
+
+// BodyHash returns a hex-encoded SHA-256 hash of the normalized body text, so
+// that cross-posts with the same content but different headers, quotes, or
+// footers hash the same. See `NormalizeBodyText` for how the text is
+// normalized.
+func (m Message) BodyHash() string {
+	hash := sha256.Sum256([]byte(NormalizeBodyText(m.Body.Tokens)))
+
+	return hex.EncodeToString(hash[:])
+}
+
+// ContentLength returns the number of runes in the normalized body text, which
+// is the size of the new content in the message, not counting quotes,
+// signatures, or footers. See `NormalizeBodyText` for how the text is
+// normalized.
+func (m Message) ContentLength() int {
+	return utf8.RuneCountInString(NormalizeBodyText(m.Body.Tokens))
+}
+
+// newContentBlock returns whether a block outside of a quote is something the
+// author of the message posted themselves, like a table or a link to a file.
+func newContentBlock(b block.Block) bool {
+	switch b.(type) {
+	case *block.AsciiTableBlock, *block.GroupResourceBlock, *block.ImageReferenceBlock, *block.RawHtmlBlock:
+		return true
+	default:
+		return false
+	}
+}
+
+// HasNewContent returns whether the author of the message wrote anything
+// themselves, as opposed to only quoting earlier messages above a signature or
+// footer. Like `NewContent`, this ignores everything from the first divider,
+// disclaimer, or forwarded message header onward.
+func (m Message) HasNewContent() bool {
+	if len(newContentParagraphs(m.Body.Tokens)) > 0 {
+		return true
+	}
+
+	quoteDepth := 0
+
+	for _, token := range m.Body.Tokens {
+		switch concreteToken := token.(type) {
+		case body.StartQuoteToken:
+			quoteDepth++
+		case body.EndQuoteToken:
+			quoteDepth--
+		case body.BlockToken:
+			if quoteDepth > 0 {
+				continue
+			}
+
+			switch concreteToken.Block.(type) {
+			case *block.DividerBlock, *block.DisclaimerBlock, *block.MessageHeaderBlock:
+				return false
+			}
+
+			if newContentBlock(concreteToken.Block) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
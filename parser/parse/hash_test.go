@@ -0,0 +1,141 @@
+package parse
+
+import "testing"
+
+func TestBodyHash(t *testing.T) {
+	const original = "Does anyone have the notes from Friday?\n\nThanks,\nAlice\n"
+
+	tests := []struct {
+		name     string
+		text     string
+		wantSame bool
+	}{
+		{
+			name:     "different footer",
+			text:     original + "\n__________\nPosted to the example group.\n",
+			wantSame: true,
+		},
+		{
+			name:     "different disclaimer",
+			text:     original + "\nThis email and any attachments are confidential.\n",
+			wantSame: true,
+		},
+		{
+			name:     "different quote",
+			text:     "> Are there notes?\n\n" + original,
+			wantSame: true,
+		},
+		{
+			name:     "different line endings and wrapping",
+			text:     "Does anyone have the notes\r\nfrom Friday?\r\n\r\nThanks,\r\nAlice\r\n",
+			wantSame: true,
+		},
+		{
+			name:     "different content",
+			text:     "Does anyone have the notes from Monday?\n\nThanks,\nAlice\n",
+			wantSame: false,
+		},
+	}
+
+	originalHash := messageForTest(t, original).BodyHash()
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if isSame := messageForTest(t, test.text).BodyHash() == originalHash; isSame != test.wantSame {
+				t.Errorf("BodyHash() of %q is the same as %q = %v, want %v", test.text, original, isSame, test.wantSame)
+			}
+		})
+	}
+}
+
+func TestContentLength(t *testing.T) {
+	text := "Sounds   good.\n\n" +
+		"On Mon, 2 Jan 2006 15:04, Alice wrote:\n" +
+		"> Should we meet?\n\n" +
+		"See you\nthen.\n\n" +
+		"-----\n\n" +
+		"This email is confidential.\n"
+
+	if got, want := messageForTest(t, text).ContentLength(), len("Sounds good.\nSee you then."); got != want {
+		t.Errorf("ContentLength() of %q = %d, want %d", text, got, want)
+	}
+}
+
+func TestNewContent(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			name: "top-posted",
+			text: "Friday works for me.\n\n" +
+				"-----Original Message-----\n" +
+				"From: Alice\n" +
+				"Subject: Meeting\n\n" +
+				"Should we meet on Friday?\n",
+			want: "Friday works for me.",
+		},
+		{
+			name: "bottom-posted",
+			text: "On Mon, 2 Jan 2006 15:04, Alice wrote:\n" +
+				"> Should we meet on Friday?\n\n" +
+				"  Friday works\n  for me.  \n\n" +
+				"> What time?\n\n" +
+				"Noon.\n\n" +
+				"--\n" +
+				"Bob\n",
+			want: "Friday works\nfor me.\n\nNoon.",
+		},
+		{
+			name: "only quoted",
+			text: "> Should we meet on Friday?\n",
+			want: "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := messageForTest(t, test.text).NewContent(); got != test.want {
+				t.Errorf("NewContent() of %q = %q, want %q", test.text, got, test.want)
+			}
+		})
+	}
+}
+
+func TestHasNewContent(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want bool
+	}{
+		{
+			name: "normal reply",
+			text: "On Mon, 2 Jan 2006 15:04, Alice wrote:\n> Should we meet on Friday?\n\nSure.\n",
+			want: true,
+		},
+		{
+			name: "only quoted",
+			text: "On Mon, 2 Jan 2006 15:04, Alice wrote:\n> Should we meet on Friday?\n",
+			want: false,
+		},
+		{
+			name: "quote, signature, and footer",
+			text: "> Should we meet on Friday?\n\n--\nBob\n\n__________\nPosted to the example group.\n",
+			want: false,
+		},
+		{
+			name: "only a link to a file",
+			text: "> Where are the notes?\n\nhttp://groups.yahoo.com/group/example/files/notes.txt\n",
+			want: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := messageForTest(t, test.text).HasNewContent(); got != test.want {
+				t.Errorf("HasNewContent() of %q = %v, want %v", test.text, got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,243 @@
+package parse
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	htmlBodyStartRegex = regexp.MustCompile(`^\s*(?i:<!doctype\s+html|<html[\s>])`)
+
+	// This is far from a complete HTML parser, but the HTML in archived
+	// messages is simple enough that matching tags is good enough to recover
+	// the text.
+	htmlTagRegex        = regexp.MustCompile(`(?s)<!--.*?-->|<![^>]*>|<(/?)([a-zA-Z][a-zA-Z0-9]*)((?:[^>"']|"[^"]*"|'[^']*')*)>`)
+	htmlHrefRegex       = regexp.MustCompile(`(?i)\bhref\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s>]+))`)
+	htmlWhitespaceRegex = regexp.MustCompile(`[ \t\r\n\f]+`)
+
+	// Elements whose contents aren't text are removed. Scripts and styles
+	// go first, since they can be in the head and contain other tags.
+	htmlIgnoredRegexes = []*regexp.Regexp{
+		regexp.MustCompile(`(?is)<script[\s>].*?</script\s*>`),
+		regexp.MustCompile(`(?is)<style[\s>].*?</style\s*>`),
+		regexp.MustCompile(`(?is)<head[\s>].*?</head\s*>`),
+	}
+)
+
+const (
+	htmlTagRegexCloseIndex = 1
+	htmlTagRegexNameIndex  = 2
+	htmlTagRegexAttrsIndex = 3
+)
+
+// IsHtmlBody returns whether a message body looks like an HTML document
+// rather than plain text.
+func IsHtmlBody(text string) bool {
+	return htmlBodyStartRegex.MatchString(text)
+}
+
+// htmlTextWriter writes the text of an HTML document as plain text, quoting
+// the contents of each `<blockquote>` with ">" so they're parsed like quotes
+// in a plain text body.
+type htmlTextWriter struct {
+	output          strings.Builder
+	quoteDepth      int
+	preDepth        int
+	pendingNewlines int
+	atLineStart     bool
+
+	// trailingNewlines is the number of newlines written since the last
+	// text, so that nested tags like "<blockquote><p>" only break once.
+	trailingNewlines int
+}
+
+// breakLine makes sure the last text is followed by `count` newlines, unless
+// nothing has been written yet.
+func (w *htmlTextWriter) breakLine(count int) {
+	if w.output.Len() > 0 && count-w.trailingNewlines > w.pendingNewlines {
+		w.pendingNewlines = count - w.trailingNewlines
+	}
+}
+
+// addNewline adds a newline regardless of the ones before it, like for each
+// of a run of "<br>" tags.
+func (w *htmlTextWriter) addNewline() {
+	if w.output.Len() > 0 {
+		w.pendingNewlines++
+	}
+}
+
+func (w *htmlTextWriter) writeNewline() {
+	w.output.WriteString("\n")
+	w.atLineStart = true
+	w.trailingNewlines++
+}
+
+func (w *htmlTextWriter) writeLineStart() {
+	if w.atLineStart {
+		w.output.WriteString(strings.Repeat("> ", w.quoteDepth))
+		w.atLineStart = false
+	}
+}
+
+func (w *htmlTextWriter) flushNewlines() {
+	for ; w.pendingNewlines > 0; w.pendingNewlines-- {
+		if !w.atLineStart {
+			w.writeNewline()
+			continue
+		}
+
+		// Blank lines between paragraphs in a quote are quoted too.
+		w.output.WriteString(strings.TrimRight(strings.Repeat("> ", w.quoteDepth), " "))
+		w.writeNewline()
+	}
+}
+
+func (w *htmlTextWriter) writeText(text string) {
+	if w.preDepth == 0 {
+		text = htmlWhitespaceRegex.ReplaceAllString(text, " ")
+	}
+
+	text = strings.ReplaceAll(html.UnescapeString(text), "\u00a0", " ")
+
+	for lineIndex, line := range strings.Split(text, "\n") {
+		if lineIndex > 0 {
+			w.writeNewline()
+		}
+
+		if w.preDepth == 0 && (w.atLineStart || w.pendingNewlines > 0) {
+			line = strings.TrimLeft(line, " ")
+		}
+
+		if line == "" {
+			continue
+		}
+
+		w.flushNewlines()
+		w.writeLineStart()
+		w.output.WriteString(line)
+		w.trailingNewlines = 0
+	}
+}
+
+func (w *htmlTextWriter) String() string {
+	lines := strings.Split(w.output.String(), "\n")
+
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n")) + "\n"
+}
+
+func hrefFromAttrs(attrs string) string {
+	match := htmlHrefRegex.FindStringSubmatch(attrs)
+	if match == nil {
+		return ""
+	}
+
+	for _, value := range match[1:] {
+		if value != "" {
+			return html.UnescapeString(value)
+		}
+	}
+
+	return ""
+}
+
+// HtmlToText converts an HTML message body to plain text which can be parsed
+// like any other body. Tags are removed, paragraphs and line breaks become
+// newlines, entities are decoded, and quotes are prefixed with ">".
+func HtmlToText(document string) string {
+	for _, ignoredRegex := range htmlIgnoredRegexes {
+		document = ignoredRegex.ReplaceAllString(document, "")
+	}
+
+	var (
+		writer           = htmlTextWriter{atLineStart: true}
+		linkHref         string
+		linkTextStartLen int
+	)
+
+	remaining := document
+
+	for {
+		match := htmlTagRegex.FindStringSubmatchIndex(remaining)
+		if match == nil {
+			writer.writeText(remaining)
+			break
+		}
+
+		writer.writeText(remaining[:match[0]])
+
+		tagNameStartIndex := match[2*htmlTagRegexNameIndex]
+		if tagNameStartIndex < 0 {
+			// This is a comment.
+			remaining = remaining[match[1]:]
+			continue
+		}
+
+		isClose := match[2*htmlTagRegexCloseIndex+1] > match[2*htmlTagRegexCloseIndex]
+		tagName := strings.ToLower(remaining[tagNameStartIndex:match[2*htmlTagRegexNameIndex+1]])
+		attrs := remaining[match[2*htmlTagRegexAttrsIndex]:match[2*htmlTagRegexAttrsIndex+1]]
+
+		switch tagName {
+		case "br":
+			writer.addNewline()
+		case "p", "h1", "h2", "h3", "h4", "h5", "h6", "ul", "ol", "table", "hr":
+			writer.breakLine(2)
+		case "div", "tr", "dt", "dd":
+			writer.breakLine(1)
+		case "li":
+			writer.breakLine(1)
+
+			if !isClose {
+				writer.writeText("- ")
+			}
+		case "td", "th":
+			if !isClose {
+				writer.writeText(" ")
+			}
+		case "pre":
+			writer.breakLine(2)
+
+			if isClose && writer.preDepth > 0 {
+				writer.preDepth--
+			} else if !isClose {
+				writer.preDepth++
+			}
+		case "blockquote":
+			// The blank line around a quote is written outside of it, so
+			// that the quote is closed before the text after it.
+			if isClose {
+				if writer.quoteDepth > 0 {
+					writer.quoteDepth--
+				}
+
+				writer.breakLine(2)
+			} else {
+				writer.breakLine(2)
+				writer.flushNewlines()
+				writer.quoteDepth++
+			}
+		case "a":
+			if !isClose {
+				linkHref, linkTextStartLen = hrefFromAttrs(attrs), writer.output.Len()
+			} else if linkHref != "" {
+				// Keep the address of links whose text isn't the address
+				// itself, since it's lost along with the tag otherwise.
+				linkText := writer.output.String()[linkTextStartLen:]
+				if !strings.HasPrefix(strings.ToLower(linkHref), "mailto:") && strings.TrimSpace(linkText) != linkHref {
+					writer.writeText(" <" + linkHref + ">")
+				}
+
+				linkHref = ""
+			}
+		}
+
+		remaining = remaining[match[1]:]
+	}
+
+	return writer.String()
+}
@@ -0,0 +1,88 @@
+package parse
+
+import "testing"
+
+func TestHtmlToText(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		want     string
+	}{
+		{
+			name:     "paragraphs and line breaks",
+			document: "<html><head><title>Lunch</title></head><body><p>Hi all,</p><p>Lunch is at<br>noon &amp; the caf&eacute;.</p></body></html>",
+			want:     "Hi all,\n\nLunch is at\nnoon & the café.\n",
+		},
+		{
+			name:     "quote",
+			document: "<div>Sounds good.</div><blockquote><p>Lunch?</p><p>Or dinner?</p></blockquote><div>Bob</div>",
+			want:     "Sounds good.\n\n> Lunch?\n>\n> Or dinner?\n\nBob\n",
+		},
+		{
+			name:     "links and lists",
+			document: `<p>See <a href="http://example.com/notes">the notes</a>:</p><ul><li>One</li><li>Two</li></ul>`,
+			want:     "See the notes <http://example.com/notes>:\n\n- One\n- Two\n",
+		},
+		{
+			name:     "scripts, styles, and comments",
+			document: "<style>p { color: red; }</style><script>alert('<p>hi</p>')</script><p>Hello<!-- tracking --></p>",
+			want:     "Hello\n",
+		},
+		{
+			name:     "preformatted",
+			document: "<pre>a  b\n  c</pre>",
+			want:     "a  b\n  c\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := HtmlToText(test.document); got != test.want {
+				t.Errorf("HtmlToText(%q) = %q, want %q", test.document, got, test.want)
+			}
+		})
+	}
+}
+
+func TestIsHtmlBody(t *testing.T) {
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"<html><body>Hi</body></html>", true},
+		{"\n  <!DOCTYPE html>\n<html>", true},
+		{"<HTML lang=\"en\">", true},
+		{"<htmlish> isn't a tag", false},
+		{"I wrote <html> in my last post.", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.text, func(t *testing.T) {
+			if got := IsHtmlBody(test.text); got != test.want {
+				t.Errorf("IsHtmlBody(%q) = %v, want %v", test.text, got, test.want)
+			}
+		})
+	}
+}
+
+func TestDecodeHtmlBody(t *testing.T) {
+	const htmlEmail = "Content-Type: text/html; charset=utf-8\r\n" +
+		"\r\n" +
+		"<p>Hi all,</p><p>Lunch is at noon.</p>\r\n"
+
+	if got, want := decodeBodyForTest(t, htmlEmail, InputConfig{}), "Hi all,\n\nLunch is at noon.\n"; got != want {
+		t.Errorf("DecodeMessageBody() = %q, want %q", got, want)
+	}
+
+	const sniffedEmail = "Content-Type: text/plain; charset=utf-8\r\n" +
+		"\r\n" +
+		"<html><body><p>Hi all,</p></body></html>\r\n"
+
+	if got, want := decodeBodyForTest(t, sniffedEmail, InputConfig{DetectHtmlBodies: true}), "Hi all,\n"; got != want {
+		t.Errorf("DecodeMessageBody() with DetectHtmlBodies = %q, want %q", got, want)
+	}
+
+	if got, want := decodeBodyForTest(t, sniffedEmail, InputConfig{}), "<html><body><p>Hi all,</p></body></html>\r\n"; got != want {
+		t.Errorf("DecodeMessageBody() without DetectHtmlBodies = %q, want %q", got, want)
+	}
+}
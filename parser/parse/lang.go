@@ -0,0 +1,42 @@
+package parse
+
+import (
+	"github.com/acearchive/yg-render/block"
+	"github.com/acearchive/yg-render/body"
+)
+
+// LangUndetermined is the BCP 47 tag for a message whose language couldn't be
+// detected.
+const LangUndetermined = "und"
+
+// Lang returns a best-effort BCP 47 tag for the language of the message, based
+// on the localized formats of the attributions in its body, like "fr" for "Le
+// 2 janvier 2006 à 15:04, Alice a écrit :". When attributions disagree, the
+// most common language wins, with ties going to the first one. It returns
+// `LangUndetermined` when there are no attributions.
+func (m Message) Lang() string {
+	var (
+		counts = make(map[string]int)
+		lang   = LangUndetermined
+	)
+
+	for _, token := range m.Body.Tokens {
+		blockToken, isBlock := token.(body.BlockToken)
+		if !isBlock {
+			continue
+		}
+
+		attribution, isAttribution := blockToken.Block.(*block.AttributionBlock)
+		if !isAttribution || attribution.Lang == "" {
+			continue
+		}
+
+		counts[attribution.Lang]++
+
+		if counts[attribution.Lang] > counts[lang] {
+			lang = attribution.Lang
+		}
+	}
+
+	return lang
+}
@@ -0,0 +1,45 @@
+package parse
+
+import "testing"
+
+func TestLang(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			name: "French attribution",
+			text: "D'accord.\n\nLe 2 janvier 2006 à 15:04, Alice a écrit :\n> On se voit vendredi ?\n",
+			want: "fr",
+		},
+		{
+			name: "German attribution",
+			text: "Gut.\n\nAlice schrieb am 2. Januar 2006 um 15:04:\n> Freitag?\n",
+			want: "de",
+		},
+		{
+			name: "English attribution",
+			text: "Sounds good.\n\nOn Mon, 2 Jan 2006 15:04, Alice wrote:\n> Friday?\n",
+			want: "en",
+		},
+		{
+			name: "most common language",
+			text: "Le 3 janvier 2006 à 09:30, Bob a écrit :\n> D'accord.\n>\n> Le 2 janvier 2006 à 15:04, Alice a écrit :\n> > Vendredi ?\n>\n> > On Sun, 1 Jan 2006 10:00, Carol wrote:\n> > > Friday?\n",
+			want: "fr",
+		},
+		{
+			name: "without an attribution",
+			text: "Sounds good.\n",
+			want: LangUndetermined,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := messageForTest(t, test.text).Lang(); got != test.want {
+				t.Errorf("Lang() of %q = %q, want %q", test.text, got, test.want)
+			}
+		})
+	}
+}
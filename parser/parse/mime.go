@@ -17,6 +17,7 @@ const (
 	MimeHeaderContentTransferEncoding = "Content-Transfer-Encoding"
 	contentTypePrefixMultipart        = "multipart/"
 	contentTypePlainText              = "text/plain"
+	contentTypeHtml                   = "text/html"
 	contentTypeParamBoundary          = "boundary"
 	contentTypeParamCharset           = "charset"
 	quotedPrintable                   = "quoted-printable"
@@ -37,10 +38,42 @@ func decodeCharset(body io.Reader, contentTypeParams map[string]string) io.Reade
 	return DefaultCharset.NewDecoder().Reader(body)
 }
 
-func DecodeMessageBody(email *mail.Message) (io.Reader, error) {
+func decodeTransferEncoding(body io.Reader, encoding string, config InputConfig) io.Reader {
+	if encoding == quotedPrintable || config.DecodeQuotedPrintable {
+		return quotedprintable.NewReader(body)
+	}
+
+	return body
+}
+
+// htmlBodyToText converts an HTML body to plain text. `isHtml` is whether the
+// content type says it's HTML; otherwise, when `detect` is set, this checks
+// whether it looks like an HTML document.
+func htmlBodyToText(body io.Reader, isHtml, detect bool) (io.Reader, error) {
+	bodyBytes, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+
+	text := string(bodyBytes)
+
+	if isHtml || (detect && IsHtmlBody(text)) {
+		text = HtmlToText(text)
+	}
+
+	return strings.NewReader(text), nil
+}
+
+// DecodeMessageBody returns the plain text body of the email. When there is no
+// plain text part but there is an HTML one, or the body is HTML, it's
+// converted to plain text. A plain text body which looks like an HTML
+// document is only converted when `config.DetectHtmlBodies` is set.
+func DecodeMessageBody(email *mail.Message, config InputConfig) (io.Reader, error) {
 	mediaType, contentTypeParams, err := mime.ParseMediaType(email.Header.Get(MimeHeaderContentType))
 
 	if err == nil && strings.HasPrefix(mediaType, contentTypePrefixMultipart) {
+		var htmlBody io.Reader
+
 		multipartReader := multipart.NewReader(email.Body, contentTypeParams[contentTypeParamBoundary])
 		for {
 			part, err := multipartReader.NextPart()
@@ -56,14 +89,30 @@ func DecodeMessageBody(email *mail.Message) (io.Reader, error) {
 			}
 
 			if partMediaType == contentTypePlainText {
-				return decodeCharset(part, partContentTypeParams), nil
+				// The multipart reader already decodes quoted-printable parts
+				// and strips the header, so this only applies when forced.
+				partBody := decodeTransferEncoding(part, part.Header.Get(MimeHeaderContentTransferEncoding), config)
+				return htmlBodyToText(decodeCharset(partBody, partContentTypeParams), false, config.DetectHtmlBodies)
+			}
+
+			// The parts are read in order, so the HTML part has to be kept in
+			// case there's no plain text part after it.
+			if partMediaType == contentTypeHtml && htmlBody == nil {
+				partBody := decodeTransferEncoding(part, part.Header.Get(MimeHeaderContentTransferEncoding), config)
+
+				htmlBody, err = htmlBodyToText(decodeCharset(partBody, partContentTypeParams), true, false)
+				if err != nil {
+					return nil, err
+				}
 			}
 		}
-	}
 
-	if email.Header.Get(MimeHeaderContentTransferEncoding) == quotedPrintable {
-		return decodeCharset(quotedprintable.NewReader(email.Body), contentTypeParams), nil
+		if htmlBody != nil {
+			return htmlBody, nil
+		}
 	}
 
-	return decodeCharset(email.Body, contentTypeParams), nil
+	emailBody := decodeTransferEncoding(email.Body, email.Header.Get(MimeHeaderContentTransferEncoding), config)
+
+	return htmlBodyToText(decodeCharset(emailBody, contentTypeParams), err == nil && mediaType == contentTypeHtml, config.DetectHtmlBodies)
 }
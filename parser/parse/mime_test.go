@@ -0,0 +1,61 @@
+package parse
+
+import (
+	"io/ioutil"
+	"net/mail"
+	"strings"
+	"testing"
+)
+
+func decodeBodyForTest(t *testing.T, rawEmail string, config InputConfig) string {
+	t.Helper()
+
+	email, err := mail.ReadMessage(strings.NewReader(rawEmail))
+	if err != nil {
+		t.Fatalf("ReadMessage() error = %v", err)
+	}
+
+	decoded, err := DecodeMessageBody(email, config)
+	if err != nil {
+		t.Fatalf("DecodeMessageBody() error = %v", err)
+	}
+
+	decodedBytes, err := ioutil.ReadAll(decoded)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+
+	return string(decodedBytes)
+}
+
+func TestDecodeQuotedPrintable(t *testing.T) {
+	const rawEmail = "Content-Type: text/plain; charset=utf-8\r\n" +
+		"\r\n" +
+		"This line was wrapped in the mid=\r\n" +
+		"dle of a word, and 1 + 1 =3D 2.\r\n"
+
+	tests := []struct {
+		name   string
+		config InputConfig
+		want   string
+	}{
+		{
+			name:   "decoded when enabled",
+			config: InputConfig{DecodeQuotedPrintable: true},
+			want:   "This line was wrapped in the middle of a word, and 1 + 1 = 2.\r\n",
+		},
+		{
+			name:   "left alone by default",
+			config: InputConfig{},
+			want:   "This line was wrapped in the mid=\r\ndle of a word, and 1 + 1 =3D 2.\r\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := decodeBodyForTest(t, rawEmail, test.config); got != test.want {
+				t.Errorf("DecodeMessageBody() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
@@ -1,11 +1,40 @@
 package parse
 
 import (
+	"github.com/acearchive/yg-render/block"
 	"github.com/acearchive/yg-render/body"
 	"sort"
 	"time"
 )
 
+type InputConfig struct {
+	DecodeQuotedPrintable  bool
+	AllowEmptyHeaderFields bool
+	DayFirstDates          bool
+	DottedMonthFirstDates  bool
+	RawHtmlSentinel        string
+	KeepParsedTimes        bool
+	AsciiTables            bool
+	ScissorMarkers         []string
+	TruncationMarkers      []string
+	LenientAttributions    bool
+	ExtraQuoteMarkers      string
+	FooterWindow           int
+
+	// DetectHtmlBodies converts plain text bodies which look like an HTML
+	// document, like ones starting with "<html>", to plain text. Bodies
+	// labeled as HTML are always converted.
+	DetectHtmlBodies bool
+
+	// CanonicalizeEmail, when set, is applied to the sender's address and to
+	// addresses in quoted attributions and headers.
+	CanonicalizeEmail func(address string) string
+
+	// Hooks are called on parse events in message bodies, like for collecting
+	// metrics.
+	Hooks *block.ParseHooks
+}
+
 type MessageID string
 
 type MessageBody struct {
@@ -17,6 +46,7 @@ type Message struct {
 	ID     MessageID
 	Parent *MessageID
 	User   string
+	Email  string
 	Flair  string
 	Date   time.Time
 	Title  *string
@@ -0,0 +1,29 @@
+package parse
+
+import (
+	"regexp"
+	"strings"
+)
+
+// This matches a short greeting at the start of a message, like "Hi all," or
+// "Dear Alice:". The greeting and up to two words after it must be followed
+// by a comma, exclamation mark, colon, or the end of the line, so that a
+// sentence like "Hey there is a bug in line 3." isn't cut.
+var greetingRegex = regexp.MustCompile(`(?i)^(?:hi|hello|hey|dear|greetings|good (?:morning|afternoon|evening))\b(?:[\t ]+[^\s,.!:]+){0,2}[\t ]*(?:[,!:]\s*|\n\s*|$)`)
+
+// Preview returns a short plain-text snippet of the new content in the
+// message, suitable for a list of messages. Unlike `Summary`, it isn't limited
+// to the first paragraph, and it skips signatures and footers as well as
+// quoted text and forwarded messages. If `skipGreeting` is set, a leading
+// greeting like "Hi all," is left out. The snippet is truncated at a word
+// boundary to at most `maxLen` characters, not including the trailing
+// ellipsis. It's empty when `maxLen` isn't positive.
+func (m Message) Preview(maxLen int, skipGreeting bool) string {
+	text := NormalizeBodyText(m.Body.Tokens)
+
+	if skipGreeting {
+		text = greetingRegex.ReplaceAllString(text, "")
+	}
+
+	return truncateAtWord(strings.Join(strings.Fields(text), " "), maxLen)
+}
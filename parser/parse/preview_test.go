@@ -0,0 +1,73 @@
+package parse
+
+import "testing"
+
+func TestPreview(t *testing.T) {
+	tests := []struct {
+		name         string
+		text         string
+		maxLen       int
+		skipGreeting bool
+		want         string
+	}{
+		{
+			name:   "starting with a quote",
+			text:   "> Should we meet on Friday?\n\nSounds good to me.\n",
+			maxLen: 100,
+			want:   "Sounds good to me.",
+		},
+		{
+			name:   "starting with an attribution",
+			text:   "On Mon, 2 Jan 2006 15:04, Alice wrote:\n> Should we meet on Friday?\n\nSounds good to me.\n",
+			maxLen: 100,
+			want:   "Sounds good to me.",
+		},
+		{
+			name:         "greeting skipped",
+			text:         "Hi all,\n\nThe meeting is on Friday.\n",
+			maxLen:       100,
+			skipGreeting: true,
+			want:         "The meeting is on Friday.",
+		},
+		{
+			name:         "greeting on the same line skipped",
+			text:         "Dear Alice: the meeting is on Friday.\n",
+			maxLen:       100,
+			skipGreeting: true,
+			want:         "the meeting is on Friday.",
+		},
+		{
+			name:   "greeting kept",
+			text:   "Hi all,\n\nThe meeting is on Friday.\n",
+			maxLen: 100,
+			want:   "Hi all, The meeting is on Friday.",
+		},
+		{
+			name:         "sentence starting like a greeting",
+			text:         "Hey there is a bug in line 3.\n",
+			maxLen:       100,
+			skipGreeting: true,
+			want:         "Hey there is a bug in line 3.",
+		},
+		{
+			name:   "past the first paragraph and before a signature",
+			text:   "Sounds good.\n\nSee you then.\n\n--\nBob\n",
+			maxLen: 100,
+			want:   "Sounds good. See you then.",
+		},
+		{
+			name:   "truncated",
+			text:   "The meeting is on Friday.\n",
+			maxLen: 10,
+			want:   "The…",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := messageForTest(t, test.text).Preview(test.maxLen, test.skipGreeting); got != test.want {
+				t.Errorf("Preview(%d, %v) of %q = %q, want %q", test.maxLen, test.skipGreeting, test.text, got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package parse
+
+import (
+	"strings"
+	"time"
+)
+
+// DefaultWordsPerMinute is a typical speed for reading prose on a screen, for
+// use with `Message.ReadingTime`.
+const DefaultWordsPerMinute = 200
+
+// WordCount returns the number of words in the new content of the message,
+// not counting quotes, signatures, or footers. See `NormalizeBodyText` for
+// what counts as new content.
+func (m Message) WordCount() int {
+	return len(strings.Fields(NormalizeBodyText(m.Body.Tokens)))
+}
+
+// ReadingTime estimates how long it takes to read the new content of the
+// message at `wordsPerMinute`, rounded to the nearest second. It's 0 when
+// `wordsPerMinute` isn't positive.
+func (m Message) ReadingTime(wordsPerMinute int) time.Duration {
+	if wordsPerMinute <= 0 {
+		return 0
+	}
+
+	readingTime := time.Duration(m.WordCount()) * time.Minute / time.Duration(wordsPerMinute)
+
+	return readingTime.Round(time.Second)
+}
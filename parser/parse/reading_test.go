@@ -0,0 +1,39 @@
+package parse
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadingTime(t *testing.T) {
+	// This is 300 words of new content, plus a quote and a signature which
+	// aren't counted.
+	text := "On Mon, 2 Jan 2006 15:04, Alice wrote:\n" +
+		"> " + strings.Repeat("quoted ", 100) + "\n\n" +
+		strings.Repeat("word ", 150) + "\n\n" +
+		strings.Repeat("more ", 150) + "\n\n" +
+		"--\n" + strings.Repeat("signature ", 50) + "\n"
+
+	message := messageForTest(t, text)
+
+	if got, want := message.WordCount(), 300; got != want {
+		t.Errorf("WordCount() = %d, want %d", got, want)
+	}
+
+	tests := []struct {
+		wordsPerMinute int
+		want           time.Duration
+	}{
+		{DefaultWordsPerMinute, 90 * time.Second},
+		{300, time.Minute},
+		{7, 42*time.Minute + 51*time.Second},
+		{0, 0},
+	}
+
+	for _, test := range tests {
+		if got := message.ReadingTime(test.wordsPerMinute); got != test.want {
+			t.Errorf("ReadingTime(%d) = %v, want %v", test.wordsPerMinute, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package parse
+
+import (
+	"github.com/acearchive/yg-render/block"
+	"github.com/acearchive/yg-render/body"
+	"strings"
+	"unicode/utf8"
+)
+
+const summaryEllipsis = "…"
+
+func firstUnquotedText(tokens []body.Token) string {
+	quoteDepth := 0
+
+	for _, token := range tokens {
+		switch concreteToken := token.(type) {
+		case body.StartQuoteToken:
+			quoteDepth++
+		case body.EndQuoteToken:
+			quoteDepth--
+		case body.BlockToken:
+			// Anything after a message header is the original message.
+			if _, isHeader := concreteToken.Block.(*block.MessageHeaderBlock); isHeader && quoteDepth == 0 {
+				return ""
+			}
+		case body.TextToken:
+			if quoteDepth > 0 {
+				continue
+			}
+
+			if text := strings.Join(strings.Fields(string(concreteToken)), " "); text != "" {
+				return text
+			}
+		}
+	}
+
+	return ""
+}
+
+// truncateAtWord returns an empty string when there's no room for any text,
+// since even the first character can't be kept.
+func truncateAtWord(text string, maxLen int) string {
+	if maxLen <= 0 {
+		return ""
+	}
+
+	if utf8.RuneCountInString(text) <= maxLen {
+		return text
+	}
+
+	var output strings.Builder
+
+	outputLen := 0
+
+	for _, word := range strings.Fields(text) {
+		wordLen := utf8.RuneCountInString(word)
+
+		separatorLen := 0
+		if outputLen > 0 {
+			separatorLen = 1
+		}
+
+		if outputLen+separatorLen+wordLen > maxLen {
+			break
+		}
+
+		if separatorLen > 0 {
+			output.WriteString(" ")
+		}
+
+		output.WriteString(word)
+		outputLen += separatorLen + wordLen
+	}
+
+	// If the first word alone is too long, cut it instead.
+	if outputLen == 0 {
+		return string([]rune(text)[:maxLen]) + summaryEllipsis
+	}
+
+	return output.String() + summaryEllipsis
+}
+
+// Summary returns a plain-text excerpt of the first paragraph of new content
+// in the message, skipping quoted text and forwarded messages. The excerpt is
+// truncated at a word boundary to at most `maxLen` characters, not including
+// the trailing ellipsis. It's empty when `maxLen` isn't positive.
+func (m Message) Summary(maxLen int) string {
+	return truncateAtWord(firstUnquotedText(m.Body.Tokens), maxLen)
+}
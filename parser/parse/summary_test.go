@@ -0,0 +1,82 @@
+package parse
+
+import (
+	"github.com/acearchive/yg-render/body"
+	"strings"
+	"testing"
+)
+
+// messageForTest returns a message whose body is `text` parsed with the
+// default blocks.
+func messageForTest(t *testing.T, text string) Message {
+	t.Helper()
+
+	tokenizer := body.NewDefaultTokenizer()
+
+	tokens, err := tokenizer.Tokenize(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return Message{Body: MessageBody{Tokens: tokens}}
+}
+
+func TestSummary(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		maxLen int
+		want   string
+	}{
+		{
+			name:   "short enough",
+			text:   "Sounds good to me.\n",
+			maxLen: 20,
+			want:   "Sounds good to me.",
+		},
+		{
+			name:   "truncated at a word boundary",
+			text:   "Sounds good to me.\n",
+			maxLen: 13,
+			want:   "Sounds good…",
+		},
+		{
+			name:   "first word too long",
+			text:   "Supercalifragilistic.\n",
+			maxLen: 5,
+			want:   "Super…",
+		},
+		{
+			name:   "only the first paragraph",
+			text:   "Sounds good.\n\nSee you then.\n",
+			maxLen: 100,
+			want:   "Sounds good.",
+		},
+		{
+			name:   "first content quoted",
+			text:   "> Should we meet on Friday?\n\nSounds good to me.\n",
+			maxLen: 100,
+			want:   "Sounds good to me.",
+		},
+		{
+			name:   "everything quoted",
+			text:   "> Should we meet on Friday?\n",
+			maxLen: 100,
+			want:   "",
+		},
+		{
+			name:   "non-positive length",
+			text:   "Sounds good to me.\n",
+			maxLen: 0,
+			want:   "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := messageForTest(t, test.text).Summary(test.maxLen); got != test.want {
+				t.Errorf("Summary(%d) of %q = %q, want %q", test.maxLen, test.text, got, test.want)
+			}
+		})
+	}
+}
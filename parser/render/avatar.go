@@ -0,0 +1,84 @@
+package render
+
+import (
+	"github.com/acearchive/yg-render/block"
+	"hash/fnv"
+	"strings"
+	"unicode"
+)
+
+const avatarInitialsFallback = "?"
+
+// These are dark enough for white text to be readable on them.
+var avatarColors = []string{
+	"#b03a2e", "#a04000", "#7d6608", "#1e8449",
+	"#117a65", "#1f618d", "#2e4053", "#6c3483",
+	"#884ea0", "#943126", "#5d6d7e", "#0e6655",
+}
+
+// AvatarArgs is a placeholder avatar showing the sender's initials on a
+// background color derived from their address.
+type AvatarArgs struct {
+	Initials string
+	Color    string
+}
+
+// avatarInitials returns the first letters of the first and last words in
+// `name`, like "AE" for "Alice Example".
+func avatarInitials(name string) string {
+	var initials []rune
+
+	for _, word := range strings.Fields(name) {
+		for _, char := range word {
+			if unicode.IsLetter(char) || unicode.IsDigit(char) {
+				initials = append(initials, unicode.ToUpper(char))
+				break
+			}
+		}
+	}
+
+	switch len(initials) {
+	case 0:
+		return avatarInitialsFallback
+	case 1:
+		return string(initials)
+	default:
+		return string([]rune{initials[0], initials[len(initials)-1]})
+	}
+}
+
+// avatarColor picks a color for `key` from `avatarColors`, which is the same
+// every time for the same key regardless of case.
+func avatarColor(key string) string {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(strings.ToLower(strings.TrimSpace(key))))
+
+	return avatarColors[hash.Sum32()%uint32(len(avatarColors))]
+}
+
+// quoteAuthorColor picks the color to tint quotes by the author of
+// `attribution` with, from the same colors as avatars. Like avatars, it's
+// derived from the address, or from the name when there's no address.
+func quoteAuthorColor(attribution *block.AttributionBlock) string {
+	colorKey := attribution.Email
+	if colorKey == "" {
+		colorKey = attribution.Name
+	}
+
+	return avatarColor(colorKey)
+}
+
+// newAvatarArgs derives an avatar from the sender's name and address. The
+// color falls back to being derived from the name when the address is
+// unknown.
+func newAvatarArgs(name, email string) *AvatarArgs {
+	colorKey := email
+	if colorKey == "" {
+		colorKey = name
+	}
+
+	return &AvatarArgs{
+		Initials: avatarInitials(name),
+		Color:    avatarColor(colorKey),
+	}
+}
@@ -0,0 +1,101 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAvatarInitials(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Alice Example", "AE"},
+		{"alice b. example", "AE"},
+		{"Alice", "A"},
+		{"\"Alice\" (Moderator)", "AM"},
+		{"Élodie Ünal", "ÉÜ"},
+		{"", "?"},
+		{"--", "?"},
+	}
+
+	for _, test := range tests {
+		if got := avatarInitials(test.name); got != test.want {
+			t.Errorf("avatarInitials(%q) = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestAvatarColor(t *testing.T) {
+	color := newAvatarArgs("Alice Example", "alice@example.com").Color
+
+	for _, email := range []string{"alice@example.com", "Alice@Example.com", " alice@example.com "} {
+		if got := newAvatarArgs("A. Example", email).Color; got != color {
+			t.Errorf("color for %q = %s, want %s", email, got, color)
+		}
+	}
+
+	// Changing how colors are picked would change every avatar in existing
+	// archives.
+	if want := "#7d6608"; color != want {
+		t.Errorf("color for alice@example.com = %s, want %s", color, want)
+	}
+
+	// Without an address, the color comes from the name.
+	if got, want := newAvatarArgs("Alice Example", "").Color, avatarColor("Alice Example"); got != want {
+		t.Errorf("color without an address = %s, want %s", got, want)
+	}
+}
+
+func TestColorQuotesByAuthor(t *testing.T) {
+	date := time.Date(2006, time.January, 2, 15, 4, 5, 0, time.UTC)
+
+	thread := twoMessageThread(t)
+	third := messageForTest(t, "<3@example.com>", "<2@example.com>", "Carol", date.Add(48*time.Hour), "On Tue, 3 Jan 2006 09:30, Bob wrote:\n> Sounds good.\n\nOn Mon, 2 Jan 2006 15:04, Alice <alice@example.com> wrote:\n> Should we meet on Friday?\n\nSee you both then.\n")
+	thread[third.ID] = third
+
+	pages := BuildArgs(thread, OutputConfig{PageSize: 25, ColorQuotesByAuthor: true})
+	if len(pages) != 1 || len(pages[0].Messages) != 3 {
+		t.Fatalf("BuildArgs() = %d pages, want 1 page of 3 messages", len(pages))
+	}
+
+	aliceStyle := fmt.Sprintf(`style="--quote-author-color: %s"`, avatarColor("Alice"))
+	bobStyle := fmt.Sprintf(`style="--quote-author-color: %s"`, avatarColor("Bob"))
+	aliceEmailStyle := fmt.Sprintf(`style="--quote-author-color: %s"`, newAvatarArgs("Alice", "alice@example.com").Color)
+
+	// Bob and Carol both quote Alice, but only Carol's attribution gives her
+	// address, which is what avatars are colored by too.
+	tests := []struct {
+		user string
+		want []string
+	}{
+		{"Alice", nil},
+		{"Bob", []string{aliceStyle}},
+		{"Carol", []string{bobStyle, aliceEmailStyle}},
+	}
+
+	for i, test := range tests {
+		message := pages[0].Messages[i]
+		if message.User != test.user {
+			t.Fatalf("message %d is from %s, want %s", i, message.User, test.user)
+		}
+
+		messageBody := string(message.Body)
+
+		if got := strings.Count(messageBody, "quote-reply-author"); got != len(test.want) {
+			t.Errorf("message from %s has %d tinted quotes, want %d", test.user, got, len(test.want))
+		}
+
+		for _, want := range test.want {
+			if !strings.Contains(messageBody, want) {
+				t.Errorf("message from %s doesn't contain %s:\n%s", test.user, want, messageBody)
+			}
+		}
+	}
+
+	if strings.Contains(string(BuildArgs(thread, OutputConfig{PageSize: 25})[0].Messages[1].Body), "quote-author-color") {
+		t.Error("quotes are tinted without ColorQuotesByAuthor")
+	}
+}
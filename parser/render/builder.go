@@ -2,6 +2,7 @@ package render
 
 import (
 	"fmt"
+	"github.com/acearchive/yg-render/block"
 	"github.com/acearchive/yg-render/body"
 	"github.com/acearchive/yg-render/parse"
 	"golang.org/x/text/language"
@@ -38,6 +39,18 @@ type MessageArgs struct {
 	Flair             string
 	Title             string
 	Body              template.HTML
+	Footnotes         []FootnoteArgs
+
+	// PreviousTitle is the subject of the thread before this message renamed
+	// it, from a "(was: ...)" suffix.
+	PreviousTitle string
+
+	// Lang is the language of the message when it's known and differs from
+	// the language of the page.
+	Lang string
+
+	// Avatar is a placeholder avatar for the sender, when enabled.
+	Avatar *AvatarArgs
 }
 
 type PagePath string
@@ -69,6 +82,15 @@ type TemplateArgs struct {
 	Links         []ExternalLinkConfig
 	Messages      []MessageArgs
 	Pagination    PaginationArgs
+
+	// Footnotes are the citations in the messages on this page.
+	Footnotes []FootnoteArgs
+
+	// HeadingLevel is the level of the page's top heading, and
+	// MessageHeadingLevel is the level of message titles, which is one below
+	// it.
+	HeadingLevel        int
+	MessageHeadingLevel int
 }
 
 func formatTimestamp(input time.Time) string {
@@ -84,16 +106,72 @@ func formatHumanReadableNumber(number int) string {
 	return localizedPrinter.Sprintf("%d", number)
 }
 
-func messageThreadToArgs(thread parse.MessageThread) []MessageArgs {
+func messageBodyHtml(message parse.Message, config OutputConfig, indent int) template.HTML {
+	html := body.RenderWithOptions(message.Body.Tokens, config.BlockOptions)
+
+	// Indenting the markup would change the contents of preformatted text.
+	if config.BlockOptions.PreserveWhitespace {
+		return template.HTML(strings.TrimSpace(html))
+	}
+
+	return template.HTML(strings.TrimSpace(body.IndentMultilineString(html, indent)))
+}
+
+func messageThreadToArgs(thread parse.MessageThread, config OutputConfig) []MessageArgs {
 	argsList := make([]MessageArgs, len(thread))
 
 	messagesByDate, messageIndices := thread.SortedByDate()
 
+	if config.ColorQuotesByAuthor {
+		config.BlockOptions.QuoteAuthorColor = quoteAuthorColor
+	}
+
+	var quotes *repeatedQuotes
+	if config.CollapseRepeatedQuotes {
+		quotes = newRepeatedQuotes(config.PageSize)
+	}
+
+	var cites *citations
+	if config.Citations != nil {
+		cites = newCitations(config.Citations, thread, messageIndices, config)
+	}
+
 	for messageIndex, message := range messagesByDate {
+		displayedMessage := message
+
+		if quotes != nil {
+			displayedMessage.Body.Tokens = quotes.Collapse(message.Body.Tokens)
+			quotes.Add(messageIndex+1, message.Body.Tokens)
+		}
+
+		if config.DiffQuotes && message.Parent != nil {
+			if parent, parentExists := thread[*message.Parent]; parentExists {
+				displayedMessage.Body.Tokens = DiffQuotes(displayedMessage.Body.Tokens, parent)
+			}
+		}
+
+		// Parents are shown again in the replies to them, so only the message
+		// itself gets block IDs to keep them unique on the page.
+		messageConfig := config
+		if config.BlockIDs {
+			messageConfig.BlockOptions.BlockIDPrefix = fmt.Sprintf("message-%d", messageIndex+1)
+		}
+
+		var footnotes []FootnoteArgs
+		if cites != nil {
+			displayedMessage.Body.Tokens, footnotes = cites.Cite(message, displayedMessage.Body.Tokens)
+		}
+
 		messageTitle := ""
 
+		messagePreviousTitle := ""
+
 		if message.Title != nil {
 			messageTitle = *message.Title
+
+			if current, previous, ok := block.SplitWasSubject(messageTitle); ok {
+				messageTitle, messagePreviousTitle = current, previous
+			}
 		}
 
 		var parentArgs *ParentArgs
@@ -106,13 +184,23 @@ func messageThreadToArgs(thread parse.MessageThread) []MessageArgs {
 				parentArgs = &ParentArgs{
 					Index:             parentIndex + 1,
 					User:              parent.User,
-					Body:              template.HTML(strings.TrimSpace(body.IndentMultilineString(parent.Body.Html, messageParentBodyIndent))),
+					Body:              messageBodyHtml(parent, config, messageParentBodyIndent),
 					Timestamp:         formatTimestamp(parent.Date),
 					FormattedDatetime: formatDatetime(parent.Date),
 				}
 			}
 		}
 
+		var messageLang string
+		if lang := message.Lang(); lang != parse.LangUndetermined && lang != config.Lang() {
+			messageLang = lang
+		}
+
+		var avatarArgs *AvatarArgs
+		if config.Avatars {
+			avatarArgs = newAvatarArgs(message.User, message.Email)
+		}
+
 		argsList[messageIndex] = MessageArgs{
 			Index:             messageIndex + 1,
 			Number:            formatHumanReadableNumber(messageIndex + 1),
@@ -123,7 +211,11 @@ func messageThreadToArgs(thread parse.MessageThread) []MessageArgs {
 			User:              message.User,
 			Flair:             message.Flair,
 			Title:             messageTitle,
-			Body:              template.HTML(strings.TrimSpace(body.IndentMultilineString(message.Body.Html, messageBodyIndent))),
+			PreviousTitle:     messagePreviousTitle,
+			Body:              messageBodyHtml(displayedMessage, messageConfig, messageBodyIndent),
+			Footnotes:         footnotes,
+			Lang:              messageLang,
+			Avatar:            avatarArgs,
 		}
 	}
 
@@ -145,6 +237,56 @@ type OutputConfig struct {
 	AddRepoLink       bool
 	Links             []ExternalLinkConfig
 	Locale            string
+	BlockOptions      block.RenderOptions
+
+	// CollapseRepeatedQuotes replaces quotes of a message shown earlier in the
+	// thread with a link back to it.
+	CollapseRepeatedQuotes bool
+
+	// DiffQuotes shows which lines of a quote differ from the message it
+	// replies to, for quotes which were edited.
+	DiffQuotes bool
+
+	// ColorQuotesByAuthor tints each quote introduced by an attribution with
+	// a color derived from the author's name, which is the same for that
+	// author everywhere in the thread.
+	ColorQuotesByAuthor bool
+
+	// BlockIDs gives each paragraph, quote, and block in a message an `id`
+	// like "message-1-block-3" for linking to it.
+	BlockIDs bool
+
+	// Avatars shows a placeholder avatar with the sender's initials next to
+	// each message instead of a generic icon.
+	Avatars bool
+
+	// Citations, when set, replaces each attribution with a numbered
+	// citation linking to the message it resolves to, and collects the
+	// attributions into footnotes at the end of the page.
+	Citations CitationResolver
+
+	// BaseHeadingLevel is the level of the top heading on each page, which is
+	// useful when embedding pages in a larger document. It defaults to 1.
+	BaseHeadingLevel int
+
+	// Template is the page template, which defaults to `Template` when nil.
+	Template *template.Template
+}
+
+func (c OutputConfig) PageTemplate() *template.Template {
+	if c.Template == nil {
+		return Template
+	}
+
+	return c.Template
+}
+
+func (c OutputConfig) HeadingLevel() int {
+	if c.BaseHeadingLevel == 0 {
+		return minHeadingLevel
+	}
+
+	return c.BaseHeadingLevel
 }
 
 func (c OutputConfig) Lang() string {
@@ -199,7 +341,7 @@ func calculateTotalPages(messages, pageSize int) int {
 }
 
 func BuildArgs(thread parse.MessageThread, config OutputConfig) []TemplateArgs {
-	messages := messageThreadToArgs(thread)
+	messages := messageThreadToArgs(thread, config)
 
 	totalPages := calculateTotalPages(len(messages), config.PageSize)
 
@@ -245,7 +387,11 @@ func BuildArgs(thread parse.MessageThread, config OutputConfig) []TemplateArgs {
 
 		messagesInPage := messages[messageStartIndex:messageEndIndex]
 
+		var footnotes []FootnoteArgs
+
 		for _, message := range messagesInPage {
+			footnotes = append(footnotes, message.Footnotes...)
+
 			if message.Parent != nil {
 				parentPageNumber := (message.Parent.Index / config.PageSize) + 1
 				message.Parent.PagePath = pagePath(parentPageNumber)
@@ -264,15 +410,18 @@ func BuildArgs(thread parse.MessageThread, config OutputConfig) []TemplateArgs {
 		}
 
 		args = append(args, TemplateArgs{
-			Title:         config.Title,
-			Description:   config.Description(),
-			BaseUrl:       config.BaseUrl,
-			Locale:        config.Locale,
-			Lang:          config.Lang(),
-			IncludeSearch: config.IncludeSearch,
-			Links:         linkArgs,
-			Messages:      messagesInPage,
-			Pagination:    paginationArgs,
+			Title:               config.Title,
+			Description:         config.Description(),
+			BaseUrl:             config.BaseUrl,
+			Locale:              config.Locale,
+			Lang:                config.Lang(),
+			IncludeSearch:       config.IncludeSearch,
+			Links:               linkArgs,
+			Messages:            messagesInPage,
+			Pagination:          paginationArgs,
+			Footnotes:           footnotes,
+			HeadingLevel:        config.HeadingLevel(),
+			MessageHeadingLevel: config.HeadingLevel() + 1,
 		})
 	}
 
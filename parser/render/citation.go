@@ -0,0 +1,136 @@
+package render
+
+import (
+	"fmt"
+	"github.com/acearchive/yg-render/block"
+	"github.com/acearchive/yg-render/body"
+	"github.com/acearchive/yg-render/parse"
+	"html"
+	"html/template"
+	"io"
+	"strings"
+)
+
+// CitationResolver returns the message in `thread` which `attribution` in
+// `message` cites, if it can tell.
+type CitationResolver func(attribution *block.AttributionBlock, message parse.Message, thread parse.MessageThread) (cited parse.MessageID, ok bool)
+
+// CiteAncestorByName resolves an attribution to the closest ancestor of the
+// message that was sent by the attributed name.
+func CiteAncestorByName(attribution *block.AttributionBlock, message parse.Message, thread parse.MessageThread) (cited parse.MessageID, ok bool) {
+	parentID := message.Parent
+
+	// Guard against cycles in malformed threads.
+	for step := 0; parentID != nil && step < len(thread); step++ {
+		parent, exists := thread[*parentID]
+		if !exists {
+			break
+		}
+
+		if strings.EqualFold(parent.User, attribution.Name) {
+			return parent.ID, true
+		}
+
+		parentID = parent.Parent
+	}
+
+	return "", false
+}
+
+type FootnoteArgs struct {
+	Number      int
+	Attribution template.HTML
+}
+
+// citationToken replaces an attribution with a numbered reference to its
+// footnote. It links to the cited message when it's known.
+type citationToken struct {
+	Number int
+	Href   string
+}
+
+func (citationToken) TagType() body.TagType {
+	return body.TagTypeSelfClose
+}
+
+func (t citationToken) WriteHtml(w io.Writer, _ block.RenderOptions) error {
+	href := t.Href
+	if href == "" {
+		href = fmt.Sprintf("#footnote-%d", t.Number)
+	}
+
+	_, err := fmt.Fprintf(
+		w,
+		`<div class="citation"><sup><a href="%s" aria-describedby="footnote-%d">[%d]</a></sup></div>`,
+		html.EscapeString(href),
+		t.Number,
+		t.Number,
+	)
+
+	return err
+}
+
+func (t citationToken) ToHtml() string {
+	return body.RenderWithOptions([]body.Token{t}, block.RenderOptions{})
+}
+
+// citations numbers the attributions in a thread and collects their text
+// into footnotes.
+type citations struct {
+	resolve        CitationResolver
+	thread         parse.MessageThread
+	messageIndices map[parse.MessageID]int
+	pageSize       int
+	renderOptions  block.RenderOptions
+	count          int
+}
+
+func newCitations(resolve CitationResolver, thread parse.MessageThread, messageIndices map[parse.MessageID]int, config OutputConfig) *citations {
+	return &citations{
+		resolve:        resolve,
+		thread:         thread,
+		messageIndices: messageIndices,
+		pageSize:       config.PageSize,
+		renderOptions:  config.BlockOptions,
+	}
+}
+
+// Cite replaces each attribution in `tokens`, which are the displayed tokens
+// of `message`, with a citation. It returns the footnotes for them, which are
+// numbered continuing from the previous call.
+func (c *citations) Cite(message parse.Message, tokens []body.Token) ([]body.Token, []FootnoteArgs) {
+	output := make([]body.Token, len(tokens))
+	var footnotes []FootnoteArgs
+
+	for i, token := range tokens {
+		blockToken, isBlock := token.(body.BlockToken)
+		if !isBlock {
+			output[i] = token
+			continue
+		}
+
+		attribution, isAttribution := blockToken.Block.(*block.AttributionBlock)
+		if !isAttribution {
+			output[i] = token
+			continue
+		}
+
+		c.count++
+
+		citation := citationToken{Number: c.count}
+
+		if cited, ok := c.resolve(attribution, message, c.thread); ok {
+			if index, exists := c.messageIndices[cited]; exists {
+				citation.Href = fmt.Sprintf("%s#message-%d", pagePath(index/c.pageSize+1), index+1)
+			}
+		}
+
+		output[i] = citation
+		footnotes = append(footnotes, FootnoteArgs{
+			Number:      c.count,
+			Attribution: template.HTML(strings.TrimSpace(body.RenderWithOptions([]body.Token{token}, c.renderOptions))),
+		})
+	}
+
+	return output, footnotes
+}
@@ -0,0 +1,27 @@
+package render
+
+import (
+	"github.com/acearchive/yg-render/parse"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderCitations(t *testing.T) {
+	date := time.Date(2006, time.January, 2, 15, 4, 5, 0, time.UTC)
+
+	first := messageForTest(t, "<1@example.com>", "", "Alice", date, "Should we meet on Friday?\n")
+	second := messageForTest(t, "<2@example.com>", "<1@example.com>", "Bob", date.Add(time.Hour), "On Mon, 2 Jan 2006 15:04, Alice wrote:\n> Should we meet on Friday?\n\nFriday works.\n")
+	third := messageForTest(t, "<3@example.com>", "<2@example.com>", "Carol", date.Add(2*time.Hour), "On Mon, 2 Jan 2006 16:04, Bob wrote:\n> Friday works.\n\nSo does Saturday.\n")
+
+	thread := parse.MessageThread{first.ID: first, second.ID: second, third.ID: third}
+
+	pages := BuildArgs(thread, OutputConfig{PageSize: 25, Title: "Example Group", BaseUrl: "/", Citations: CiteAncestorByName})
+
+	var output strings.Builder
+	if err := Template.Execute(&output, pages[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	checkGolden(t, "citations.html", output.String())
+}
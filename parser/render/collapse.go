@@ -0,0 +1,116 @@
+package render
+
+import (
+	"fmt"
+	"github.com/acearchive/yg-render/block"
+	"github.com/acearchive/yg-render/body"
+	"github.com/acearchive/yg-render/parse"
+	"html"
+	"io"
+)
+
+// repeatedQuoteToken replaces a quote whose content is the same as a message
+// shown earlier in the thread with a link back to that message.
+type repeatedQuoteToken struct {
+	MessageIndex int
+	PagePath     PagePath
+}
+
+func (repeatedQuoteToken) TagType() body.TagType {
+	return body.TagTypeSelfClose
+}
+
+func (t repeatedQuoteToken) WriteHtml(w io.Writer, _ block.RenderOptions) error {
+	href := fmt.Sprintf("%s#message-%d", t.PagePath, t.MessageIndex)
+
+	_, err := fmt.Fprintf(
+		w,
+		`<blockquote class="repeated-quote"><a href="%s">Quoted message %s</a></blockquote>`,
+		html.EscapeString(href),
+		formatHumanReadableNumber(t.MessageIndex),
+	)
+
+	return err
+}
+
+func (t repeatedQuoteToken) ToHtml() string {
+	return body.RenderWithOptions([]body.Token{t}, block.RenderOptions{})
+}
+
+// matchingEndQuote returns the index of the `EndQuoteToken` which closes the
+// quote opened at `startIndex`, or -1 if it's never closed.
+func matchingEndQuote(tokens []body.Token, startIndex int) int {
+	depth := 0
+
+	for i := startIndex; i < len(tokens); i++ {
+		switch tokens[i].(type) {
+		case body.StartQuoteToken:
+			depth++
+		case body.EndQuoteToken:
+			depth--
+
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+// repeatedQuotes tracks the normalized text of the messages shown so far in a
+// thread, so that quotes of them in later messages can be collapsed.
+type repeatedQuotes struct {
+	pageSize      int
+	messageByText map[string]int
+}
+
+func newRepeatedQuotes(pageSize int) *repeatedQuotes {
+	return &repeatedQuotes{
+		pageSize:      pageSize,
+		messageByText: make(map[string]int),
+	}
+}
+
+// Add records the message at `index`, which is 1-based like `MessageArgs`.
+func (r *repeatedQuotes) Add(index int, tokens []body.Token) {
+	text := parse.NormalizeBodyText(tokens)
+	if text == "" {
+		return
+	}
+
+	if _, exists := r.messageByText[text]; !exists {
+		r.messageByText[text] = index
+	}
+}
+
+// Collapse replaces each top-level quote in `tokens` whose text is the same as
+// a message added so far with a link back to that message.
+func (r *repeatedQuotes) Collapse(tokens []body.Token) []body.Token {
+	output := make([]body.Token, 0, len(tokens))
+
+	for i := 0; i < len(tokens); i++ {
+		if _, isQuote := tokens[i].(body.StartQuoteToken); !isQuote {
+			output = append(output, tokens[i])
+			continue
+		}
+
+		endIndex := matchingEndQuote(tokens, i)
+		if endIndex < 0 {
+			return append(output, tokens[i:]...)
+		}
+
+		if index, isRepeated := r.messageByText[parse.NormalizeBodyText(tokens[i+1:endIndex])]; isRepeated {
+			output = append(output, repeatedQuoteToken{
+				MessageIndex: index,
+				PagePath:     pagePath((index-1)/r.pageSize + 1),
+			})
+		} else {
+			output = append(output, tokens[i:endIndex+1]...)
+		}
+
+		i = endIndex
+	}
+
+	return output
+}
@@ -0,0 +1,43 @@
+package render
+
+import (
+	"github.com/acearchive/yg-render/parse"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCollapseRepeatedQuotes(t *testing.T) {
+	date := time.Date(2006, time.January, 2, 15, 4, 5, 0, time.UTC)
+
+	const history = "On Mon, 2 Jan 2006 15:04, Alice wrote:\n> Should we meet on Friday?\n> Or Saturday?\n\n"
+
+	first := messageForTest(t, "<1@example.com>", "", "Alice", date, "Should we meet on Friday?\nOr Saturday?\n")
+	second := messageForTest(t, "<2@example.com>", "<1@example.com>", "Bob", date.Add(time.Hour), history+"Friday works.\n")
+	third := messageForTest(t, "<3@example.com>", "<1@example.com>", "Carol", date.Add(2*time.Hour), history+"Saturday works.\n")
+	fourth := messageForTest(t, "<4@example.com>", "<1@example.com>", "Dave", date.Add(3*time.Hour), "> Should we meet on Thursday?\n\nNo.\n")
+
+	thread := parse.MessageThread{first.ID: first, second.ID: second, third.ID: third, fourth.ID: fourth}
+
+	tests := []struct {
+		name          string
+		collapse      bool
+		wantCollapsed []bool
+	}{
+		{"collapsed", true, []bool{false, true, true, false}},
+		{"not collapsed", false, []bool{false, false, false, false}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			messages := BuildArgs(thread, OutputConfig{PageSize: 25, CollapseRepeatedQuotes: test.collapse})[0].Messages
+
+			for i, message := range messages {
+				isCollapsed := strings.Contains(string(message.Body), `<blockquote class="repeated-quote"><a href="/#message-1">Quoted message 1</a></blockquote>`)
+				if isCollapsed != test.wantCollapsed[i] {
+					t.Errorf("message %d has a collapsed quote = %v, want %v:\n%s", i+1, isCollapsed, test.wantCollapsed[i], message.Body)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package render
+
+import (
+	"fmt"
+	"github.com/acearchive/yg-render/block"
+	"github.com/acearchive/yg-render/body"
+	"github.com/acearchive/yg-render/parse"
+	"html"
+	"io"
+	"strings"
+)
+
+type diffLineKind string
+
+const (
+	diffLineSame    diffLineKind = "same"
+	diffLineAdded   diffLineKind = "added"
+	diffLineRemoved diffLineKind = "removed"
+)
+
+type diffLine struct {
+	Kind diffLineKind
+	Text string
+}
+
+// diffLines returns a line-based diff from `original` to `changed` using the
+// longest common subsequence of lines. Where lines were replaced, the removed
+// lines come before the added ones.
+func diffLines(original, changed []string) []diffLine {
+	// common[i][j] is the length of the longest common subsequence of
+	// original[i:] and changed[j:].
+	common := make([][]int, len(original)+1)
+	for i := range common {
+		common[i] = make([]int, len(changed)+1)
+	}
+
+	for i := len(original) - 1; i >= 0; i-- {
+		for j := len(changed) - 1; j >= 0; j-- {
+			switch {
+			case original[i] == changed[j]:
+				common[i][j] = common[i+1][j+1] + 1
+			case common[i+1][j] >= common[i][j+1]:
+				common[i][j] = common[i+1][j]
+			default:
+				common[i][j] = common[i][j+1]
+			}
+		}
+	}
+
+	var lines []diffLine
+
+	i, j := 0, 0
+
+	for i < len(original) || j < len(changed) {
+		switch {
+		case i < len(original) && j < len(changed) && original[i] == changed[j]:
+			lines = append(lines, diffLine{Kind: diffLineSame, Text: original[i]})
+			i++
+			j++
+		case i < len(original) && (j == len(changed) || common[i+1][j] >= common[i][j+1]):
+			lines = append(lines, diffLine{Kind: diffLineRemoved, Text: original[i]})
+			i++
+		default:
+			lines = append(lines, diffLine{Kind: diffLineAdded, Text: changed[j]})
+			j++
+		}
+	}
+
+	return lines
+}
+
+func countLinesOfKind(lines []diffLine, kind diffLineKind) int {
+	count := 0
+
+	for count < len(lines) && lines[count].Kind == kind {
+		count++
+	}
+
+	return count
+}
+
+func reversedLines(lines []diffLine) []diffLine {
+	reversed := make([]diffLine, len(lines))
+
+	for i, line := range lines {
+		reversed[len(lines)-1-i] = line
+	}
+
+	return reversed
+}
+
+// trimLeadingUnquotedLines drops the lines of the original message before the
+// start of the quote. Removed lines right before added ones are assumed to
+// have been replaced by them, so one is kept for each added line.
+func trimLeadingUnquotedLines(lines []diffLine) []diffLine {
+	removedCount := countLinesOfKind(lines, diffLineRemoved)
+	addedCount := countLinesOfKind(lines[removedCount:], diffLineAdded)
+
+	if addedCount < removedCount {
+		return lines[removedCount-addedCount:]
+	}
+
+	return lines
+}
+
+// trimUnquotedLines drops the lines of the original message before the start
+// of the quote and after the end of it, since quoting only part of a message
+// isn't a change to it.
+func trimUnquotedLines(lines []diffLine) []diffLine {
+	lines = trimLeadingUnquotedLines(lines)
+
+	return reversedLines(trimLeadingUnquotedLines(reversedLines(lines)))
+}
+
+// normalizedLines returns the paragraphs of new content in `tokens` with
+// whitespace collapsed, so that quotes which were rewrapped by the client
+// still compare equal to the original.
+func normalizedLines(tokens []body.Token) []string {
+	text := parse.NormalizeBodyText(tokens)
+	if text == "" {
+		return nil
+	}
+
+	return strings.Split(text, "\n")
+}
+
+// quoteDiffToken follows a quote whose text differs from the message it
+// replies to, showing the lines which were changed.
+type quoteDiffToken struct {
+	Lines []diffLine
+}
+
+func (quoteDiffToken) TagType() body.TagType {
+	return body.TagTypeSelfClose
+}
+
+func (t quoteDiffToken) WriteHtml(w io.Writer, _ block.RenderOptions) error {
+	if _, err := io.WriteString(w, "<details class=\"quote-diff\">\n  <summary>This quote differs from the message it replies to</summary>\n"); err != nil {
+		return err
+	}
+
+	for _, line := range t.Lines {
+		text := html.EscapeString(line.Text)
+
+		switch line.Kind {
+		case diffLineAdded:
+			text = "<ins>" + text + "</ins>"
+		case diffLineRemoved:
+			text = "<del>" + text + "</del>"
+		}
+
+		if _, err := fmt.Fprintf(w, "  <p class=\"quote-diff-line quote-diff-%s\">%s</p>\n", line.Kind, text); err != nil {
+			return err
+		}
+	}
+
+	_, err := io.WriteString(w, "</details>")
+
+	return err
+}
+
+func (t quoteDiffToken) ToHtml() string {
+	return body.RenderWithOptions([]body.Token{t}, block.RenderOptions{})
+}
+
+// DiffQuotes adds a diff after each top-level quote in `tokens` whose text
+// differs from `original`, which is the message being replied to. Quotes
+// which share no lines with it are assumed to quote some other message and
+// are left alone.
+func DiffQuotes(tokens []body.Token, original parse.Message) []body.Token {
+	originalLines := normalizedLines(original.Body.Tokens)
+	if len(originalLines) == 0 {
+		return tokens
+	}
+
+	output := make([]body.Token, 0, len(tokens))
+
+	for i := 0; i < len(tokens); i++ {
+		if _, isQuote := tokens[i].(body.StartQuoteToken); !isQuote {
+			output = append(output, tokens[i])
+			continue
+		}
+
+		endIndex := matchingEndQuote(tokens, i)
+		if endIndex < 0 {
+			return append(output, tokens[i:]...)
+		}
+
+		output = append(output, tokens[i:endIndex+1]...)
+
+		lines := trimUnquotedLines(diffLines(originalLines, normalizedLines(tokens[i+1:endIndex])))
+
+		var hasSame, hasChanged bool
+
+		for _, line := range lines {
+			if line.Kind == diffLineSame {
+				hasSame = true
+			} else {
+				hasChanged = true
+			}
+		}
+
+		if hasSame && hasChanged {
+			output = append(output, quoteDiffToken{Lines: lines})
+		}
+
+		i = endIndex
+	}
+
+	return output
+}
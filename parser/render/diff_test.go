@@ -0,0 +1,80 @@
+package render
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDiffLines(t *testing.T) {
+	got := diffLines([]string{"a", "b", "c"}, []string{"a", "x", "c", "d"})
+
+	want := []diffLine{
+		{Kind: diffLineSame, Text: "a"},
+		{Kind: diffLineRemoved, Text: "b"},
+		{Kind: diffLineAdded, Text: "x"},
+		{Kind: diffLineSame, Text: "c"},
+		{Kind: diffLineAdded, Text: "d"},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("diffLines() = %v, want %v", got, want)
+	}
+}
+
+func TestDiffQuotes(t *testing.T) {
+	date := time.Date(2006, time.January, 2, 15, 4, 5, 0, time.UTC)
+
+	original := messageForTest(t, "<1@example.com>", "", "Alice", date, "Hi all,\n\nThe meeting is on Friday.\n\nBring the notes.\n")
+
+	tests := []struct {
+		name      string
+		text      string
+		wantLines []diffLine
+	}{
+		{
+			name: "altered",
+			text: "> The meeting is on Saturday.\n>\n> Bring the notes.\n\nSee you then.\n",
+			wantLines: []diffLine{
+				{Kind: diffLineRemoved, Text: "The meeting is on Friday."},
+				{Kind: diffLineAdded, Text: "The meeting is on Saturday."},
+				{Kind: diffLineSame, Text: "Bring the notes."},
+			},
+		},
+		{
+			name: "quoted in part and rewrapped",
+			text: "> The meeting is\n> on Friday.\n\nSee you then.\n",
+		},
+		{
+			name: "another message",
+			text: "> Where is it?\n\nSee you then.\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reply := messageForTest(t, "<2@example.com>", "<1@example.com>", "Bob", date.Add(time.Hour), test.text)
+
+			var lines []diffLine
+			for _, token := range DiffQuotes(reply.Body.Tokens, original) {
+				if diff, ok := token.(quoteDiffToken); ok {
+					lines = diff.Lines
+				}
+			}
+
+			if !reflect.DeepEqual(lines, test.wantLines) {
+				t.Errorf("diff of %q = %v, want %v", test.text, lines, test.wantLines)
+			}
+		})
+	}
+}
+
+func TestRenderQuoteDiff(t *testing.T) {
+	diff := quoteDiffToken{Lines: []diffLine{
+		{Kind: diffLineRemoved, Text: "The meeting is on Friday."},
+		{Kind: diffLineAdded, Text: "The meeting is on <Saturday>."},
+		{Kind: diffLineSame, Text: "Bring the notes."},
+	}}
+
+	checkGolden(t, "quote_diff.html", diff.ToHtml())
+}
@@ -36,7 +36,7 @@ func Execute(path string, config OutputConfig, thread parse.MessageThread) error
 			return err
 		}
 
-		if err := Template.Execute(file, args); err != nil {
+		if err := config.PageTemplate().Execute(file, args); err != nil {
 			return err
 		}
 
@@ -4,25 +4,53 @@ import (
 	_ "embed"
 	"fmt"
 	"github.com/Masterminds/sprig/v3"
+	"html"
 	"html/template"
 )
 
 const (
 	messageBodyIndent       = 18
 	messageParentBodyIndent = 20
+	minHeadingLevel         = 1
+	maxHeadingLevel         = 6
 )
 
+// heading renders a heading element at `level`, which is clamped to the range
+// of HTML heading levels.
+func heading(level int, class, text string) template.HTML {
+	if level < minHeadingLevel {
+		level = minHeadingLevel
+	} else if level > maxHeadingLevel {
+		level = maxHeadingLevel
+	}
+
+	return template.HTML(fmt.Sprintf(`<h%[1]d class="%[2]s">%[3]s</h%[1]d>`, level, html.EscapeString(class), html.EscapeString(text)))
+}
+
 //go:embed template.html.tmpl
 var templateString string
 
 var Template *template.Template
 
-func init() {
-	templateFunctions := sprig.FuncMap()
+func templateFunctions() template.FuncMap {
+	functions := sprig.FuncMap()
 
-	templateFunctions["comment"] = func(text string) template.HTML {
+	functions["comment"] = func(text string) template.HTML {
 		return template.HTML(fmt.Sprintf("<!-- %s -->", text))
 	}
 
-	Template = template.Must(template.New("yg-render").Funcs(templateFunctions).Parse(templateString))
+	functions["heading"] = heading
+
+	return functions
+}
+
+// ParseTemplate parses a custom page template, which is executed with a
+// `TemplateArgs` for each page. It has access to the same functions as the
+// default template.
+func ParseTemplate(text string) (*template.Template, error) {
+	return template.New("yg-render").Funcs(templateFunctions()).Parse(text)
+}
+
+func init() {
+	Template = template.Must(ParseTemplate(templateString))
 }
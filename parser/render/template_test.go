@@ -0,0 +1,162 @@
+package render
+
+import (
+	"flag"
+	"github.com/acearchive/yg-render/body"
+	"github.com/acearchive/yg-render/parse"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var updateGolden = flag.Bool("update", false, "Write the rendered pages of golden tests to their files in testdata")
+
+// checkGolden compares `rendered` to the file `name` in testdata, or writes it
+// there with -update.
+func checkGolden(t *testing.T, name string, rendered string) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name)
+
+	if *updateGolden {
+		if err := ioutil.WriteFile(path, []byte(rendered), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		return
+	}
+
+	want, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rendered != string(want) {
+		t.Errorf("rendered page doesn't match %s:\n%s", path, rendered)
+	}
+}
+
+func messageForTest(t *testing.T, id, parent parse.MessageID, user string, date time.Time, text string) parse.Message {
+	t.Helper()
+
+	tokenizer := body.NewDefaultTokenizer()
+
+	tokens, err := tokenizer.Tokenize(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message := parse.Message{
+		ID:   id,
+		User: user,
+		Date: date,
+		Body: parse.MessageBody{Tokens: tokens, Html: body.Render(tokens)},
+	}
+
+	if parent != "" {
+		message.Parent = &parent
+	}
+
+	return message
+}
+
+func twoMessageThread(t *testing.T) parse.MessageThread {
+	title := "Meeting on Friday"
+
+	first := messageForTest(t, "<1@example.com>", "", "Alice", time.Date(2006, time.January, 2, 15, 4, 5, 0, time.UTC), "Should we meet on Friday?\n")
+	first.Title = &title
+
+	second := messageForTest(t, "<2@example.com>", "<1@example.com>", "Bob", time.Date(2006, time.January, 3, 9, 30, 0, 0, time.UTC), "On Mon, 2 Jan 2006 15:04, Alice wrote:\n> Should we meet on Friday?\n\nSounds good.\n")
+
+	return parse.MessageThread{first.ID: first, second.ID: second}
+}
+
+func TestRenderTwoMessageThread(t *testing.T) {
+	pages := BuildArgs(twoMessageThread(t), OutputConfig{PageSize: 25, Title: "Example Group", BaseUrl: "/"})
+	if len(pages) != 1 {
+		t.Fatalf("BuildArgs() = %d pages, want 1", len(pages))
+	}
+
+	var output strings.Builder
+	if err := Template.Execute(&output, pages[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	checkGolden(t, "two_message_thread.html", output.String())
+}
+
+func TestRenderCustomTemplate(t *testing.T) {
+	pageTemplate, err := ParseTemplate(`{{ range .Messages }}<article id="message-{{ .Index }}"><h2>{{ .User }}</h2>{{ .Body }}</article>
+{{ end }}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := OutputConfig{PageSize: 25, Template: pageTemplate}
+
+	var output strings.Builder
+	if err := config.PageTemplate().Execute(&output, BuildArgs(twoMessageThread(t), config)[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	checkGolden(t, "custom_template.html", output.String())
+}
+
+func TestRenderHeadingLevel(t *testing.T) {
+	tests := []struct {
+		name             string
+		baseHeadingLevel int
+		wantTitle        string
+		wantMessageTitle string
+	}{
+		{"default", 0, `<h1 class="thread-title">Example Group</h1>`, `<h2 class="card-title message-title">Meeting on Friday</h2>`},
+		{"shifted", 3, `<h3 class="thread-title">Example Group</h3>`, `<h4 class="card-title message-title">Meeting on Friday</h4>`},
+		{"clamped", 6, `<h6 class="thread-title">Example Group</h6>`, `<h6 class="card-title message-title">Meeting on Friday</h6>`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := OutputConfig{PageSize: 25, Title: "Example Group", BaseHeadingLevel: test.baseHeadingLevel}
+
+			var output strings.Builder
+			if err := Template.Execute(&output, BuildArgs(twoMessageThread(t), config)[0]); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, want := range []string{test.wantTitle, test.wantMessageTitle} {
+				if !strings.Contains(output.String(), want) {
+					t.Errorf("rendered page doesn't contain %s", want)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderSubjectChange(t *testing.T) {
+	thread := twoMessageThread(t)
+
+	renamed := "Lunch instead (was: Meeting on Friday)"
+	second := thread["<2@example.com>"]
+	second.Title = &renamed
+	thread[second.ID] = second
+
+	var output strings.Builder
+	if err := Template.Execute(&output, BuildArgs(thread, OutputConfig{PageSize: 25})[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		`<h2 class="card-title message-title">Lunch instead</h2>`,
+		`<p class="subject-changed card-subtitle mb-2 text-muted">Subject changed from <q>Meeting on Friday</q></p>`,
+	} {
+		if !strings.Contains(output.String(), want) {
+			t.Errorf("rendered page doesn't contain %s", want)
+		}
+	}
+
+	if strings.Count(output.String(), "subject-changed") != 1 {
+		t.Errorf("rendered page has %d subject changes, want 1", strings.Count(output.String(), "subject-changed"))
+	}
+}